@@ -0,0 +1,104 @@
+package displaywidth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/clipperhouse/uax29/v2/graphemes"
+)
+
+// A long, log-like ASCII string
+var longASCII = strings.Repeat("2025-01-02T15:04:05Z INFO server: request handled in 12ms path=/api/v1/items status=200\n", 64)
+
+// A long ASCII sentence with no control characters
+var cleanASCII = strings.Repeat("The quick brown fox jumps over the lazy dog. ", 32)
+
+func BenchmarkASCII(b *testing.B) {
+	b.Run("String", func(b *testing.B) {
+		b.SetBytes(int64(len(longASCII)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = String(longASCII)
+		}
+	})
+
+	bs := []byte(longASCII)
+	b.Run("Bytes", func(b *testing.B) {
+		b.SetBytes(int64(len(bs)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = Bytes(bs)
+		}
+	})
+
+	b.Run("String/clean", func(b *testing.B) {
+		b.SetBytes(int64(len(cleanASCII)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = String(cleanASCII)
+		}
+	})
+
+	b.Run("String/clean/TabWidth", func(b *testing.B) {
+		options := Options{TabWidth: 8}
+		b.SetBytes(int64(len(cleanASCII)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = options.String(cleanASCII)
+		}
+	})
+
+	cleanBytes := []byte(cleanASCII)
+	b.Run("Bytes/clean", func(b *testing.B) {
+		b.SetBytes(int64(len(cleanBytes)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = Bytes(cleanBytes)
+		}
+	})
+
+	b.Run("printableASCIILength", func(b *testing.B) {
+		s := strings.ReplaceAll(longASCII, "\n", " ")
+		b.SetBytes(int64(len(s)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = printableASCIILength(s)
+		}
+	})
+
+	// Baseline: the per-grapheme path, without the ASCII fast path
+	b.Run("graphemes", func(b *testing.B) {
+		b.SetBytes(int64(len(longASCII)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			width := 0
+			g := graphemes.FromString(longASCII)
+			for g.Next() {
+				width += graphemeWidth(g.Value(), DefaultOptions)
+			}
+			_ = width
+		}
+	})
+}
+
+// A medium-length CJK string, which takes the grapheme path
+var mediumCJK = strings.Repeat("日本語のテキストと中文文本，한국어 텍스트。", 8)
+
+func BenchmarkCJK(b *testing.B) {
+	b.Run("String", func(b *testing.B) {
+		b.SetBytes(int64(len(mediumCJK)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = String(mediumCJK)
+		}
+	})
+
+	bs := []byte(mediumCJK)
+	b.Run("Bytes", func(b *testing.B) {
+		b.SetBytes(int64(len(bs)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = Bytes(bs)
+		}
+	})
+}
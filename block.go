@@ -0,0 +1,80 @@
+package displaywidth
+
+import "strings"
+
+// Align is the horizontal alignment of text within a width, for
+// [Options.AlignString] and [Options.NormalizeBlock].
+type Align uint8
+
+const (
+	// AlignLeft pads on the right.
+	AlignLeft Align = iota
+	// AlignRight pads on the left.
+	AlignRight
+	// AlignCenter pads on both sides. When the padding is odd, the extra
+	// space is on the right.
+	AlignCenter
+)
+
+// AlignString pads s with spaces to the given width, with the given
+// alignment. If s is already at least width wide, it is returned unchanged.
+//
+// With [Options.TabWidth], spaces before s move its tab stops, as in
+// [Options.PadLeft], so if s contains tabs, a right- or center-aligned
+// result may be wider or narrower than width.
+func (options Options) AlignString(s string, width int, align Align) string {
+	n := width - options.String(s)
+	if n <= 0 {
+		return s
+	}
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", n) + s
+	case AlignCenter:
+		left := n / 2
+		return strings.Repeat(" ", left) + s + strings.Repeat(" ", n-left)
+	default:
+		return s + strings.Repeat(" ", n)
+	}
+}
+
+// AlignString pads s with spaces to the given width, with the given
+// alignment. See [Options.AlignString].
+func AlignString(s string, width int, align Align) string {
+	return DefaultOptionsValue().AlignString(s, width, align)
+}
+
+// NormalizeBlock splits s into lines, and pads each line to the width of the
+// widest, with the given alignment, so that all lines have equal width. It is
+// the core of drawing a box around text. See [Options.NormalizeBlock].
+func NormalizeBlock(s string, align Align) []string {
+	return DefaultOptionsValue().NormalizeBlock(s, align)
+}
+
+// NormalizeBlock splits s into lines, and pads each line to the width of the
+// widest, with the given options and alignment, so that all lines have equal
+// width. Lines are measured by grapheme cluster, so that lines with wide
+// characters, such as CJK, are padded correctly.
+//
+// Lines are separated by "\n" or "\r\n"; the separators are not included in
+// the result. A trailing newline ends the last line, and does not add an
+// empty line. An empty string has no lines, and returns nil.
+func (options Options) NormalizeBlock(s string, align Align) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+
+	max := 0
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		lines[i] = line
+		if w := options.String(line); w > max {
+			max = w
+		}
+	}
+	for i, line := range lines {
+		lines[i] = options.AlignString(line, max, align)
+	}
+	return lines
+}
@@ -0,0 +1,79 @@
+package displaywidth
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAlignString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		width    int
+		align    Align
+		expected string
+	}{
+		{"left", "ab", 5, AlignLeft, "ab   "},
+		{"right", "ab", 5, AlignRight, "   ab"},
+		{"center", "ab", 6, AlignCenter, "  ab  "},
+		{"center odd", "ab", 5, AlignCenter, " ab  "},
+		{"CJK left", "中文", 6, AlignLeft, "中文  "},
+		{"CJK right", "中文", 6, AlignRight, "  中文"},
+		{"CJK center", "中", 5, AlignCenter, " 中  "},
+		{"emoji center", "😀", 4, AlignCenter, " 😀 "},
+		{"exact", "中文", 4, AlignCenter, "中文"},
+		{"wider", "hello", 3, AlignRight, "hello"},
+		{"empty", "", 3, AlignCenter, "   "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AlignString(tt.input, tt.width, tt.align); got != tt.expected {
+				t.Errorf("AlignString(%q, %d, %d) = %q, want %q", tt.input, tt.width, tt.align, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		align    Align
+		expected []string
+	}{
+		{"empty", "", AlignLeft, nil},
+		{"single line", "hello", AlignLeft, []string{"hello"}},
+		{"mixed width left", "a\n中文\n😀 b", AlignLeft, []string{"a   ", "中文", "😀 b"}},
+		{"mixed width right", "a\n中文\n😀 b", AlignRight, []string{"   a", "中文", "😀 b"}},
+		{"mixed width center", "a\n中文字\nbb", AlignCenter, []string{"  a   ", "中文字", "  bb  "}},
+		{"combining", "é́\nabc", AlignLeft, []string{"é́  ", "abc"}},
+		{"trailing newline", "ab\nc\n", AlignLeft, []string{"ab", "c "}},
+		{"empty lines", "ab\n\nc", AlignLeft, []string{"ab", "  ", "c "}},
+		{"only newline", "\n", AlignLeft, []string{""}},
+		{"two trailing newlines", "ab\n\n", AlignLeft, []string{"ab", "  "}},
+		{"CRLF", "ab\r\n中文\r\n", AlignRight, []string{"  ab", "中文"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeBlock(tt.input, tt.align)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("NormalizeBlock(%q, %d) = %q, want %q", tt.input, tt.align, got, tt.expected)
+			}
+			// All lines have equal width
+			for _, line := range got {
+				if w, w0 := String(line), String(got[0]); w != w0 {
+					t.Errorf("NormalizeBlock(%q) line %q has width %d, want %d", tt.input, line, w, w0)
+				}
+			}
+		})
+	}
+
+	// Escape sequences are zero width with ControlSequences
+	got := controlSequences.NormalizeBlock("\x1b[31mred\x1b[0m\nlonger", AlignLeft)
+	expected := []string{"\x1b[31mred\x1b[0m   ", "longer"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("NormalizeBlock() with ControlSequences = %q, want %q", got, expected)
+	}
+}
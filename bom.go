@@ -0,0 +1,26 @@
+package displaywidth
+
+import (
+	"bytes"
+	"strings"
+)
+
+// bom is the UTF-8 encoding of the byte order mark, U+FEFF.
+const bom = "\uFEFF"
+
+// StripBOMString removes a single leading UTF-8 byte order mark (U+FEFF), if
+// present, such as one written by a text editor at the start of a file.
+//
+// A BOM is zero width, so removing it does not change the display width of
+// the string. Only the first BOM is removed; U+FEFF elsewhere in the string
+// acts as a zero-width no-break space (word joiner), and is left as is.
+func StripBOMString(s string) string {
+	return strings.TrimPrefix(s, bom)
+}
+
+// StripBOMBytes removes a single leading UTF-8 byte order mark (U+FEFF), if
+// present. The result is a subslice of s; it does not allocate. See
+// [StripBOMString].
+func StripBOMBytes(s []byte) []byte {
+	return bytes.TrimPrefix(s, []byte(bom))
+}
@@ -0,0 +1,50 @@
+package displaywidth
+
+import "testing"
+
+func TestBOM(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		stripped string
+		width    int
+	}{
+		{"empty", "", "", 0},
+		{"BOM only", "\uFEFF", "", 0},
+		{"leading BOM", "\uFEFFhello", "hello", 5},
+		{"leading BOM CJK", "\uFEFF中文", "中文", 4},
+		{"no BOM", "hello", "hello", 5},
+		// Only the first BOM is removed
+		{"two BOMs", "\uFEFF\uFEFFhello", "\uFEFFhello", 5},
+		// Interior U+FEFF is a word joiner, and is left as is
+		{"interior", "hel\uFEFFlo", "hel\uFEFFlo", 5},
+		{"leading and interior", "\uFEFFhel\uFEFFlo", "hel\uFEFFlo", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The BOM is zero width, so stripping it does not change the width
+			if got := String(tt.input); got != tt.width {
+				t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.width)
+			}
+			if got := Bytes([]byte(tt.input)); got != tt.width {
+				t.Errorf("Bytes(%q) = %d, want %d", tt.input, got, tt.width)
+			}
+
+			if got := StripBOMString(tt.input); got != tt.stripped {
+				t.Errorf("StripBOMString(%q) = %q, want %q", tt.input, got, tt.stripped)
+			}
+			if got := StripBOMBytes([]byte(tt.input)); string(got) != tt.stripped {
+				t.Errorf("StripBOMBytes(%q) = %q, want %q", tt.input, got, tt.stripped)
+			}
+			if got := String(StripBOMString(tt.input)); got != tt.width {
+				t.Errorf("String(StripBOMString(%q)) = %d, want %d", tt.input, got, tt.width)
+			}
+		})
+	}
+
+	// A partial BOM is not a BOM
+	if got := StripBOMString("\xEF\xBBhello"); got != "\xEF\xBBhello" {
+		t.Errorf("StripBOMString() = %q, want %q", got, "\xEF\xBBhello")
+	}
+}
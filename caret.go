@@ -0,0 +1,54 @@
+package displaywidth
+
+import (
+	"strings"
+
+	"github.com/clipperhouse/uax29/v2/graphemes"
+)
+
+// RenderCaret rewrites C0 control characters (0x00-0x1F) and DEL (0x7F) into
+// caret notation, such as ^A for 0x01, ^[ for ESC and ^? for DEL, as by
+// cat -v. Tabs and newlines are rewritten as well.
+//
+// When [Options.ControlSequences] is true, 7-bit escape sequences are left
+// intact, consistent with their measurement as zero width.
+//
+// The width of the result, measured without CaretNotation, equals the width
+// of s measured with [Options.CaretNotation].
+func (options Options) RenderCaret(s string) string {
+	i := 0
+	for i < len(s) && !isCaretControl(s[i]) {
+		i++
+	}
+	if i == len(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	b.WriteString(s[:i])
+
+	g := graphemes.FromString(s[i:])
+	g.AnsiEscapeSequences = options.ControlSequences
+
+	for g.Next() {
+		v := g.Value()
+		if !isCaretControl(v[0]) || (len(v) > 1 && !isNewline(v)) {
+			b.WriteString(v)
+			continue
+		}
+		for i := 0; i < len(v); i++ {
+			// ^@ through ^_ for 0x00-0x1F, and ^? for DEL
+			b.WriteByte('^')
+			b.WriteByte(v[i] ^ 0x40)
+		}
+	}
+	return b.String()
+}
+
+// RenderCaret rewrites C0 control characters (0x00-0x1F) and DEL (0x7F) into
+// caret notation, such as ^A for 0x01 and ^? for DEL, as by cat -v. See
+// [Options.RenderCaret].
+func RenderCaret(s string) string {
+	return DefaultOptionsValue().RenderCaret(s)
+}
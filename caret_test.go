@@ -0,0 +1,82 @@
+package displaywidth
+
+import "testing"
+
+func TestCaretNotation(t *testing.T) {
+	caret := Options{CaretNotation: true}
+
+	// Full C0 range plus DEL
+	for b := 0; b <= 0x7F; b++ {
+		if b > 0x1F && b < 0x7F {
+			continue
+		}
+		s := string(rune(b))
+
+		expectedCaret := "^" + string(rune(b^0x40))
+		if got := RenderCaret(s); got != expectedCaret {
+			t.Errorf("RenderCaret(%q) = %q, want %q", s, got, expectedCaret)
+		}
+		if got := caret.String(s); got != 2 {
+			t.Errorf("String(%q) = %d, want 2", s, got)
+		}
+		if got := caret.Bytes([]byte(s)); got != 2 {
+			t.Errorf("Bytes(%q) = %d, want 2", s, got)
+		}
+		if got := caret.Rune(rune(b)); got != 2 {
+			t.Errorf("Rune(%q) = %d, want 2", s, got)
+		}
+		if got := DefaultOptions.String(s); got != 0 {
+			t.Errorf("default String(%q) = %d, want 0", s, got)
+		}
+	}
+
+	if got := RenderCaret("\x00\x1b\x7f"); got != "^@^[^?" {
+		t.Errorf("RenderCaret() = %q, want %q", got, "^@^[^?")
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		options  Options
+		expected string
+		width    int
+	}{
+		{"empty", "", caret, "", 0},
+		{"no controls", "hello 中文", caret, "hello 中文", 10},
+		{"bell", "a\x07b", caret, "a^Gb", 4},
+		{"tab", "a\tb", caret, "a^Ib", 4},
+		{"newline", "a\nb", caret, "a^Jb", 4},
+		{"CRLF", "a\r\nb", caret, "a^M^Jb", 6},
+		{"DEL", "a\x7f", caret, "a^?", 3},
+		{"C1 unchanged", "a\u0085", caret, "a\u0085", 1},
+		{"combining after control", "\x01\u0301", caret, "^A\u0301", 2},
+
+		// Escape sequences are ordinary controls unless ControlSequences is set
+		{"escape", "\x1b[31mred", caret, "^[[31mred", 2 + 4 + 3},
+		{"escape with ControlSequences", "\x1b[31mred", Options{CaretNotation: true, ControlSequences: true}, "\x1b[31mred", 3},
+
+		// Caret notation takes precedence over tab expansion
+		{"TabWidth", "a\tb", Options{CaretNotation: true, TabWidth: 8}, "a^Ib", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.RenderCaret(tt.input); got != tt.expected {
+				t.Errorf("RenderCaret(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+			if got := tt.options.String(tt.input); got != tt.width {
+				t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.width)
+			}
+			if got := tt.options.Bytes([]byte(tt.input)); got != tt.width {
+				t.Errorf("Bytes(%q) = %d, want %d", tt.input, got, tt.width)
+			}
+
+			// The width is consistent with the rendered string
+			plain := tt.options
+			plain.CaretNotation = false
+			if got := plain.String(tt.options.RenderCaret(tt.input)); got != tt.width {
+				t.Errorf("String(RenderCaret(%q)) = %d, want %d", tt.input, got, tt.width)
+			}
+		})
+	}
+}
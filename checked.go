@@ -0,0 +1,58 @@
+package displaywidth
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrClusterTooWide is returned by [Options.WidthChecked] for a grapheme
+// cluster whose width, according to the Unicode tables, is greater than 2.
+var ErrClusterTooWide = errors.New("displaywidth: grapheme cluster wider than 2")
+
+// WidthChecked calculates the display width of s, and checks that no
+// grapheme cluster is wider than 2. See [Options.WidthChecked].
+func WidthChecked(s string) (int, error) {
+	return DefaultOptionsValue().WidthChecked(s)
+}
+
+// WidthChecked calculates the display width of s, for the given options, and
+// checks the invariant that no grapheme cluster is wider than 2 according to
+// the Unicode tables. It returns the same width as [Options.String], and an
+// error wrapping [ErrClusterTooWide] for the first cluster that violates the
+// invariant. A violation indicates a bug, such as a faulty table update,
+// rather than a problem with s.
+//
+// Options that deliberately widen grapheme clusters, such as TabWidth,
+// CaretNotation, SplitFlags, EmojiFallback, EmojiWidth, UnknownWidth,
+// PrivateUseWidth, MaxCombiningPerCluster, MaxClusterBytes and
+// WideEnclosingMarks, are not checked; each cluster is checked as measured
+// by the options that follow the tables, which are EastAsianWidth,
+// ControlSequences, ControlSequences8Bit, EmojiSet, HonorVS15,
+// HalfwidthAsWide and NeutralAsWide.
+func (options Options) WidthChecked(s string) (int, error) {
+	s = untilNUL(s, options)
+
+	tables := Options{
+		EastAsianWidth:       options.EastAsianWidth,
+		ControlSequences:     options.ControlSequences,
+		ControlSequences8Bit: options.ControlSequences8Bit,
+		EmojiSet:             options.EmojiSet,
+		HonorVS15:            options.HonorVS15,
+		HalfwidthAsWide:      options.HalfwidthAsWide,
+		NeutralAsWide:        options.NeutralAsWide,
+	}
+
+	g := tables.StringGraphemes(s)
+	for g.Next() {
+		if w := g.Width(); w > 2 {
+			return options.String(s), clusterTooWide(g.Value(), g.Start(), w)
+		}
+	}
+	return options.String(s), nil
+}
+
+// clusterTooWide returns the error for the grapheme cluster v, at byte
+// offset start, whose width w violates the invariant of WidthChecked.
+func clusterTooWide(v string, start, w int) error {
+	return fmt.Errorf("%w: %+q at byte %d has width %d", ErrClusterTooWide, v, start, w)
+}
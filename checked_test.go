@@ -0,0 +1,116 @@
+package displaywidth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/clipperhouse/displaywidth/testdata"
+)
+
+func TestWidthChecked(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  Options
+		expected int
+	}{
+		{"empty", "", defaultOptions, 0},
+		{"ASCII", "hello", defaultOptions, 5},
+		{"CJK", "中文", defaultOptions, 4},
+		{"emoji", "😀👩\u200D💻🇺🇸", defaultOptions, 6},
+		{"combining", "e\u0301\u0302\u0303", defaultOptions, 1},
+		{"ambiguous EAW", "★", eawOptions, 2},
+		{"escape sequence", "\x1b[31mab\x1b[0m", controlSequences, 2},
+		{"lone modifiers", "a🏽🏽 中🏽", defaultOptions, 5},
+
+		// Options that widen clusters do not violate the invariant
+		{"tabs", "\tab", Options{TabWidth: 8}, 10},
+		{"caret CRLF", "\r\n", Options{CaretNotation: true}, 4},
+		{"SplitFlags", "🇺🇸", Options{SplitFlags: true}, 4},
+		{"EmojiFallback", "👨\u200D👩\u200D👧", Options{EmojiFallback: true}, 6},
+		{"EmojiWidth", "😀", Options{EmojiWidth: 3}, 3},
+		{"PrivateUseWidth", "\uE000", Options{PrivateUseWidth: 3}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.options.WidthChecked(tt.input)
+			if err != nil {
+				t.Errorf("WidthChecked(%q) returned error: %v", tt.input, err)
+			}
+			if got != tt.expected {
+				t.Errorf("WidthChecked(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if want := tt.options.String(tt.input); got != want {
+				t.Errorf("WidthChecked(%q) = %d, String() = %d", tt.input, got, want)
+			}
+		})
+	}
+
+	if got, err := WidthChecked("中文"); got != 4 || err != nil {
+		t.Errorf("WidthChecked() = (%d, %v), want (4, nil)", got, err)
+	}
+}
+
+// TestWidthCheckedAllRunes catches a regression in the tables or in
+// graphemeWidth that makes any single rune wider than 2.
+func TestWidthCheckedAllRunes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping exhaustive test in short mode")
+	}
+
+	options := []Options{
+		defaultOptions,
+		eawOptions,
+		{HalfwidthAsWide: true, HonorVS15: true},
+	}
+
+	for _, o := range options {
+		for r := rune(0); r <= utf8.MaxRune; r++ {
+			if !utf8.ValidRune(r) {
+				continue
+			}
+			s := string(r)
+			if _, err := o.WidthChecked(s); err != nil {
+				t.Errorf("WidthChecked(%+q) with %+v: %v", s, o, err)
+			}
+			// Variation selectors, and an emoji modifier, which is a lone
+			// modifier after most runes
+			for _, suffix := range []string{"\uFE0E", "\uFE0F", "\U0001F3FD"} {
+				if _, err := o.WidthChecked(s + suffix); err != nil {
+					t.Errorf("WidthChecked(%+q) with %+v: %v", s+suffix, o, err)
+				}
+			}
+		}
+	}
+}
+
+// TestWidthCheckedCorpora verifies that no grapheme cluster in the test
+// corpora is wider than 2.
+func TestWidthCheckedCorpora(t *testing.T) {
+	for _, load := range []func() ([]byte, error){testdata.Sample, testdata.InvalidUTF8, testdata.TestCases} {
+		corpus, err := load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range []Options{defaultOptions, eawOptions, controlSequences} {
+			for _, line := range strings.Split(string(corpus), "\n") {
+				if _, err := o.WidthChecked(line); err != nil {
+					t.Errorf("WidthChecked() with %+v: %v", o, err)
+				}
+			}
+		}
+	}
+}
+
+func TestClusterTooWide(t *testing.T) {
+	err := clusterTooWide("ab", 3, 5)
+	if !errors.Is(err, ErrClusterTooWide) {
+		t.Errorf("clusterTooWide() = %v, want to wrap ErrClusterTooWide", err)
+	}
+	if want := `displaywidth: grapheme cluster wider than 2: "ab" at byte 3 has width 5`; err.Error() != want {
+		t.Errorf("clusterTooWide() = %q, want %q", err.Error(), want)
+	}
+}
@@ -1,6 +1,7 @@
 package comparison
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/clipperhouse/displaywidth"
@@ -154,6 +155,47 @@ func TestLibraryBehaviorComparison(t *testing.T) {
 				"uniseg_default":         10,
 			},
 		},
+
+		// Tabs: go-runewidth and uniseg always measure a tab as width 0, as
+		// does displaywidth by default. With TabWidth, displaywidth expands
+		// tabs to the next tab stop.
+		{
+			name:  "Tab",
+			input: "\t",
+			expected: map[string]int{
+				"displaywidth_default":   0,
+				"displaywidth_options{}": 0,
+				"displaywidth_TabWidth4": 4,
+				"displaywidth_TabWidth8": 8,
+				"go-runewidth_default":   0,
+				"go-runewidth_EAW":       0,
+				"uniseg_default":         0,
+			},
+		},
+		{
+			name:  "Tab between text",
+			input: "ab\tc",
+			expected: map[string]int{
+				"displaywidth_default":   3,
+				"displaywidth_options{}": 3,
+				"displaywidth_TabWidth4": 5, // "ab", tab to column 4, "c"
+				"displaywidth_TabWidth8": 9, // "ab", tab to column 8, "c"
+				"go-runewidth_default":   3,
+				"uniseg_default":         3,
+			},
+		},
+		{
+			name:  "Tab after CJK",
+			input: "中文\tx",
+			expected: map[string]int{
+				"displaywidth_default":   5,
+				"displaywidth_options{}": 5,
+				"displaywidth_TabWidth4": 9, // "中文" is 4 columns, tab to column 8, "x"
+				"displaywidth_TabWidth8": 9,
+				"go-runewidth_default":   5,
+				"uniseg_default":         5,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -182,6 +224,17 @@ func TestLibraryBehaviorComparison(t *testing.T) {
 				}
 			}
 
+			// Test displaywidth with tab expansion
+			for _, tabWidth := range []int{4, 8} {
+				key := fmt.Sprintf("displaywidth_TabWidth%d", tabWidth)
+				got := displaywidth.Options{TabWidth: tabWidth}.String(tc.input)
+				if expected, ok := tc.expected[key]; ok {
+					if got != expected {
+						t.Errorf("displaywidth.Options{TabWidth: %d}.String() = %d, want %d", tabWidth, got, expected)
+					}
+				}
+			}
+
 			// Test go-runewidth default
 			goRunewidthDefault := runewidth.StringWidth(tc.input)
 			if expected, ok := tc.expected["go-runewidth_default"]; ok {
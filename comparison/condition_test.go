@@ -0,0 +1,37 @@
+package comparison
+
+import (
+	"testing"
+
+	"github.com/clipperhouse/displaywidth"
+	"github.com/mattn/go-runewidth"
+)
+
+// widther is the subset of *runewidth.Condition that displaywidth.Condition
+// implements, as a library accepting a width function might declare it.
+type widther interface {
+	RuneWidth(r rune) int
+	StringWidth(s string) int
+}
+
+var (
+	_ widther = runewidth.NewCondition()
+	_ widther = displaywidth.Condition{}
+)
+
+func TestConditionDropIn(t *testing.T) {
+	// Where the libraries agree, the adapter is a drop-in replacement
+	inputs := []string{"hello", "中文", "★", "café"}
+
+	for _, eaw := range []bool{false, true} {
+		c := runewidth.NewCondition()
+		c.EastAsianWidth = eaw
+		var rw, dw widther = c, displaywidth.Condition{Options: displaywidth.Options{EastAsianWidth: eaw}}
+
+		for _, s := range inputs {
+			if got, want := dw.StringWidth(s), rw.StringWidth(s); got != want {
+				t.Errorf("StringWidth(%q) with EastAsianWidth %v = %d, runewidth %d", s, eaw, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package comparison
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/clipperhouse/displaywidth"
+	"github.com/rivo/uniseg"
+)
+
+// Category classifies a disagreement between displaywidth and uniseg, for
+// triage. The categories are checked in the order they are declared, so a
+// cluster that is both a flag and a newer emoji is CategoryUnicodeVersion.
+type Category uint8
+
+const (
+	// CategoryOther is a disagreement that fits no other category.
+	CategoryOther Category = iota
+	// CategoryUnicodeVersion is a cluster containing a code point that is
+	// unassigned in Go's Unicode tables, which suggests that the libraries
+	// were built from different Unicode versions.
+	CategoryUnicodeVersion
+	// CategoryFlags is a cluster containing a regional indicator.
+	CategoryFlags
+	// CategoryVariationSelector is a cluster containing VS15 (U+FE0E) or
+	// VS16 (U+FE0F).
+	CategoryVariationSelector
+	// CategoryControl is a control character or escape sequence.
+	CategoryControl
+	// CategoryAmbiguous is an East Asian Ambiguous character.
+	CategoryAmbiguous
+	// CategoryWide is an East Asian Wide or Fullwidth character. Unicode
+	// reassigns these between versions, such as the Yijing symbols in
+	// Unicode 16.
+	CategoryWide
+	// CategoryCombining is a cluster containing a mark (Mn, Me or Mc), or
+	// a zero-width character.
+	CategoryCombining
+	// CategoryEmoji is an emoji, such as a ZWJ or modifier sequence.
+	CategoryEmoji
+	// CategorySegmentation is an input whose total widths disagree,
+	// although each of its grapheme clusters, as segmented by displaywidth,
+	// agrees. The libraries segment the input differently.
+	CategorySegmentation
+)
+
+var categoryNames = [...]string{
+	CategoryOther:             "Other",
+	CategoryUnicodeVersion:    "Unicode version",
+	CategoryFlags:             "Flags",
+	CategoryVariationSelector: "Variation selector",
+	CategoryControl:           "Control",
+	CategoryAmbiguous:         "East Asian Ambiguous",
+	CategoryWide:              "East Asian Wide",
+	CategoryCombining:         "Combining",
+	CategoryEmoji:             "Emoji",
+	CategorySegmentation:      "Segmentation",
+}
+
+// String returns a human-readable name for the category.
+func (c Category) String() string {
+	if int(c) < len(categoryNames) {
+		return categoryNames[c]
+	}
+	return "Unknown"
+}
+
+// ClusterDiff is a grapheme cluster whose width disagrees.
+type ClusterDiff struct {
+	// Value is the grapheme cluster, or the whole input for
+	// CategorySegmentation.
+	Value string
+	// Start is the byte position of Value in the input.
+	Start int
+	// DisplayWidth is the width according to displaywidth.
+	DisplayWidth int
+	// Uniseg is the width according to uniseg.
+	Uniseg int
+	// Reason is the property that determined DisplayWidth.
+	Reason displaywidth.Reason
+	// Category classifies the disagreement.
+	Category Category
+}
+
+// Diff is an input whose width disagrees, in total or in any of its grapheme
+// clusters.
+type Diff struct {
+	// Input is the string that was measured.
+	Input string
+	// DisplayWidth is the width according to displaywidth.
+	DisplayWidth int
+	// Uniseg is the width according to uniseg.
+	Uniseg int
+	// Clusters are the grapheme clusters that disagree. There is at least
+	// one.
+	Clusters []ClusterDiff
+}
+
+// Report is the result of [Compare].
+type Report struct {
+	// Options are the displaywidth options that were compared.
+	Options displaywidth.Options
+	// Inputs is the number of inputs that were compared.
+	Inputs int
+	// Diffs are the inputs that disagree, in the order they were given.
+	Diffs []Diff
+}
+
+// Compare measures each input with displaywidth, using the given options,
+// and with uniseg, and reports the inputs where they disagree, in total or
+// in any grapheme cluster. Each disagreeing cluster is classified by
+// [Category]. It is a tool for triaging interoperability bug reports, and
+// for finding regressions.
+//
+// uniseg has no equivalent of most options; only EastAsianWidth is applied
+// to both, by setting uniseg.EastAsianAmbiguousWidth for the duration of
+// the call. Compare is therefore not safe for concurrent use with other
+// callers of uniseg.
+func Compare(options displaywidth.Options, inputs []string) Report {
+	original := uniseg.EastAsianAmbiguousWidth
+	defer func() { uniseg.EastAsianAmbiguousWidth = original }()
+	if options.EastAsianWidth {
+		uniseg.EastAsianAmbiguousWidth = 2
+	} else {
+		uniseg.EastAsianAmbiguousWidth = 1
+	}
+
+	report := Report{Options: options, Inputs: len(inputs)}
+	for _, input := range inputs {
+		if diff, ok := compare(options, input); ok {
+			report.Diffs = append(report.Diffs, diff)
+		}
+	}
+	return report
+}
+
+// CompareRunes is [Compare] over each rune from lo to hi inclusive, as a
+// single-rune string. Surrogates are skipped.
+func CompareRunes(options displaywidth.Options, lo, hi rune) Report {
+	var inputs []string
+	for r := lo; r <= hi; r++ {
+		if r >= 0xD800 && r <= 0xDFFF {
+			continue
+		}
+		inputs = append(inputs, string(r))
+	}
+	return Compare(options, inputs)
+}
+
+// compare measures a single input. uniseg.EastAsianAmbiguousWidth must
+// already be set.
+func compare(options displaywidth.Options, input string) (Diff, bool) {
+	dw := options.String(input)
+	us := uniseg.StringWidth(input)
+
+	// Clusters are compared even when the totals agree, since their
+	// differences may cancel out.
+	diff := Diff{Input: input, DisplayWidth: dw, Uniseg: us}
+	for _, e := range options.ExplainString(input) {
+		w := uniseg.StringWidth(e.Value)
+		if w == e.Width {
+			continue
+		}
+		diff.Clusters = append(diff.Clusters, ClusterDiff{
+			Value:        e.Value,
+			Start:        e.Start,
+			DisplayWidth: e.Width,
+			Uniseg:       w,
+			Reason:       e.Reason,
+			Category:     classify(e.Value, e.Reason),
+		})
+	}
+
+	if len(diff.Clusters) == 0 {
+		if dw == us {
+			return Diff{}, false
+		}
+		diff.Clusters = append(diff.Clusters, ClusterDiff{
+			Value:        input,
+			DisplayWidth: dw,
+			Uniseg:       us,
+			Category:     CategorySegmentation,
+		})
+	}
+	return diff, true
+}
+
+// classify returns the category of a disagreeing grapheme cluster.
+func classify(cluster string, reason displaywidth.Reason) Category {
+	var unassigned, flag, vs, mark bool
+	for _, r := range cluster {
+		switch {
+		case r == 0xFE0E || r == 0xFE0F:
+			vs = true
+		case r >= 0x1F1E6 && r <= 0x1F1FF:
+			flag = true
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+			mark = true
+		case r != unicode.ReplacementChar && !isAssigned(r):
+			unassigned = true
+		}
+	}
+
+	switch {
+	case unassigned:
+		return CategoryUnicodeVersion
+	case flag:
+		return CategoryFlags
+	case vs:
+		return CategoryVariationSelector
+	case reason == displaywidth.ReasonControl || reason == displaywidth.ReasonEscapeSequence:
+		return CategoryControl
+	case reason == displaywidth.ReasonEastAsianAmbiguous:
+		return CategoryAmbiguous
+	case reason == displaywidth.ReasonEastAsianWide:
+		return CategoryWide
+	case mark || reason == displaywidth.ReasonZeroWidth:
+		return CategoryCombining
+	case reason == displaywidth.ReasonEmoji:
+		return CategoryEmoji
+	}
+	return CategoryOther
+}
+
+// isAssigned reports whether r is assigned in Go's Unicode tables, i.e. is
+// not in the general category Cn.
+func isAssigned(r rune) bool {
+	return unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.P, unicode.S, unicode.Z,
+		unicode.Cc, unicode.Cf, unicode.Co, unicode.Cs)
+}
+
+// Categories returns the number of disagreeing grapheme clusters in each
+// category.
+func (r Report) Categories() map[Category]int {
+	counts := make(map[Category]int)
+	for _, d := range r.Diffs {
+		for _, c := range d.Clusters {
+			counts[c.Category]++
+		}
+	}
+	return counts
+}
+
+// String returns a summary of the report: the number of disagreements,
+// followed by each category with its count and a few examples.
+func (r Report) String() string {
+	const examples = 3
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d inputs disagree\n", len(r.Diffs), r.Inputs)
+
+	byCategory := make(map[Category][]ClusterDiff)
+	for _, d := range r.Diffs {
+		for _, c := range d.Clusters {
+			byCategory[c.Category] = append(byCategory[c.Category], c)
+		}
+	}
+
+	categories := make([]Category, 0, len(byCategory))
+	for c := range byCategory {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i] < categories[j] })
+
+	for _, c := range categories {
+		clusters := byCategory[c]
+		fmt.Fprintf(&b, "%s: %d\n", c, len(clusters))
+		for i, cd := range clusters {
+			if i == examples {
+				break
+			}
+			fmt.Fprintf(&b, "\t%+q: displaywidth %d, uniseg %d (%s)\n", cd.Value, cd.DisplayWidth, cd.Uniseg, cd.Reason)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package comparison
+
+import (
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/clipperhouse/displaywidth"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Category // one per disagreeing cluster, nil if they agree
+	}{
+		{"ASCII", "hello world", nil},
+		{"CJK", "中文", nil},
+		{"emoji", "😀", nil},
+		{"flag", "🇺🇸", nil},
+		{"VS15 on emoji", "⌛︎", []Category{CategoryVariationSelector}},
+		{"VS16 keycap", "1️⃣", []Category{CategoryVariationSelector}},
+		{"spacing mark", "aः", []Category{CategoryCombining}},
+		{"prepended concatenation mark", "؀", []Category{CategoryCombining}},
+		{"unassigned default ignorable", "⁥", []Category{CategoryUnicodeVersion}},
+		{"Unicode 16 wide", "⚊", []Category{CategoryWide}},
+		{"two clusters", "a⌛︎bःc", []Category{CategoryVariationSelector, CategoryCombining}},
+	}
+
+	var inputs []string
+	for _, tt := range tests {
+		inputs = append(inputs, tt.input)
+	}
+	report := Compare(displaywidth.DefaultOptions, inputs)
+	if report.Inputs != len(inputs) {
+		t.Errorf("Inputs = %d, want %d", report.Inputs, len(inputs))
+	}
+
+	diffs := make(map[string]Diff)
+	for _, d := range report.Diffs {
+		diffs[d.Input] = d
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := diffs[tt.input]
+			if tt.expected == nil {
+				if ok {
+					t.Errorf("Compare(%+q) disagrees: %+v", tt.input, d)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("Compare(%+q) agrees, want a disagreement", tt.input)
+			}
+			if len(d.Clusters) != len(tt.expected) {
+				t.Fatalf("Compare(%+q) has %d clusters, want %d: %+v", tt.input, len(d.Clusters), len(tt.expected), d.Clusters)
+			}
+			for i, c := range d.Clusters {
+				if c.Category != tt.expected[i] {
+					t.Errorf("Compare(%+q) cluster %+q is %s, want %s", tt.input, c.Value, c.Category, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompareEastAsianWidth(t *testing.T) {
+	// EastAsianWidth is applied to uniseg too, so ambiguous characters agree
+	inputs := []string{"§", "α", "±"}
+	for _, options := range []displaywidth.Options{{}, {EastAsianWidth: true}} {
+		if report := Compare(options, inputs); len(report.Diffs) != 0 {
+			t.Errorf("Compare() with EastAsianWidth=%v disagrees:\n%s", options.EastAsianWidth, report)
+		}
+	}
+}
+
+func TestCategoryString(t *testing.T) {
+	for c := CategoryOther; c <= CategorySegmentation; c++ {
+		if s := c.String(); s == "" || s == "Unknown" {
+			t.Errorf("Category(%d).String() = %q", c, s)
+		}
+	}
+	if s := Category(255).String(); s != "Unknown" {
+		t.Errorf("Category(255).String() = %q, want %q", s, "Unknown")
+	}
+}
+
+// Investigate differences between displaywidth and uniseg.
+// Not meant to be a real test, more of a tool.
+func TestUnisegReport(t *testing.T) {
+	t.Skip("skipping differential report")
+	for _, options := range []displaywidth.Options{{}, {EastAsianWidth: true}} {
+		report := CompareRunes(options, 0, unicode.MaxRune)
+		t.Logf("EastAsianWidth=%v\n%s", options.EastAsianWidth, report)
+	}
+}
+
+func FuzzCompare(f *testing.F) {
+	seeds := []string{"hello", "⌛︎", "aः", "👩‍💻", "🇺🇸🇯", "\x1b[31m"}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		if !utf8.ValidString(s) {
+			return
+		}
+		report := Compare(displaywidth.DefaultOptions, []string{s})
+		for _, d := range report.Diffs {
+			if len(d.Clusters) == 0 {
+				t.Errorf("Compare(%+q) reported no clusters", s)
+			}
+			for _, c := range d.Clusters {
+				if c.DisplayWidth == c.Uniseg {
+					t.Errorf("Compare(%+q) cluster %+q reported agreeing widths %d", s, c.Value, c.DisplayWidth)
+				}
+				if c.Category.String() == "Unknown" {
+					t.Errorf("Compare(%+q) cluster %+q has unknown category %d", s, c.Value, c.Category)
+				}
+				if s[c.Start:c.Start+len(c.Value)] != c.Value {
+					t.Errorf("Compare(%+q) cluster %+q is not at %d", s, c.Value, c.Start)
+				}
+			}
+		}
+	})
+}
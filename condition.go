@@ -0,0 +1,21 @@
+package displaywidth
+
+// Condition adapts Options to the common width methods of
+// *runewidth.Condition from github.com/mattn/go-runewidth, so that code
+// written against that type, or against an interface of its methods, can
+// use displaywidth without changes. It implements only RuneWidth and
+// StringWidth, not the full runewidth API.
+type Condition struct {
+	// Options are the options used for measuring.
+	Options Options
+}
+
+// RuneWidth returns the display width of r, as [Options.Rune].
+func (c Condition) RuneWidth(r rune) int {
+	return c.Options.Rune(r)
+}
+
+// StringWidth returns the display width of s, as [Options.String].
+func (c Condition) StringWidth(s string) int {
+	return c.Options.String(s)
+}
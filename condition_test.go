@@ -0,0 +1,27 @@
+package displaywidth
+
+import "testing"
+
+func TestCondition(t *testing.T) {
+	options := []Options{
+		defaultOptions,
+		eawOptions,
+		controlSequences,
+		{TabWidth: 4},
+	}
+	inputs := []string{"", "hello", "中文", "★", "😀", "👩\u200D💻", "🇺🇸", "é", "\x1b[31mab\x1b[0m", "a\tb"}
+
+	for _, o := range options {
+		c := Condition{Options: o}
+		for _, s := range inputs {
+			if got, want := c.StringWidth(s), o.String(s); got != want {
+				t.Errorf("StringWidth(%q) with %+v = %d, want %d", s, o, got, want)
+			}
+			for _, r := range s {
+				if got, want := c.RuneWidth(r), o.Rune(r); got != want {
+					t.Errorf("RuneWidth(%q) with %+v = %d, want %d", r, o, got, want)
+				}
+			}
+		}
+	}
+}
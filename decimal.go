@@ -0,0 +1,68 @@
+package displaywidth
+
+import "strings"
+
+// AlignDecimal aligns a column of decimal numbers on the decimal point, and
+// right-aligns the column within width. See [Options.AlignDecimal].
+func AlignDecimal(values []string, width int) []string {
+	return DefaultOptionsValue().AlignDecimal(values, width)
+}
+
+// AlignDecimal aligns a column of decimal numbers on the decimal point, with
+// the given options, for tables of amounts. The integer parts are
+// right-aligned and the fractional parts left-aligned, so that the decimal
+// points line up, and the column is then right-aligned within width. The
+// result has one string per value, each of equal display width.
+//
+// The decimal point is the last "." or fullwidth "．" (U+FF0E) in a value. A
+// value without one is aligned as an integer part, with its end at the
+// decimal points of the others. Widths are measured, so fullwidth digits and
+// wide currency symbols align correctly.
+//
+// If the aligned values are wider than width, they are not truncated, and
+// the result is wider than width.
+func (options Options) AlignDecimal(values []string, width int) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	points := make([]int, len(values))
+	intWidth, fracWidth := 0, 0
+	for i, v := range values {
+		p := decimalPoint(v)
+		points[i] = p
+		if w := options.String(v[:p]); w > intWidth {
+			intWidth = w
+		}
+		if w := options.String(v[p:]); w > fracWidth {
+			fracWidth = w
+		}
+	}
+
+	lead := width - intWidth - fracWidth
+	if lead < 0 {
+		lead = 0
+	}
+
+	result := make([]string, len(values))
+	for i, v := range values {
+		p := points[i]
+		left := lead + intWidth - options.String(v[:p])
+		right := fracWidth - options.String(v[p:])
+		result[i] = strings.Repeat(" ", left) + v + strings.Repeat(" ", right)
+	}
+	return result
+}
+
+// decimalPoint returns the byte offset of the decimal point of v, or len(v)
+// if it has none.
+func decimalPoint(v string) int {
+	i := strings.LastIndex(v, ".")
+	if j := strings.LastIndex(v, "．"); j > i {
+		i = j
+	}
+	if i < 0 {
+		return len(v)
+	}
+	return i
+}
@@ -0,0 +1,92 @@
+package displaywidth
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAlignDecimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		width    int
+		options  Options
+		expected []string
+	}{
+		{"empty", nil, 10, defaultOptions, nil},
+		{
+			"mixed lengths",
+			[]string{"1.5", "123.25", "10", "0.125"},
+			9,
+			defaultOptions,
+			[]string{"    1.5  ", "  123.25 ", "   10    ", "    0.125"},
+		},
+		{
+			"no decimal points",
+			[]string{"1", "22", "333"},
+			5,
+			defaultOptions,
+			[]string{"    1", "   22", "  333"},
+		},
+		{
+			"negative and currency",
+			[]string{"-$1.00", "$1234.5", "$.75"},
+			8,
+			defaultOptions,
+			[]string{"  -$1.00", "$1234.5 ", "    $.75"},
+		},
+		{
+			"fullwidth digits",
+			[]string{"１２．５", "３．２５", "1.5"},
+			10,
+			defaultOptions,
+			[]string{"１２．５  ", "  ３．２５", "   1.5    "},
+		},
+		{
+			"wide currency symbol",
+			[]string{"￥100", "￥5.5", "12.75"},
+			8,
+			defaultOptions,
+			[]string{"￥100   ", "  ￥5.5 ", "   12.75"},
+		},
+		{
+			"too narrow",
+			[]string{"1.5", "100.25"},
+			3,
+			defaultOptions,
+			[]string{"  1.5 ", "100.25"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.AlignDecimal(tt.values, tt.width)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("AlignDecimal(%q, %d) = %q, want %q", tt.values, tt.width, got, tt.expected)
+			}
+
+			// Each value has equal width, and the decimal points align
+			col := -1
+			for i, v := range got {
+				w := tt.options.String(v)
+				if want := tt.options.String(got[0]); w != want {
+					t.Errorf("AlignDecimal()[%d] has width %d, want %d", i, w, want)
+				}
+				p := decimalPoint(tt.values[i])
+				if p == len(tt.values[i]) {
+					continue
+				}
+				c := tt.options.String(v[:strings.Index(v, tt.values[i])+p])
+				if col >= 0 && c != col {
+					t.Errorf("AlignDecimal()[%d] has decimal point at column %d, want %d", i, c, col)
+				}
+				col = c
+			}
+		})
+	}
+
+	if got := AlignDecimal([]string{"1.5", "10"}, 4); !reflect.DeepEqual(got, []string{" 1.5", "10  "}) {
+		t.Errorf("AlignDecimal() = %q", got)
+	}
+}
@@ -0,0 +1,50 @@
+package displaywidth
+
+import (
+	"bytes"
+
+	"github.com/clipperhouse/uax29/v2/graphemes"
+)
+
+// BytesUntil calculates the display width of s up to the first delim byte,
+// or up to maxWidth, and returns the width and the number of bytes
+// consumed. See [Options.BytesUntil].
+func BytesUntil(s []byte, delim byte, maxWidth int) (width int, consumed int) {
+	return DefaultOptionsValue().BytesUntil(s, delim, maxWidth)
+}
+
+// BytesUntil calculates the display width of s up to the first delim byte,
+// for the given options, and returns the width and the number of bytes
+// consumed, for measuring a field of a delimited record, such as in a CSV or
+// TSV parser, without slicing it first. If the field is wider than maxWidth,
+// it stops at the last grapheme cluster that fits; a negative maxWidth
+// means no limit.
+//
+// The result is always on a grapheme cluster boundary. It stops before the
+// grapheme cluster that contains delim, so s[consumed] is delim if the
+// field ended; when delim is inside a cluster, such as "\n" in "\r\n",
+// consumed is the start of that cluster. If neither delim nor maxWidth
+// stops it, consumed is len(s), or the offset of the first NUL byte when
+// StopAtNUL is true.
+//
+// Grapheme clusters are measured on their own, so options that depend on
+// context, such as TabWidth, are ignored.
+func (options Options) BytesUntil(s []byte, delim byte, maxWidth int) (width int, consumed int) {
+	g := graphemes.FromBytes(untilNUL(s, options))
+	g.AnsiEscapeSequences = options.ControlSequences
+	g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+
+	for g.Next() {
+		v := g.Value()
+		if bytes.IndexByte(v, delim) >= 0 {
+			break
+		}
+		w := graphemeWidth(v, options)
+		if maxWidth >= 0 && width+w > maxWidth {
+			break
+		}
+		width += w
+		consumed = g.End()
+	}
+	return width, consumed
+}
@@ -0,0 +1,78 @@
+package displaywidth
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestBytesUntil(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		delim    byte
+		maxWidth int
+		options  Options
+		width    int
+		consumed int
+	}{
+		{"empty", "", '\t', -1, defaultOptions, 0, 0},
+		{"no delimiter", "hello", '\t', -1, defaultOptions, 5, 5},
+		{"delimiter", "hello\tworld", '\t', -1, defaultOptions, 5, 5},
+		{"leading delimiter", "\thello", '\t', -1, defaultOptions, 0, 0},
+		{"empty field", "\t\t", '\t', -1, defaultOptions, 0, 0},
+		{"CJK field", "中文\t字符", '\t', -1, defaultOptions, 4, 6},
+		{"CJK second field", "字符测试\tx", '\t', -1, defaultOptions, 8, 12},
+		{"emoji field", "👋🏽 hi\tx", '\t', -1, defaultOptions, 5, 11},
+		{"combining field", "cafe\u0301\tx", '\t', -1, defaultOptions, 4, 6},
+		{"comma", "a,b", ',', -1, defaultOptions, 1, 1},
+
+		// maxWidth stops at the last cluster that fits
+		{"maxWidth", "hello world\tx", '\t', 5, defaultOptions, 5, 5},
+		{"maxWidth CJK", "中文字符\tx", '\t', 5, defaultOptions, 4, 6},
+		{"maxWidth zero", "hello", '\t', 0, defaultOptions, 0, 0},
+		{"maxWidth zero-width clusters", "\u0301\u0301a", '\t', 0, defaultOptions, 0, 4},
+		{"maxWidth past delimiter", "ab\tcd", '\t', 10, defaultOptions, 2, 2},
+
+		// The delimiter inside a cluster stops at the cluster's start
+		{"CRLF", "ab\r\ncd", '\n', -1, defaultOptions, 2, 2},
+		{"escape sequence", "\x1b[1;31mab;c", ';', -1, controlSequences, 0, 0},
+		{"escape sequence before delimiter", "\x1b[31mab;c", ';', -1, controlSequences, 2, 7},
+
+		{"ambiguous EAW", "★★\tx", '\t', -1, eawOptions, 4, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, consumed := tt.options.BytesUntil([]byte(tt.input), tt.delim, tt.maxWidth)
+			if width != tt.width || consumed != tt.consumed {
+				t.Errorf("BytesUntil(%q, %q, %d) = (%d, %d), want (%d, %d)", tt.input, tt.delim, tt.maxWidth, width, consumed, tt.width, tt.consumed)
+			}
+		})
+	}
+
+	// Walk the fields of a TSV record
+	record := []byte("名前\t年齢\tcity\n")
+	var widths []int
+	for len(record) > 0 {
+		width, consumed := BytesUntil(record, '\t', -1)
+		widths = append(widths, width)
+		record = record[consumed:]
+		if len(record) > 0 && record[0] == '\t' {
+			record = record[1:]
+		} else {
+			break
+		}
+	}
+	if want := []int{4, 4, 4}; !reflect.DeepEqual(widths, want) {
+		t.Errorf("BytesUntil fields have widths %v, want %v", widths, want)
+	}
+
+	// Measuring does not modify the input
+	input := []byte("中文\tx")
+	orig := append([]byte(nil), input...)
+	_, _ = BytesUntil(input, '\t', 1)
+	if !bytes.Equal(input, orig) {
+		t.Errorf("BytesUntil modified its input")
+	}
+}
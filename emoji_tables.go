@@ -0,0 +1,295 @@
+// Code generated by internal/gen/main.go. DO NOT EDIT.
+
+package displaywidth
+
+import "unicode"
+
+// extendedPictographic is the set of code points with the
+// Extended_Pictographic property, from emoji-data.txt. Along with the
+// regional indicators, these are the code points that may begin an emoji
+// grapheme cluster.
+var extendedPictographic = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00A9, Hi: 0x00A9, Stride: 1},
+		{Lo: 0x00AE, Hi: 0x00AE, Stride: 1},
+		{Lo: 0x203C, Hi: 0x203C, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+		{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+		{Lo: 0x2194, Hi: 0x2199, Stride: 1},
+		{Lo: 0x21A9, Hi: 0x21AA, Stride: 1},
+		{Lo: 0x231A, Hi: 0x231B, Stride: 1},
+		{Lo: 0x2328, Hi: 0x2328, Stride: 1},
+		{Lo: 0x23CF, Hi: 0x23CF, Stride: 1},
+		{Lo: 0x23E9, Hi: 0x23F3, Stride: 1},
+		{Lo: 0x23F8, Hi: 0x23FA, Stride: 1},
+		{Lo: 0x24C2, Hi: 0x24C2, Stride: 1},
+		{Lo: 0x25AA, Hi: 0x25AB, Stride: 1},
+		{Lo: 0x25B6, Hi: 0x25B6, Stride: 1},
+		{Lo: 0x25C0, Hi: 0x25C0, Stride: 1},
+		{Lo: 0x25FB, Hi: 0x25FE, Stride: 1},
+		{Lo: 0x2600, Hi: 0x2604, Stride: 1},
+		{Lo: 0x260E, Hi: 0x260E, Stride: 1},
+		{Lo: 0x2611, Hi: 0x2611, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2615, Stride: 1},
+		{Lo: 0x2618, Hi: 0x2618, Stride: 1},
+		{Lo: 0x261D, Hi: 0x261D, Stride: 1},
+		{Lo: 0x2620, Hi: 0x2620, Stride: 1},
+		{Lo: 0x2622, Hi: 0x2623, Stride: 1},
+		{Lo: 0x2626, Hi: 0x2626, Stride: 1},
+		{Lo: 0x262A, Hi: 0x262A, Stride: 1},
+		{Lo: 0x262E, Hi: 0x262F, Stride: 1},
+		{Lo: 0x2638, Hi: 0x263A, Stride: 1},
+		{Lo: 0x2640, Hi: 0x2640, Stride: 1},
+		{Lo: 0x2642, Hi: 0x2642, Stride: 1},
+		{Lo: 0x2648, Hi: 0x2653, Stride: 1},
+		{Lo: 0x265F, Hi: 0x2660, Stride: 1},
+		{Lo: 0x2663, Hi: 0x2663, Stride: 1},
+		{Lo: 0x2665, Hi: 0x2666, Stride: 1},
+		{Lo: 0x2668, Hi: 0x2668, Stride: 1},
+		{Lo: 0x267B, Hi: 0x267B, Stride: 1},
+		{Lo: 0x267E, Hi: 0x267F, Stride: 1},
+		{Lo: 0x2692, Hi: 0x2697, Stride: 1},
+		{Lo: 0x2699, Hi: 0x2699, Stride: 1},
+		{Lo: 0x269B, Hi: 0x269C, Stride: 1},
+		{Lo: 0x26A0, Hi: 0x26A1, Stride: 1},
+		{Lo: 0x26A7, Hi: 0x26A7, Stride: 1},
+		{Lo: 0x26AA, Hi: 0x26AB, Stride: 1},
+		{Lo: 0x26B0, Hi: 0x26B1, Stride: 1},
+		{Lo: 0x26BD, Hi: 0x26BE, Stride: 1},
+		{Lo: 0x26C4, Hi: 0x26C5, Stride: 1},
+		{Lo: 0x26C8, Hi: 0x26C8, Stride: 1},
+		{Lo: 0x26CE, Hi: 0x26CF, Stride: 1},
+		{Lo: 0x26D1, Hi: 0x26D1, Stride: 1},
+		{Lo: 0x26D3, Hi: 0x26D4, Stride: 1},
+		{Lo: 0x26E9, Hi: 0x26EA, Stride: 1},
+		{Lo: 0x26F0, Hi: 0x26F5, Stride: 1},
+		{Lo: 0x26F7, Hi: 0x26FA, Stride: 1},
+		{Lo: 0x26FD, Hi: 0x26FD, Stride: 1},
+		{Lo: 0x2702, Hi: 0x2702, Stride: 1},
+		{Lo: 0x2705, Hi: 0x2705, Stride: 1},
+		{Lo: 0x2708, Hi: 0x270D, Stride: 1},
+		{Lo: 0x270F, Hi: 0x270F, Stride: 1},
+		{Lo: 0x2712, Hi: 0x2712, Stride: 1},
+		{Lo: 0x2714, Hi: 0x2714, Stride: 1},
+		{Lo: 0x2716, Hi: 0x2716, Stride: 1},
+		{Lo: 0x271D, Hi: 0x271D, Stride: 1},
+		{Lo: 0x2721, Hi: 0x2721, Stride: 1},
+		{Lo: 0x2728, Hi: 0x2728, Stride: 1},
+		{Lo: 0x2733, Hi: 0x2734, Stride: 1},
+		{Lo: 0x2744, Hi: 0x2744, Stride: 1},
+		{Lo: 0x2747, Hi: 0x2747, Stride: 1},
+		{Lo: 0x274C, Hi: 0x274C, Stride: 1},
+		{Lo: 0x274E, Hi: 0x274E, Stride: 1},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2763, Hi: 0x2764, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27A1, Hi: 0x27A1, Stride: 1},
+		{Lo: 0x27B0, Hi: 0x27B0, Stride: 1},
+		{Lo: 0x27BF, Hi: 0x27BF, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2B05, Hi: 0x2B07, Stride: 1},
+		{Lo: 0x2B1B, Hi: 0x2B1C, Stride: 1},
+		{Lo: 0x2B50, Hi: 0x2B50, Stride: 1},
+		{Lo: 0x2B55, Hi: 0x2B55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303D, Hi: 0x303D, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3297, Stride: 1},
+		{Lo: 0x3299, Hi: 0x3299, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1F004, Hi: 0x1F004, Stride: 1},
+		{Lo: 0x1F02C, Hi: 0x1F02F, Stride: 1},
+		{Lo: 0x1F094, Hi: 0x1F09F, Stride: 1},
+		{Lo: 0x1F0AF, Hi: 0x1F0B0, Stride: 1},
+		{Lo: 0x1F0C0, Hi: 0x1F0C0, Stride: 1},
+		{Lo: 0x1F0CF, Hi: 0x1F0D0, Stride: 1},
+		{Lo: 0x1F0F6, Hi: 0x1F0FF, Stride: 1},
+		{Lo: 0x1F170, Hi: 0x1F171, Stride: 1},
+		{Lo: 0x1F17E, Hi: 0x1F17F, Stride: 1},
+		{Lo: 0x1F18E, Hi: 0x1F18E, Stride: 1},
+		{Lo: 0x1F191, Hi: 0x1F19A, Stride: 1},
+		{Lo: 0x1F1AE, Hi: 0x1F1E5, Stride: 1},
+		{Lo: 0x1F201, Hi: 0x1F20F, Stride: 1},
+		{Lo: 0x1F21A, Hi: 0x1F21A, Stride: 1},
+		{Lo: 0x1F22F, Hi: 0x1F22F, Stride: 1},
+		{Lo: 0x1F232, Hi: 0x1F23A, Stride: 1},
+		{Lo: 0x1F23C, Hi: 0x1F23F, Stride: 1},
+		{Lo: 0x1F249, Hi: 0x1F25F, Stride: 1},
+		{Lo: 0x1F266, Hi: 0x1F321, Stride: 1},
+		{Lo: 0x1F324, Hi: 0x1F393, Stride: 1},
+		{Lo: 0x1F396, Hi: 0x1F397, Stride: 1},
+		{Lo: 0x1F399, Hi: 0x1F39B, Stride: 1},
+		{Lo: 0x1F39E, Hi: 0x1F3F0, Stride: 1},
+		{Lo: 0x1F3F3, Hi: 0x1F3F5, Stride: 1},
+		{Lo: 0x1F3F7, Hi: 0x1F3FA, Stride: 1},
+		{Lo: 0x1F400, Hi: 0x1F4FD, Stride: 1},
+		{Lo: 0x1F4FF, Hi: 0x1F53D, Stride: 1},
+		{Lo: 0x1F549, Hi: 0x1F54E, Stride: 1},
+		{Lo: 0x1F550, Hi: 0x1F567, Stride: 1},
+		{Lo: 0x1F56F, Hi: 0x1F570, Stride: 1},
+		{Lo: 0x1F573, Hi: 0x1F57A, Stride: 1},
+		{Lo: 0x1F587, Hi: 0x1F587, Stride: 1},
+		{Lo: 0x1F58A, Hi: 0x1F58D, Stride: 1},
+		{Lo: 0x1F590, Hi: 0x1F590, Stride: 1},
+		{Lo: 0x1F595, Hi: 0x1F596, Stride: 1},
+		{Lo: 0x1F5A4, Hi: 0x1F5A5, Stride: 1},
+		{Lo: 0x1F5A8, Hi: 0x1F5A8, Stride: 1},
+		{Lo: 0x1F5B1, Hi: 0x1F5B2, Stride: 1},
+		{Lo: 0x1F5BC, Hi: 0x1F5BC, Stride: 1},
+		{Lo: 0x1F5C2, Hi: 0x1F5C4, Stride: 1},
+		{Lo: 0x1F5D1, Hi: 0x1F5D3, Stride: 1},
+		{Lo: 0x1F5DC, Hi: 0x1F5DE, Stride: 1},
+		{Lo: 0x1F5E1, Hi: 0x1F5E1, Stride: 1},
+		{Lo: 0x1F5E3, Hi: 0x1F5E3, Stride: 1},
+		{Lo: 0x1F5E8, Hi: 0x1F5E8, Stride: 1},
+		{Lo: 0x1F5EF, Hi: 0x1F5EF, Stride: 1},
+		{Lo: 0x1F5F3, Hi: 0x1F5F3, Stride: 1},
+		{Lo: 0x1F5FA, Hi: 0x1F64F, Stride: 1},
+		{Lo: 0x1F680, Hi: 0x1F6C5, Stride: 1},
+		{Lo: 0x1F6CB, Hi: 0x1F6D2, Stride: 1},
+		{Lo: 0x1F6D5, Hi: 0x1F6E5, Stride: 1},
+		{Lo: 0x1F6E9, Hi: 0x1F6E9, Stride: 1},
+		{Lo: 0x1F6EB, Hi: 0x1F6F0, Stride: 1},
+		{Lo: 0x1F6F3, Hi: 0x1F6FF, Stride: 1},
+		{Lo: 0x1F7DA, Hi: 0x1F7FF, Stride: 1},
+		{Lo: 0x1F80C, Hi: 0x1F80F, Stride: 1},
+		{Lo: 0x1F848, Hi: 0x1F84F, Stride: 1},
+		{Lo: 0x1F85A, Hi: 0x1F85F, Stride: 1},
+		{Lo: 0x1F888, Hi: 0x1F88F, Stride: 1},
+		{Lo: 0x1F8AE, Hi: 0x1F8AF, Stride: 1},
+		{Lo: 0x1F8BC, Hi: 0x1F8BF, Stride: 1},
+		{Lo: 0x1F8C2, Hi: 0x1F8CF, Stride: 1},
+		{Lo: 0x1F8D9, Hi: 0x1F8FF, Stride: 1},
+		{Lo: 0x1F90C, Hi: 0x1F93A, Stride: 1},
+		{Lo: 0x1F93C, Hi: 0x1F945, Stride: 1},
+		{Lo: 0x1F947, Hi: 0x1F9FF, Stride: 1},
+		{Lo: 0x1FA58, Hi: 0x1FA5F, Stride: 1},
+		{Lo: 0x1FA6E, Hi: 0x1FAFF, Stride: 1},
+		{Lo: 0x1FC00, Hi: 0x1FFFD, Stride: 1},
+	},
+	LatinOffset: 2,
+}
+
+// emojiModifierBase is the set of code points with the
+// Emoji_Modifier_Base property, from emoji-data.txt: those that an emoji
+// modifier (skin tone, U+1F3FB-U+1F3FF) may follow to form an emoji modifier
+// sequence.
+var emojiModifierBase = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x261D, Hi: 0x261D, Stride: 1},
+		{Lo: 0x26F9, Hi: 0x26F9, Stride: 1},
+		{Lo: 0x270A, Hi: 0x270D, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1F385, Hi: 0x1F385, Stride: 1},
+		{Lo: 0x1F3C2, Hi: 0x1F3C4, Stride: 1},
+		{Lo: 0x1F3C7, Hi: 0x1F3C7, Stride: 1},
+		{Lo: 0x1F3CA, Hi: 0x1F3CC, Stride: 1},
+		{Lo: 0x1F442, Hi: 0x1F443, Stride: 1},
+		{Lo: 0x1F446, Hi: 0x1F450, Stride: 1},
+		{Lo: 0x1F466, Hi: 0x1F478, Stride: 1},
+		{Lo: 0x1F47C, Hi: 0x1F47C, Stride: 1},
+		{Lo: 0x1F481, Hi: 0x1F483, Stride: 1},
+		{Lo: 0x1F485, Hi: 0x1F487, Stride: 1},
+		{Lo: 0x1F48F, Hi: 0x1F48F, Stride: 1},
+		{Lo: 0x1F491, Hi: 0x1F491, Stride: 1},
+		{Lo: 0x1F4AA, Hi: 0x1F4AA, Stride: 1},
+		{Lo: 0x1F574, Hi: 0x1F575, Stride: 1},
+		{Lo: 0x1F57A, Hi: 0x1F57A, Stride: 1},
+		{Lo: 0x1F590, Hi: 0x1F590, Stride: 1},
+		{Lo: 0x1F595, Hi: 0x1F596, Stride: 1},
+		{Lo: 0x1F645, Hi: 0x1F647, Stride: 1},
+		{Lo: 0x1F64B, Hi: 0x1F64F, Stride: 1},
+		{Lo: 0x1F6A3, Hi: 0x1F6A3, Stride: 1},
+		{Lo: 0x1F6B4, Hi: 0x1F6B6, Stride: 1},
+		{Lo: 0x1F6C0, Hi: 0x1F6C0, Stride: 1},
+		{Lo: 0x1F6CC, Hi: 0x1F6CC, Stride: 1},
+		{Lo: 0x1F90C, Hi: 0x1F90C, Stride: 1},
+		{Lo: 0x1F90F, Hi: 0x1F90F, Stride: 1},
+		{Lo: 0x1F918, Hi: 0x1F91F, Stride: 1},
+		{Lo: 0x1F926, Hi: 0x1F926, Stride: 1},
+		{Lo: 0x1F930, Hi: 0x1F939, Stride: 1},
+		{Lo: 0x1F93C, Hi: 0x1F93E, Stride: 1},
+		{Lo: 0x1F977, Hi: 0x1F977, Stride: 1},
+		{Lo: 0x1F9B5, Hi: 0x1F9B6, Stride: 1},
+		{Lo: 0x1F9B8, Hi: 0x1F9B9, Stride: 1},
+		{Lo: 0x1F9BB, Hi: 0x1F9BB, Stride: 1},
+		{Lo: 0x1F9CD, Hi: 0x1F9CF, Stride: 1},
+		{Lo: 0x1F9D1, Hi: 0x1F9DD, Stride: 1},
+		{Lo: 0x1FAC3, Hi: 0x1FAC5, Stride: 1},
+		{Lo: 0x1FAF0, Hi: 0x1FAF8, Stride: 1},
+	},
+}
+
+// emojiSMP is a bitmap of the Extended_Pictographic and
+// Emoji_Modifier_Base code points from U+1F000 to U+1FFFF, whose UTF-8
+// encodings begin with F0 9F, for checking the emoji before an emoji
+// modifier without decoding it or searching the range tables.
+var emojiSMP = [64]uint64{
+	0x0000F00000000010, // U+1F000
+	0x0000000000000000, // U+1F040
+	0x00018000FFF00000, // U+1F080
+	0xFFC0000000018001, // U+1F0C0
+	0x0000000000000000, // U+1F100
+	0xC003000000000000, // U+1F140
+	0xFFFFC00007FE4000, // U+1F180
+	0x0000003FFFFFFFFF, // U+1F1C0
+	0xF7FC80000400FFFE, // U+1F200
+	0xFFFFFFC0FFFFFE00, // U+1F240
+	0xFFFFFFFFFFFFFFFF, // U+1F280
+	0xFFFFFFFFFFFFFFFF, // U+1F2C0
+	0xFFFFFFF3FFFFFFFF, // U+1F300
+	0xFFFFFFFFFFFFFFFF, // U+1F340
+	0xFFFFFFFFCECFFFFF, // U+1F380
+	0x07B9FFFFFFFFFFFF, // U+1F3C0
+	0xFFFFFFFFFFFFFFFF, // U+1F400
+	0xFFFFFFFFFFFFFFFF, // U+1F440
+	0xFFFFFFFFFFFFFFFF, // U+1F480
+	0xBFFFFFFFFFFFFFFF, // U+1F4C0
+	0x3FFFFFFFFFFFFFFF, // U+1F500
+	0x07F980FFFFFF7E00, // U+1F540
+	0x1006013000613C80, // U+1F580
+	0xFC08810A700E001C, // U+1F5C0
+	0xFFFFFFFFFFFFFFFF, // U+1F600
+	0x000000000000FFFF, // U+1F640
+	0xFFFFFFFFFFFFFFFF, // U+1F680
+	0xFFF9FA3FFFE7F83F, // U+1F6C0
+	0x0000000000000000, // U+1F700
+	0x0000000000000000, // U+1F740
+	0x0000000000000000, // U+1F780
+	0xFFFFFFFFFC000000, // U+1F7C0
+	0x000000000000F000, // U+1F800
+	0x00000000FC00FF00, // U+1F840
+	0xF000C0000000FF00, // U+1F880
+	0xFFFFFFFFFE00FFFC, // U+1F8C0
+	0xF7FFFFFFFFFFF000, // U+1F900
+	0xFFFFFFFFFFFFFFBF, // U+1F940
+	0xFFFFFFFFFFFFFFFF, // U+1F980
+	0xFFFFFFFFFFFFFFFF, // U+1F9C0
+	0x0000000000000000, // U+1FA00
+	0xFFFFC000FF000000, // U+1FA40
+	0xFFFFFFFFFFFFFFFF, // U+1FA80
+	0xFFFFFFFFFFFFFFFF, // U+1FAC0
+	0x0000000000000000, // U+1FB00
+	0x0000000000000000, // U+1FB40
+	0x0000000000000000, // U+1FB80
+	0x0000000000000000, // U+1FBC0
+	0xFFFFFFFFFFFFFFFF, // U+1FC00
+	0xFFFFFFFFFFFFFFFF, // U+1FC40
+	0xFFFFFFFFFFFFFFFF, // U+1FC80
+	0xFFFFFFFFFFFFFFFF, // U+1FCC0
+	0xFFFFFFFFFFFFFFFF, // U+1FD00
+	0xFFFFFFFFFFFFFFFF, // U+1FD40
+	0xFFFFFFFFFFFFFFFF, // U+1FD80
+	0xFFFFFFFFFFFFFFFF, // U+1FDC0
+	0xFFFFFFFFFFFFFFFF, // U+1FE00
+	0xFFFFFFFFFFFFFFFF, // U+1FE40
+	0xFFFFFFFFFFFFFFFF, // U+1FE80
+	0xFFFFFFFFFFFFFFFF, // U+1FEC0
+	0xFFFFFFFFFFFFFFFF, // U+1FF00
+	0xFFFFFFFFFFFFFFFF, // U+1FF40
+	0xFFFFFFFFFFFFFFFF, // U+1FF80
+	0x3FFFFFFFFFFFFFFF, // U+1FFC0
+}
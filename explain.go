@@ -0,0 +1,172 @@
+package displaywidth
+
+// Reason describes the property that determined the width of a grapheme
+// cluster. It is intended for diagnostics, such as comparing widths against
+// other libraries. The values and their String representations are stable.
+type Reason uint8
+
+const (
+	// ReasonNarrow is the default: the cluster has no width-relevant
+	// properties, and is width 1. It includes an emoji that an option
+	// measures as text, such as one outside EmojiSet, or followed by VS15
+	// when HonorVS15 is true.
+	ReasonNarrow Reason = iota
+	// ReasonControl is a C0 or C1 control character, or DEL, which are
+	// width 0, or width 2 in caret notation when the CaretNotation option is
+	// true.
+	ReasonControl
+	// ReasonEscapeSequence is an ECMA-48 escape sequence, when the
+	// ControlSequences or ControlSequences8Bit option is true. Width 0,
+	// except for an OSC sequence that the OSCFilter option measures as
+	// text.
+	ReasonEscapeSequence
+	// ReasonZeroWidth is a zero-width character, such as a combining mark,
+	// format character, or zero-width space.
+	ReasonZeroWidth
+	// ReasonEastAsianWide is an East Asian Wide (W) or Fullwidth (F)
+	// character, which is width 2.
+	ReasonEastAsianWide
+	// ReasonEastAsianAmbiguous is an East Asian Ambiguous (A) character,
+	// which is width 1, or width 2 when the EastAsianWidth option is true.
+	ReasonEastAsianAmbiguous
+	// ReasonEmoji is an emoji with default emoji presentation, including
+	// ZWJ sequences and modifier sequences. Width 2, or the EmojiWidth
+	// option.
+	ReasonEmoji
+	// ReasonEmojiVS16 is a character that defaults to text presentation,
+	// promoted to emoji presentation (width 2, or the EmojiWidth option) by
+	// VS16 (U+FE0F).
+	ReasonEmojiVS16
+	// ReasonRegionalIndicator is a flag (a pair of regional indicators), or
+	// a lone regional indicator. Width 2, or the EmojiWidth option.
+	ReasonRegionalIndicator
+	// ReasonInvalidUTF8 is a byte that is not valid UTF-8, which is width 1.
+	ReasonInvalidUTF8
+	// ReasonOption is a cluster whose width was set by an option, rather
+	// than by its properties: a width option such as PrivateUseWidth,
+	// UnknownWidth, HalfwidthAsWide, NeutralAsWide or SplitFlags, or an
+	// option that adds width to a cluster, such as MaxCombiningPerCluster or
+	// VisibleJoiners.
+	ReasonOption
+)
+
+var reasonNames = [...]string{
+	ReasonNarrow:             "Narrow",
+	ReasonControl:            "Control",
+	ReasonEscapeSequence:     "Escape sequence",
+	ReasonZeroWidth:          "Zero width",
+	ReasonEastAsianWide:      "East Asian Wide",
+	ReasonEastAsianAmbiguous: "East Asian Ambiguous",
+	ReasonEmoji:              "Emoji",
+	ReasonEmojiVS16:          "Emoji via VS16",
+	ReasonRegionalIndicator:  "Regional indicator",
+	ReasonInvalidUTF8:        "Invalid UTF-8",
+	ReasonOption:             "Option",
+}
+
+// String returns a human-readable name for the reason.
+func (r Reason) String() string {
+	if int(r) < len(reasonNames) {
+		return reasonNames[r]
+	}
+	return "Unknown"
+}
+
+// GraphemeExplanation describes the width of a single grapheme cluster.
+type GraphemeExplanation struct {
+	// Value is the grapheme cluster.
+	Value string
+	// Start is the byte position of the grapheme cluster in the original string.
+	Start int
+	// Width is the display width of the grapheme cluster.
+	Width int
+	// Reason is the property that determined the width.
+	Reason Reason
+}
+
+// ExplainString returns, for each grapheme cluster in s, its width and the
+// property that determined it. It is a developer tool, for understanding
+// width decisions, and for filing bugs against discrepancies with other
+// libraries.
+//
+// Each cluster is measured on its own, so the sum of the widths equals
+// [String] unless the width depends on context; see [Options.ExplainString].
+func ExplainString(s string) []GraphemeExplanation {
+	return DefaultOptionsValue().ExplainString(s)
+}
+
+// ExplainString returns, for each grapheme cluster in s, its width and the
+// property that determined it, for the given options. It is a developer tool,
+// for understanding width decisions, and for filing bugs against
+// discrepancies with other libraries.
+//
+// Each cluster is measured on its own, so the sum of the widths equals
+// [Options.String], except with options whose widths depend on neighboring
+// clusters or the column: TabWidth, ContextualAmbiguous,
+// AmbiguousInCJKContext and DECGraphics.
+func (options Options) ExplainString(s string) []GraphemeExplanation {
+	var result []GraphemeExplanation
+	g := options.StringGraphemes(untilNUL(s, options))
+	for g.Next() {
+		width, reason := graphemeWidthReason(g.Value(), options)
+		result = append(result, GraphemeExplanation{
+			Value:  g.Value(),
+			Start:  g.Start(),
+			Width:  width,
+			Reason: reason,
+		})
+	}
+	return result
+}
+
+// isEmojiCluster reports whether the grapheme cluster is an emoji, as
+// classified for the zero Options. See [Options.WidthAndEmoji].
+func isEmojiCluster[T ~string | ~[]byte](s T) bool {
+	switch _, reason := graphemeWidthReason(s, Options{}); reason {
+	case ReasonEmoji, ReasonEmojiVS16, ReasonRegionalIndicator:
+		return true
+	}
+	return false
+}
+
+// reasonCategories maps each reason to its category in CategoryHistogram.
+var reasonCategories = [...]string{
+	ReasonNarrow:             "Narrow",
+	ReasonControl:            "Control",
+	ReasonEscapeSequence:     "Control",
+	ReasonZeroWidth:          "ZeroWidth",
+	ReasonEastAsianWide:      "Wide",
+	ReasonEastAsianAmbiguous: "Ambiguous",
+	ReasonEmoji:              "Emoji",
+	ReasonEmojiVS16:          "Emoji",
+	ReasonRegionalIndicator:  "Emoji",
+	ReasonInvalidUTF8:        "Narrow",
+	ReasonOption:             "Option",
+}
+
+// CategoryHistogram counts the grapheme clusters of s in each width
+// category. See [Options.CategoryHistogram].
+func CategoryHistogram(s string) map[string]int {
+	return DefaultOptionsValue().CategoryHistogram(s)
+}
+
+// CategoryHistogram counts the grapheme clusters of s in each width
+// category, for diagnostics such as localization dashboards. The categories
+// are "Narrow", "Wide", "Ambiguous", "Emoji", "ZeroWidth", "Control" and
+// "Option"; categories with no clusters are omitted. The counts sum to the number of
+// grapheme clusters.
+//
+// Each category groups one or more [Reason] values from
+// [Options.ExplainString]: escape sequences are "Control", flags and emoji
+// via VS16 are "Emoji", and invalid UTF-8 is "Narrow". East Asian Ambiguous
+// characters are "Ambiguous" whether or not EastAsianWidth is true, and
+// clusters whose width was set by an option are "Option".
+func (options Options) CategoryHistogram(s string) map[string]int {
+	result := make(map[string]int)
+	g := options.StringGraphemes(untilNUL(s, options))
+	for g.Next() {
+		_, reason := graphemeWidthReason(g.Value(), options)
+		result[reasonCategories[reason]]++
+	}
+	return result
+}
@@ -0,0 +1,240 @@
+package displaywidth
+
+import (
+	"reflect"
+	"testing"
+	"unicode"
+
+	"github.com/clipperhouse/displaywidth/testdata"
+)
+
+func TestExplainString(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options Options
+		width   int
+		reason  Reason
+	}{
+		{"ASCII", "a", defaultOptions, 1, ReasonNarrow},
+		{"newline", "\n", defaultOptions, 0, ReasonControl},
+		{"CRLF", "\r\n", defaultOptions, 0, ReasonControl},
+		{"DEL", "\x7f", defaultOptions, 0, ReasonControl},
+		{"C1 control", "\u0085", defaultOptions, 0, ReasonControl},
+		{"invalid UTF-8", "\xff", defaultOptions, 1, ReasonInvalidUTF8},
+		{"Latin", "\u00F1", defaultOptions, 1, ReasonNarrow},
+		{"combining mark", "\u0301", defaultOptions, 0, ReasonZeroWidth},
+		{"base with combining mark", "n\u0303", defaultOptions, 1, ReasonNarrow},
+		{"zero-width space", "\u200B", defaultOptions, 0, ReasonZeroWidth},
+		{"CJK", "中", defaultOptions, 2, ReasonEastAsianWide},
+		{"fullwidth", "Ａ", defaultOptions, 2, ReasonEastAsianWide},
+		{"ambiguous", "★", defaultOptions, 1, ReasonEastAsianAmbiguous},
+		{"ambiguous EAW", "★", eawOptions, 2, ReasonEastAsianAmbiguous},
+		{"emoji", "😀", defaultOptions, 2, ReasonEmoji},
+		{"emoji modifier sequence", "👍🏽", defaultOptions, 2, ReasonEmoji},
+		{"emoji ZWJ sequence", "👩\u200D💻", defaultOptions, 2, ReasonEmoji},
+		{"emoji VS16", "\u2702\uFE0F", defaultOptions, 2, ReasonEmojiVS16},
+		{"keycap", "1\uFE0F\u20E3", defaultOptions, 2, ReasonEmojiVS16},
+		{"ambiguous VS16", "\u2660\uFE0F", defaultOptions, 2, ReasonEmojiVS16},
+		{"text presentation", "\u2702", defaultOptions, 1, ReasonNarrow},
+		{"flag", "🇺🇸", defaultOptions, 2, ReasonRegionalIndicator},
+		{"lone regional indicator", "🇺", defaultOptions, 2, ReasonRegionalIndicator},
+		{"escape sequence", "\x1b[31m", controlSequences, 0, ReasonEscapeSequence},
+		{"ESC without ControlSequences", "\x1b", defaultOptions, 0, ReasonControl},
+		{"8-bit escape sequence", "\x9b31m", controlSequences8Bit, 0, ReasonEscapeSequence},
+		{"8-bit control", "\x85", controlSequences8Bit, 0, ReasonControl},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.ExplainString(tt.input)
+			if len(got) != 1 {
+				t.Fatalf("ExplainString(%q) returned %d explanations, want 1: %+v", tt.input, len(got), got)
+			}
+			e := got[0]
+			if e.Value != tt.input || e.Start != 0 {
+				t.Errorf("ExplainString(%q) value = %q at %d, want %q at 0", tt.input, e.Value, e.Start, tt.input)
+			}
+			if e.Width != tt.width {
+				t.Errorf("ExplainString(%q) width = %d, want %d", tt.input, e.Width, tt.width)
+			}
+			if e.Reason != tt.reason {
+				t.Errorf("ExplainString(%q) reason = %q, want %q", tt.input, e.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestExplainStringMultiple(t *testing.T) {
+	got := ExplainString("a中😀")
+	want := []GraphemeExplanation{
+		{"a", 0, 1, ReasonNarrow},
+		{"中", 1, 2, ReasonEastAsianWide},
+		{"😀", 4, 2, ReasonEmoji},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ExplainString() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ExplainString()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := ExplainString(""); len(got) != 0 {
+		t.Errorf("ExplainString(\"\") = %+v, want empty", got)
+	}
+}
+
+// TestExplainStringConsistency verifies, for each option that affects the
+// width of a grapheme cluster, that widths sum to String, and that each
+// reason agrees with the width.
+func TestExplainStringConsistency(t *testing.T) {
+	sample, err := testdata.Sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid, err := testdata.InvalidUTF8()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clusters that the options below measure differently
+	special := "\uE000 \U000F0000 \U00040000 \uFF76 \u20A9 \u00C0 \u2E3A \u00B1 " +
+		"\t\r\n\x07 \u231B\uFE0E \U0001F1FA\U0001F1F8 a\u20DD \u4E2D\u20DD " +
+		"e\u0301\u0302\u0303\u0304 \U0001F468\u200D\U0001F469\u200D\U0001F467 \u00A9\uFE0F " +
+		"\x1b]8;;http://example.com\x1b\\link\x1b]8;;\x1b\\ \x1b[31mred\x1b[0m \u0085 " +
+		"a\U0001F3FD \U0001F3FD\U0001F3FD \u4E2D\U0001F3FD \U0001F000\U0001F3FD"
+	inputs := []string{string(sample), string(invalid), special}
+
+	latin1 := &unicode.RangeTable{R16: []unicode.Range16{{Lo: 0x00A0, Hi: 0x00FF, Stride: 1}}}
+	all := []Options{
+		defaultOptions,
+		eawOptions,
+		controlSequences,
+		{ControlSequences8Bit: true},
+		{EastAsianWidth: true, NarrowAmbiguous: latin1},
+		{CombiningOverride: unicode.Mc},
+		{ControlSequences: true, OSCFilter: &OSCFilter{ZeroWidth: func([]byte) bool { return false }}},
+		{SplitFlags: true},
+		{CaretNotation: true},
+		{MaxCombiningPerCluster: 2},
+		{MaxClusterBytes: 8},
+		{WideEnclosingMarks: true},
+		{EmojiFallback: true},
+		{VisibleJoiners: true},
+		{UnknownWidth: 2},
+		{EmojiSet: latin1},
+		{EmojiWidth: 1},
+		{EmojiWidth: 3},
+		{HonorVS15: true},
+		{PrivateUseWidth: 2},
+		{HalfwidthAsWide: true},
+		{NeutralAsWide: true},
+	}
+
+	for _, options := range all {
+		for _, input := range inputs {
+			sum := 0
+			for _, e := range options.ExplainString(input) {
+				sum += e.Width
+
+				var want []int
+				switch e.Reason {
+				case ReasonControl:
+					want = []int{0}
+					if options.CaretNotation {
+						want = []int{0, 2, 4}
+					}
+				case ReasonEscapeSequence:
+					want = []int{0}
+					if options.OSCFilter != nil {
+						want = nil
+					}
+				case ReasonZeroWidth:
+					want = []int{0}
+				case ReasonNarrow, ReasonInvalidUTF8:
+					want = []int{1}
+				case ReasonEastAsianWide:
+					want = []int{2}
+				case ReasonEmoji, ReasonEmojiVS16, ReasonRegionalIndicator:
+					want = []int{options.emojiWidth()}
+				case ReasonEastAsianAmbiguous:
+					want = []int{1, 2}
+				case ReasonOption:
+					want = nil
+				}
+				ok := want == nil
+				for _, w := range want {
+					ok = ok || e.Width == w
+				}
+				if !ok {
+					t.Errorf("%+v: ExplainString cluster %q has width %d, inconsistent with reason %q", options, e.Value, e.Width, e.Reason)
+				}
+				if e.Reason == ReasonOption && options == defaultOptions {
+					t.Errorf("ExplainString cluster %q has reason %q with default options", e.Value, e.Reason)
+				}
+			}
+			if want := options.String(input); sum != want {
+				t.Errorf("%+v: ExplainString widths sum to %d, want %d", options, sum, want)
+			}
+		}
+	}
+}
+
+func TestReasonString(t *testing.T) {
+	for r := ReasonNarrow; r <= ReasonOption; r++ {
+		if r.String() == "" || r.String() == "Unknown" {
+			t.Errorf("Reason(%d).String() = %q", r, r.String())
+		}
+	}
+	if got := Reason(255).String(); got != "Unknown" {
+		t.Errorf("Reason(255).String() = %q, want %q", got, "Unknown")
+	}
+}
+
+func TestCategoryHistogram(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  Options
+		expected map[string]int
+	}{
+		{"empty", "", defaultOptions, map[string]int{}},
+		{"ASCII", "hello", defaultOptions, map[string]int{"Narrow": 5}},
+		{
+			"mixed", "Hi 中文 😀🇯🇵 ★é\u200b\n", defaultOptions,
+			map[string]int{"Narrow": 5, "Wide": 2, "Emoji": 2, "Ambiguous": 2, "ZeroWidth": 1, "Control": 1},
+		},
+		{"ambiguous with EAW", "★±", eawOptions, map[string]int{"Ambiguous": 2}},
+		{"emoji via VS16", "☺\uFE0F", defaultOptions, map[string]int{"Emoji": 1}},
+		{"ZWJ sequence", "👨\u200d👩\u200d👧", defaultOptions, map[string]int{"Emoji": 1}},
+		{"escape sequence", "\x1b[31mred\x1b[0m", controlSequences, map[string]int{"Control": 2, "Narrow": 3}},
+		{"invalid UTF-8", "a\xff", defaultOptions, map[string]int{"Narrow": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.CategoryHistogram(tt.input)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("CategoryHistogram(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+
+			// The counts sum to the number of grapheme clusters
+			sum, clusters := 0, 0
+			for _, n := range got {
+				sum += n
+			}
+			g := tt.options.StringGraphemes(tt.input)
+			for g.Next() {
+				clusters++
+			}
+			if sum != clusters {
+				t.Errorf("CategoryHistogram(%q) counts sum to %d, want %d", tt.input, sum, clusters)
+			}
+		})
+	}
+
+	if got := CategoryHistogram("中a"); !reflect.DeepEqual(got, map[string]int{"Wide": 1, "Narrow": 1}) {
+		t.Errorf("CategoryHistogram() = %v", got)
+	}
+}
@@ -0,0 +1,31 @@
+package displaywidth
+
+// WidthFunc returns a function that measures a string as [String] does, for
+// libraries that accept a custom width function, such as TUI renderers. The
+// function uses the default options at the time of each call, so it follows
+// [SetDefaultOptions].
+func WidthFunc() func(string) int {
+	return String
+}
+
+// RuneWidthFunc returns a function that measures a rune as [Rune] does, for
+// libraries that accept a custom rune width function. The function uses the
+// default options at the time of each call, so it follows
+// [SetDefaultOptions].
+func RuneWidthFunc() func(rune) int {
+	return Rune
+}
+
+// WidthFunc returns a function that measures a string as [Options.String]
+// does, with a copy of options, for libraries that accept a custom width
+// function, such as TUI renderers.
+func (options Options) WidthFunc() func(string) int {
+	return options.String
+}
+
+// RuneWidthFunc returns a function that measures a rune as [Options.Rune]
+// does, with a copy of options, for libraries that accept a custom rune
+// width function.
+func (options Options) RuneWidthFunc() func(rune) int {
+	return options.Rune
+}
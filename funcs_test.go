@@ -0,0 +1,47 @@
+package displaywidth
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestWidthFunc(t *testing.T) {
+	inputs := []string{"", "hello", "中文", "★", "👋🏽", "🇯🇵", "é", "\x1b[31mred\x1b[0m"}
+
+	for _, options := range []Options{defaultOptions, eawOptions, controlSequences} {
+		width := options.WidthFunc()
+		runeWidth := options.RuneWidthFunc()
+		for _, s := range inputs {
+			if got, want := width(s), options.String(s); got != want {
+				t.Errorf("%+v WidthFunc()(%q) = %d, want %d", options, s, got, want)
+			}
+			for _, r := range s {
+				if got, want := runeWidth(r), options.Rune(r); got != want {
+					t.Errorf("%+v RuneWidthFunc()(%q) = %d, want %d", options, r, got, want)
+				}
+			}
+		}
+	}
+
+	// The functions hold a copy of the options
+	options := Options{}
+	width := options.WidthFunc()
+	options.EastAsianWidth = true
+	if got := width("★"); got != 1 {
+		t.Errorf("WidthFunc()(%q) = %d, want 1", "★", got)
+	}
+
+	// The package-level functions follow SetDefaultOptions
+	t.Cleanup(func() { defaultOptionsOverride = atomic.Value{} })
+	width, runeWidth := WidthFunc(), RuneWidthFunc()
+	if got := width("★"); got != 1 {
+		t.Errorf("WidthFunc()(%q) = %d, want 1", "★", got)
+	}
+	SetDefaultOptions(Options{EastAsianWidth: true})
+	if got := width("★"); got != 2 {
+		t.Errorf("WidthFunc()(%q) = %d, want 2", "★", got)
+	}
+	if got := runeWidth('★'); got != 2 {
+		t.Errorf("RuneWidthFunc()(%q) = %d, want 2", '★', got)
+	}
+}
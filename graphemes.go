@@ -11,11 +11,24 @@ import (
 type Graphemes[T ~string | ~[]byte] struct {
 	iter    *graphemes.Iterator[T]
 	options Options
+	// visible indicates that zero-width grapheme clusters are skipped
+	visible bool
+	// tables indicates that no per-cluster option is set, so that Width
+	// can use tableWidth
+	tables bool
 }
 
 // Next advances the iterator to the next grapheme cluster.
 func (g *Graphemes[T]) Next() bool {
-	return g.iter.Next()
+	if !g.visible {
+		return g.iter.Next()
+	}
+	for g.iter.Next() {
+		if g.Width() > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // Value returns the current grapheme cluster.
@@ -25,16 +38,31 @@ func (g *Graphemes[T]) Value() T {
 
 // Width returns the display width of the current grapheme cluster.
 func (g *Graphemes[T]) Width() int {
+	if g.tables {
+		return tableWidth(g.Value(), g.options.EastAsianWidth, g.options.ControlSequences8Bit)
+	}
 	return graphemeWidth(g.Value(), g.options)
 }
 
+// Start returns the byte position of the current grapheme cluster in the
+// original string or []byte.
+func (g *Graphemes[T]) Start() int {
+	return g.iter.Start()
+}
+
+// End returns the byte position after the current grapheme cluster in the
+// original string or []byte.
+func (g *Graphemes[T]) End() int {
+	return g.iter.End()
+}
+
 // StringGraphemes returns an iterator over grapheme clusters for the given
 // string.
 //
 // Iterate using the Next method, and get the width of the current grapheme
 // using the Width method.
 func StringGraphemes(s string) Graphemes[string] {
-	return DefaultOptions.StringGraphemes(s)
+	return DefaultOptionsValue().StringGraphemes(s)
 }
 
 // StringGraphemes returns an iterator over grapheme clusters for the given
@@ -47,7 +75,7 @@ func (options Options) StringGraphemes(s string) Graphemes[string] {
 	g.AnsiEscapeSequences = options.ControlSequences
 	g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
 
-	return Graphemes[string]{iter: g, options: options}
+	return Graphemes[string]{iter: g, options: options, tables: !options.clusterOptions()}
 }
 
 // BytesGraphemes returns an iterator over grapheme clusters for the given
@@ -56,7 +84,7 @@ func (options Options) StringGraphemes(s string) Graphemes[string] {
 // Iterate using the Next method, and get the width of the current grapheme
 // using the Width method.
 func BytesGraphemes(s []byte) Graphemes[[]byte] {
-	return DefaultOptions.BytesGraphemes(s)
+	return DefaultOptionsValue().BytesGraphemes(s)
 }
 
 // BytesGraphemes returns an iterator over grapheme clusters for the given
@@ -69,5 +97,160 @@ func (options Options) BytesGraphemes(s []byte) Graphemes[[]byte] {
 	g.AnsiEscapeSequences = options.ControlSequences
 	g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
 
-	return Graphemes[[]byte]{iter: g, options: options}
+	return Graphemes[[]byte]{iter: g, options: options, tables: !options.clusterOptions()}
+}
+
+// StringVisibleGraphemes returns an iterator over the grapheme clusters of
+// the given string that have nonzero width, skipping zero-width clusters such
+// as control characters and escape sequences.
+//
+// Each cluster is measured on its own, as by the Width method, so the sum of
+// the widths of the yielded clusters equals [String] unless the width
+// depends on context; see [Options.StringVisibleGraphemes]. Use the Start and
+// End methods to map clusters back to the original string.
+func StringVisibleGraphemes(s string) Graphemes[string] {
+	return DefaultOptionsValue().StringVisibleGraphemes(s)
+}
+
+// StringVisibleGraphemes returns an iterator over the grapheme clusters of
+// the given string that have nonzero width, with the given options.
+//
+// Each cluster is measured on its own, as by the Width method, so the sum of
+// the widths of the yielded clusters equals [Options.String], except with
+// options whose widths depend on neighboring clusters or the column:
+// TabWidth, ContextualAmbiguous, AmbiguousInCJKContext and DECGraphics. Use
+// the Start and End methods to map clusters back to the original string.
+func (options Options) StringVisibleGraphemes(s string) Graphemes[string] {
+	g := options.StringGraphemes(s)
+	g.visible = true
+	return g
+}
+
+// BytesVisibleGraphemes returns an iterator over the grapheme clusters of
+// the given []byte that have nonzero width, skipping zero-width clusters such
+// as control characters and escape sequences.
+//
+// Each cluster is measured on its own, as by the Width method, so the sum of
+// the widths of the yielded clusters equals [Bytes] unless the width depends
+// on context; see [Options.BytesVisibleGraphemes]. Use the Start and End
+// methods to map clusters back to the original []byte.
+func BytesVisibleGraphemes(s []byte) Graphemes[[]byte] {
+	return DefaultOptionsValue().BytesVisibleGraphemes(s)
+}
+
+// BytesVisibleGraphemes returns an iterator over the grapheme clusters of
+// the given []byte that have nonzero width, with the given options.
+//
+// Each cluster is measured on its own, as by the Width method, so the sum of
+// the widths of the yielded clusters equals [Options.Bytes], except with
+// options whose widths depend on neighboring clusters or the column:
+// TabWidth, ContextualAmbiguous, AmbiguousInCJKContext and DECGraphics. Use
+// the Start and End methods to map clusters back to the original []byte.
+func (options Options) BytesVisibleGraphemes(s []byte) Graphemes[[]byte] {
+	g := options.BytesGraphemes(s)
+	g.visible = true
+	return g
+}
+
+// GraphemeOffset returns the byte offset of the start of the nth grapheme
+// cluster (0-based) of the given string, or len(s) if s has n or fewer
+// clusters. It is useful for moving a cursor by grapheme cluster. A negative
+// n is treated as 0.
+func GraphemeOffset(s string, n int) int {
+	return DefaultOptionsValue().GraphemeOffset(s, n)
+}
+
+// GraphemeOffset returns the byte offset of the start of the nth grapheme
+// cluster (0-based) of the given string, with the given options, or len(s) if
+// s has n or fewer clusters. A negative n is treated as 0.
+//
+// The offset does not depend on width. Options are relevant only to
+// segmentation: when [Options.ControlSequences] is true, an escape sequence
+// counts as a single cluster.
+func (options Options) GraphemeOffset(s string, n int) int {
+	g := options.StringGraphemes(s)
+	for i := 0; g.Next(); i++ {
+		if i >= n {
+			return g.Start()
+		}
+	}
+	return len(s)
+}
+
+// ColumnToByteOffset returns the byte offset of the grapheme cluster that
+// covers the given display column (0-based) of s, such as for positioning a
+// cursor at a mouse click. See [Options.ColumnToByteOffset].
+func ColumnToByteOffset(s string, col int) (offset int, exact bool) {
+	return DefaultOptionsValue().ColumnToByteOffset(s, col)
+}
+
+// ColumnToByteOffset returns the byte offset of the grapheme cluster that
+// covers the given display column (0-based) of s, with the given options,
+// such as for positioning a cursor at a mouse click.
+//
+// exact reports whether col is the first column of the cluster. It is false
+// when col lands on a later column of a wide cluster, such as the second cell
+// of a CJK character; callers may then choose to round the offset to the end
+// of the cluster.
+//
+// If col is at or beyond the width of s, offset is len(s), and exact reports
+// whether col equals the width. A negative col is treated as 0. Zero-width
+// clusters do not cover any column.
+//
+// For options whose widths depend on context, such as [Options.TabWidth],
+// columns are counted as [Options.String] counts them, so a tab covers the
+// columns up to the next tab stop.
+func (options Options) ColumnToByteOffset(s string, col int) (offset int, exact bool) {
+	if col < 0 {
+		col = 0
+	}
+	if options.contextual() {
+		return options.columnToByteOffsetContext(s, col)
+	}
+
+	start := 0
+	g := options.StringGraphemes(s)
+	for g.Next() {
+		w := g.Width()
+		if col < start+w {
+			return g.Start(), col == start
+		}
+		start += w
+	}
+	return len(s), col == start
+}
+
+// columnToByteOffsetContext is ColumnToByteOffset for options whose widths
+// depend on context, such as TabWidth. Each cluster is measured following
+// the text before it, as by String.
+func (options Options) columnToByteOffsetContext(s string, col int) (offset int, exact bool) {
+	g := graphemes.FromString(s)
+	g.AnsiEscapeSequences = options.ControlSequences || options.DECGraphics
+	g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+
+	// prev is the start of the last cluster of nonzero width
+	start, prev := 0, 0
+	var state contextState
+	for g.Next() {
+		w, next := widthFrom(g.Value(), state, options)
+		if state.pending && next.cjk {
+			// With AmbiguousInCJKContext, the column added to an ambiguous
+			// cluster followed by CJK is counted with the CJK cluster, but
+			// belongs to the ambiguous one
+			if col == start {
+				return prev, false
+			}
+			start++
+			w--
+		}
+		if col < start+w {
+			return g.Start(), col == start
+		}
+		if w > 0 {
+			prev = g.Start()
+		}
+		start += w
+		state = next
+	}
+	return len(s), col == start
 }
@@ -0,0 +1,263 @@
+package displaywidth
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/clipperhouse/displaywidth/testdata"
+)
+
+func TestGraphemesStartEnd(t *testing.T) {
+	input := "a中😀\u0301\x1b[31mb"
+	iter := controlSequences.StringGraphemes(input)
+
+	var rebuilt string
+	prev := 0
+	for iter.Next() {
+		if iter.Start() != prev {
+			t.Errorf("Start() = %d, want %d", iter.Start(), prev)
+		}
+		if got := input[iter.Start():iter.End()]; got != iter.Value() {
+			t.Errorf("input[Start():End()] = %q, want Value() %q", got, iter.Value())
+		}
+		rebuilt += iter.Value()
+		prev = iter.End()
+	}
+	if rebuilt != input {
+		t.Errorf("concatenated graphemes = %q, want %q", rebuilt, input)
+	}
+}
+
+func TestVisibleGraphemes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  Options
+		expected []string
+	}{
+		{"empty", "", defaultOptions, nil},
+		{"ASCII", "abc", defaultOptions, []string{"a", "b", "c"}},
+		{"controls skipped", "a\nb\tc\x00", defaultOptions, []string{"a", "b", "c"}},
+		{"zero-width skipped", "\u200Ba\u200Bb\u200B", defaultOptions, []string{"a", "b"}},
+		{"combining marks kept with base", "e\u0301中", defaultOptions, []string{"e\u0301", "中"}},
+		{"escape sequences skipped", "\x1b[31mred\x1b[0m", controlSequences, []string{"r", "e", "d"}},
+		{"escape sequences without option", "\x1b[0m", defaultOptions, []string{"[", "0", "m"}},
+		{"only zero-width", "\n\u200B\u0301", defaultOptions, nil},
+		{"emoji ZWJ sequence", "a👩\u200D💻b", defaultOptions, []string{"a", "👩\u200D💻", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			sum := 0
+			iter := tt.options.StringVisibleGraphemes(tt.input)
+			for iter.Next() {
+				if iter.Width() == 0 {
+					t.Errorf("StringVisibleGraphemes(%q) yielded zero-width %q", tt.input, iter.Value())
+				}
+				if v := tt.input[iter.Start():iter.End()]; v != iter.Value() {
+					t.Errorf("input[Start():End()] = %q, want %q", v, iter.Value())
+				}
+				got = append(got, iter.Value())
+				sum += iter.Width()
+			}
+
+			if len(got) != len(tt.expected) {
+				t.Fatalf("StringVisibleGraphemes(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("StringVisibleGraphemes(%q) = %q, want %q", tt.input, got, tt.expected)
+					break
+				}
+			}
+			if want := tt.options.String(tt.input); sum != want {
+				t.Errorf("StringVisibleGraphemes(%q) sum = %d, want %d (from String)", tt.input, sum, want)
+			}
+
+			bsum := 0
+			biter := tt.options.BytesVisibleGraphemes([]byte(tt.input))
+			for biter.Next() {
+				bsum += biter.Width()
+			}
+			if bsum != sum {
+				t.Errorf("BytesVisibleGraphemes(%q) sum = %d, want %d", tt.input, bsum, sum)
+			}
+		})
+	}
+
+	n := 0
+	iter := StringVisibleGraphemes("a\nb")
+	for iter.Next() {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("StringVisibleGraphemes yielded %d clusters, want 2", n)
+	}
+	biter := BytesVisibleGraphemes([]byte("a\nb"))
+	n = 0
+	for biter.Next() {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("BytesVisibleGraphemes yielded %d clusters, want 2", n)
+	}
+}
+
+func TestGraphemeOffset(t *testing.T) {
+	// "a", family ZWJ sequence, "b", "e" + combining acute, "c"
+	family := "👨\u200D👩\u200D👧"
+	input := "a" + family + "be\u0301c"
+
+	tests := []struct {
+		n        int
+		expected int
+	}{
+		{-1, 0},
+		{0, 0},
+		{1, 1},
+		// The whole ZWJ sequence is one cluster
+		{2, 1 + len(family)},
+		{3, 2 + len(family)},
+		{4, 2 + len(family) + len("e\u0301")},
+		{5, len(input)},
+		{100, len(input)},
+	}
+
+	for _, tt := range tests {
+		if got := GraphemeOffset(input, tt.n); got != tt.expected {
+			t.Errorf("GraphemeOffset(%q, %d) = %d, want %d", input, tt.n, got, tt.expected)
+		}
+	}
+
+	if got := GraphemeOffset("", 0); got != 0 {
+		t.Errorf("GraphemeOffset(\"\", 0) = %d, want 0", got)
+	}
+
+	// With ControlSequences, an escape sequence is one cluster
+	esc := "\x1b[31mab"
+	if got := controlSequences.GraphemeOffset(esc, 1); got != 5 {
+		t.Errorf("GraphemeOffset(%q, 1) = %d, want 5", esc, got)
+	}
+	if got := defaultOptions.GraphemeOffset(esc, 1); got != 1 {
+		t.Errorf("GraphemeOffset(%q, 1) = %d, want 1", esc, got)
+	}
+}
+
+func TestColumnToByteOffset(t *testing.T) {
+	// Columns: a=0, 中=1-2, b=3, 😀=4-5, e+acute=6
+	input := "a中b😀e\u0301"
+
+	tests := []struct {
+		name           string
+		col            int
+		expectedOffset int
+		expectedExact  bool
+	}{
+		{"negative", -1, 0, true},
+		{"first", 0, 0, true},
+		{"CJK first cell", 1, 1, true},
+		{"CJK second cell", 2, 1, false},
+		{"after CJK", 3, 4, true},
+		{"emoji first cell", 4, 5, true},
+		{"emoji second cell", 5, 5, false},
+		{"combining", 6, 9, true},
+		{"end", 7, len(input), true},
+		{"beyond end", 10, len(input), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, exact := ColumnToByteOffset(input, tt.col)
+			if offset != tt.expectedOffset || exact != tt.expectedExact {
+				t.Errorf("ColumnToByteOffset(%q, %d) = (%d, %v), want (%d, %v)",
+					input, tt.col, offset, exact, tt.expectedOffset, tt.expectedExact)
+			}
+		})
+	}
+
+	// Zero-width escape sequences do not cover a column
+	esc := "\x1b[31m中"
+	if offset, exact := controlSequences.ColumnToByteOffset(esc, 1); offset != 5 || exact {
+		t.Errorf("ColumnToByteOffset(%q, 1) = (%d, %v), want (5, false)", esc, offset, exact)
+	}
+
+	// Ambiguous characters are wide with EastAsianWidth
+	if offset, exact := eawOptions.ColumnToByteOffset("★a", 2); offset != 3 || !exact {
+		t.Errorf("ColumnToByteOffset(%q, 2) = (%d, %v), want (3, true)", "★a", offset, exact)
+	}
+
+	// Tabs cover the columns up to the next tab stop
+	tabs := "ab\tc\td"
+	tab8 := Options{TabWidth: 8}
+	for _, tt := range []struct {
+		col            int
+		expectedOffset int
+		expectedExact  bool
+	}{
+		{2, 2, true},
+		{4, 2, false},
+		{7, 2, false},
+		{8, 3, true},
+		{9, 4, true},
+		{16, 5, true},
+		{17, len(tabs), true},
+	} {
+		if offset, exact := tab8.ColumnToByteOffset(tabs, tt.col); offset != tt.expectedOffset || exact != tt.expectedExact {
+			t.Errorf("ColumnToByteOffset(%q, %d) with TabWidth = (%d, %v), want (%d, %v)",
+				tabs, tt.col, offset, exact, tt.expectedOffset, tt.expectedExact)
+		}
+	}
+
+	// An ambiguous character before CJK covers two columns with
+	// AmbiguousInCJKContext
+	cjk := Options{AmbiguousInCJKContext: true}
+	if offset, exact := cjk.ColumnToByteOffset("×漢", 1); offset != 0 || exact {
+		t.Errorf("ColumnToByteOffset(%q, 1) = (%d, %v), want (0, false)", "×漢", offset, exact)
+	}
+	if offset, exact := cjk.ColumnToByteOffset("×漢", 2); offset != 2 || !exact {
+		t.Errorf("ColumnToByteOffset(%q, 2) = (%d, %v), want (2, true)", "×漢", offset, exact)
+	}
+
+	if offset, exact := ColumnToByteOffset("", 0); offset != 0 || !exact {
+		t.Errorf("ColumnToByteOffset(\"\", 0) = (%d, %v), want (0, true)", offset, exact)
+	}
+}
+
+// TestGraphemesSumCorpora verifies, without fuzzing, that the width of each
+// line of the test corpora equals the sum of the widths of its grapheme
+// clusters, for String and Bytes.
+func TestGraphemesSumCorpora(t *testing.T) {
+	var corpora [][]byte
+	for _, load := range []func() ([]byte, error){testdata.Sample, testdata.InvalidUTF8, testdata.TestCases} {
+		corpus, err := load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		corpora = append(corpora, corpus)
+	}
+
+	for _, options := range []Options{defaultOptions, eawOptions, controlSequences, {EastAsianWidth: true, ControlSequences: true}} {
+		for _, corpus := range corpora {
+			for _, line := range bytes.Split(corpus, []byte("\n")) {
+				sum := 0
+				g := options.StringGraphemes(string(line))
+				for g.Next() {
+					sum += g.Width()
+				}
+				if got := options.String(string(line)); got != sum {
+					t.Errorf("%+v String(%q) = %d, want grapheme sum %d", options, line, got, sum)
+				}
+
+				sum = 0
+				bg := options.BytesGraphemes(line)
+				for bg.Next() {
+					sum += bg.Width()
+				}
+				if got := options.Bytes(line); got != sum {
+					t.Errorf("%+v Bytes(%q) = %d, want grapheme sum %d", options, line, got, sum)
+				}
+			}
+		}
+	}
+}
@@ -1,17 +1,21 @@
-// Package main generates tries of Unicode properties for string width calculation
+// Package main generates tries and range tables of Unicode properties for string width calculation
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"path/filepath"
 )
 
 func main() {
+	dataDir := flag.String("data-dir", defaultDataDir, "directory of Unicode data files; missing files are downloaded into it")
+	flag.Parse()
+
 	fmt.Println("Generating string width trie...")
 
 	// Parse Unicode data
-	data, err := ParseUnicodeData()
+	data, err := ParseUnicodeData(*dataDir)
 	if err != nil {
 		log.Fatalf("Failed to parse Unicode data: %v", err)
 	}
@@ -29,4 +33,10 @@ func main() {
 	}
 
 	fmt.Println("Trie generation completed successfully!")
+
+	// Write range tables to output file
+	tablesPath := filepath.Join("..", "..", "emoji_tables.go")
+	if err := WriteTablesGo(data, tablesPath); err != nil {
+		log.Fatalf("Failed to write range tables: %v", err)
+	}
 }
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+	"unicode"
+)
+
+// RangeTableDefinition describes a unicode.RangeTable to generate from a set
+// of code points
+type RangeTableDefinition struct {
+	Name    string
+	Comment string
+	Runes   map[rune]bool
+}
+
+// rangeTableDefinitions returns the range tables to generate from the
+// Unicode data
+func rangeTableDefinitions(data *UnicodeData) []RangeTableDefinition {
+	return []RangeTableDefinition{
+		{
+			Name: "extendedPictographic",
+			Comment: `extendedPictographic is the set of code points with the
+Extended_Pictographic property, from emoji-data.txt. Along with the
+regional indicators, these are the code points that may begin an emoji
+grapheme cluster.`,
+			Runes: data.ExtendedPictographic,
+		},
+		{
+			Name: "emojiModifierBase",
+			Comment: `emojiModifierBase is the set of code points with the
+Emoji_Modifier_Base property, from emoji-data.txt: those that an emoji
+modifier (skin tone, U+1F3FB-U+1F3FF) may follow to form an emoji modifier
+sequence.`,
+			Runes: data.EmojiModifierBase,
+		},
+	}
+}
+
+// BitmapDefinition describes a bitmap to generate of the code points of a
+// set in the range Lo to Lo+64*Words-1, one bit per code point
+type BitmapDefinition struct {
+	Name    string
+	Comment string
+	Lo      rune
+	Words   int
+	Runes   map[rune]bool
+}
+
+// bitmapDefinitions returns the bitmaps to generate from the Unicode data
+func bitmapDefinitions(data *UnicodeData) []BitmapDefinition {
+	// An emoji modifier may follow either kind of emoji
+	emoji := make(map[rune]bool)
+	for r := range data.ExtendedPictographic {
+		emoji[r] = true
+	}
+	for r := range data.EmojiModifierBase {
+		emoji[r] = true
+	}
+
+	return []BitmapDefinition{
+		{
+			Name: "emojiSMP",
+			Comment: `emojiSMP is a bitmap of the Extended_Pictographic and
+Emoji_Modifier_Base code points from U+1F000 to U+1FFFF, whose UTF-8
+encodings begin with F0 9F, for checking the emoji before an emoji
+modifier without decoding it or searching the range tables.`,
+			Lo:    0x1F000,
+			Words: 64,
+			Runes: emoji,
+		},
+	}
+}
+
+// WriteTablesGo generates the Go code for the range tables and bitmaps
+func WriteTablesGo(data *UnicodeData, outputPath string) error {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, "// Code generated by internal/gen/main.go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package displaywidth\n\n")
+	fmt.Fprintf(buf, "import \"unicode\"\n\n")
+
+	for _, def := range rangeTableDefinitions(data) {
+		writeRangeTable(buf, def)
+	}
+	for _, def := range bitmapDefinitions(data) {
+		writeBitmap(buf, def)
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outputPath, formatted, 0644)
+}
+
+// writeRangeTable writes def as a unicode.RangeTable variable, merging
+// consecutive code points into ranges of stride 1
+func writeRangeTable(buf *bytes.Buffer, def RangeTableDefinition) {
+	table := buildRangeTable(def.Runes)
+
+	for _, line := range bytes.Split([]byte(def.Comment), []byte("\n")) {
+		fmt.Fprintf(buf, "// %s\n", line)
+	}
+	fmt.Fprintf(buf, "var %s = &unicode.RangeTable{\n", def.Name)
+	if len(table.R16) > 0 {
+		fmt.Fprintf(buf, "R16: []unicode.Range16{\n")
+		for _, r := range table.R16 {
+			fmt.Fprintf(buf, "{Lo: 0x%04X, Hi: 0x%04X, Stride: 1},\n", r.Lo, r.Hi)
+		}
+		fmt.Fprintf(buf, "},\n")
+	}
+	if len(table.R32) > 0 {
+		fmt.Fprintf(buf, "R32: []unicode.Range32{\n")
+		for _, r := range table.R32 {
+			fmt.Fprintf(buf, "{Lo: 0x%04X, Hi: 0x%04X, Stride: 1},\n", r.Lo, r.Hi)
+		}
+		fmt.Fprintf(buf, "},\n")
+	}
+	if table.LatinOffset > 0 {
+		fmt.Fprintf(buf, "LatinOffset: %d,\n", table.LatinOffset)
+	}
+	fmt.Fprintf(buf, "}\n\n")
+}
+
+// writeBitmap writes def as an array of uint64, in which bit r%64 of word
+// (r-Lo)/64 is set for each code point r in the set
+func writeBitmap(buf *bytes.Buffer, def BitmapDefinition) {
+	words := buildBitmap(def.Runes, def.Lo, def.Words)
+
+	for _, line := range bytes.Split([]byte(def.Comment), []byte("\n")) {
+		fmt.Fprintf(buf, "// %s\n", line)
+	}
+	fmt.Fprintf(buf, "var %s = [%d]uint64{\n", def.Name, def.Words)
+	for i, w := range words {
+		fmt.Fprintf(buf, "0x%016X, // U+%04X\n", w, def.Lo+rune(i*64))
+	}
+	fmt.Fprintf(buf, "}\n\n")
+}
+
+// buildBitmap returns the bitmap of the code points in set from lo to
+// lo+64*n-1
+func buildBitmap(set map[rune]bool, lo rune, n int) []uint64 {
+	words := make([]uint64, n)
+	for r := range set {
+		if i := r - lo; i >= 0 && i < rune(64*n) {
+			words[i/64] |= 1 << (i % 64)
+		}
+	}
+	return words
+}
+
+// buildRangeTable returns a unicode.RangeTable of the code points in set,
+// with consecutive code points merged into ranges of stride 1
+func buildRangeTable(set map[rune]bool) *unicode.RangeTable {
+	runes := make([]rune, 0, len(set))
+	for r := range set {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	table := &unicode.RangeTable{}
+	for i := 0; i < len(runes); {
+		lo := runes[i]
+		hi := lo
+		for i++; i < len(runes) && runes[i] == hi+1; i++ {
+			hi = runes[i]
+		}
+
+		switch {
+		case hi <= 0xFFFF:
+			table.R16 = append(table.R16, unicode.Range16{Lo: uint16(lo), Hi: uint16(hi), Stride: 1})
+			if hi <= unicode.MaxLatin1 {
+				table.LatinOffset++
+			}
+		case lo > 0xFFFF:
+			table.R32 = append(table.R32, unicode.Range32{Lo: uint32(lo), Hi: uint32(hi), Stride: 1})
+		default:
+			// A range spanning U+FFFF is split between the tables
+			table.R16 = append(table.R16, unicode.Range16{Lo: uint16(lo), Hi: 0xFFFF, Stride: 1})
+			table.R32 = append(table.R32, unicode.Range32{Lo: 0x10000, Hi: uint32(hi), Stride: 1})
+		}
+	}
+	return table
+}
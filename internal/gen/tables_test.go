@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode"
+)
+
+func TestBuildRangeTable(t *testing.T) {
+	set := map[rune]bool{
+		0xA9: true, 0xAE: true,
+		0x2194: true, 0x2195: true, 0x2196: true,
+		0xFFFE: true, 0xFFFF: true, 0x10000: true,
+		0x1F004: true,
+	}
+
+	got := buildRangeTable(set)
+	expected := &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 0xA9, Hi: 0xA9, Stride: 1},
+			{Lo: 0xAE, Hi: 0xAE, Stride: 1},
+			{Lo: 0x2194, Hi: 0x2196, Stride: 1},
+			{Lo: 0xFFFE, Hi: 0xFFFF, Stride: 1},
+		},
+		R32: []unicode.Range32{
+			{Lo: 0x10000, Hi: 0x10000, Stride: 1},
+			{Lo: 0x1F004, Hi: 0x1F004, Stride: 1},
+		},
+		LatinOffset: 2,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("buildRangeTable() = %+v, want %+v", got, expected)
+	}
+
+	for r := rune(0); r <= 0x20000; r++ {
+		if unicode.Is(got, r) != set[r] {
+			t.Errorf("unicode.Is(table, %U) = %v, want %v", r, !set[r], set[r])
+		}
+	}
+}
+
+func TestBuildBitmap(t *testing.T) {
+	set := map[rune]bool{0x1F000: true, 0x1F03F: true, 0x1F040: true, 0x1F0BF: true, 0x1EFFF: true, 0x1F100: true}
+
+	got := buildBitmap(set, 0x1F000, 4)
+	expected := []uint64{1 | 1<<63, 1, 1 << 63, 0}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("buildBitmap() = %#x, want %#x", got, expected)
+	}
+}
+
+func TestTablesUpToDate(t *testing.T) {
+	// The range tables in the root package are generated from the vendored
+	// data, and must be regenerated when it changes
+	data, err := ParseUnicodeData(defaultDataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "emoji_tables.go")
+	if err := WriteTablesGo(data, path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join("..", "..", "emoji_tables.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Errorf("emoji_tables.go is out of date; run go generate")
+	}
+}
@@ -18,6 +18,7 @@ type UnicodeData struct {
 	EastAsianWidth       map[rune]string // From EastAsianWidth.txt
 	ExtendedPictographic map[rune]bool   // From emoji-data.txt (Extended_Pictographic property)
 	EmojiPresentation    map[rune]bool   // From emoji-data.txt (Emoji_Presentation property)
+	EmojiModifierBase    map[rune]bool   // From emoji-data.txt (Emoji_Modifier_Base property)
 	VS16Eligible         map[rune]bool   // From emoji-variation-sequences.txt (base chars with valid FE0F sequence)
 	RegionalIndicator    map[rune]bool   // From emoji-data.txt (Regional Indicator symbols, range 1F1E6..1F1FF)
 	ControlChars         map[rune]bool   // From Go stdlib
@@ -56,12 +57,21 @@ const (
 	vs16_Eligible
 )
 
-// ParseUnicodeData downloads and parses all required Unicode data files
-func ParseUnicodeData() (*UnicodeData, error) {
+// unicodeVersion is the version of the Unicode data files
+const unicodeVersion = "17.0.0"
+
+// defaultDataDir is the directory of the vendored Unicode data files
+var defaultDataDir = filepath.Join("data", unicodeVersion)
+
+// ParseUnicodeData parses all required Unicode data files from dataDir.
+// Files missing from dataDir are downloaded into it first, so that
+// regeneration works offline when the files are vendored.
+func ParseUnicodeData(dataDir string) (*UnicodeData, error) {
 	data := &UnicodeData{
 		EastAsianWidth:       make(map[rune]string),
 		ExtendedPictographic: make(map[rune]bool),
 		EmojiPresentation:    make(map[rune]bool),
+		EmojiModifierBase:    make(map[rune]bool),
 		VS16Eligible:         make(map[rune]bool),
 		RegionalIndicator:    make(map[rune]bool),
 		ControlChars:         make(map[rune]bool),
@@ -69,10 +79,7 @@ func ParseUnicodeData() (*UnicodeData, error) {
 		ZeroWidthChars:       make(map[rune]bool),
 	}
 
-	const unicodeVersion = "17.0.0"
-
 	// Create data directory
-	dataDir := filepath.Join("data", unicodeVersion)
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %v", err)
 	}
@@ -109,7 +116,8 @@ func ParseUnicodeData() (*UnicodeData, error) {
 	return data, nil
 }
 
-// downloadFile downloads a file from URL to local path
+// downloadFile downloads a file from URL to local path, unless the file
+// already exists, such as a vendored copy
 func downloadFile(url, filepath string) error {
 	// Check if file already exists
 	if _, err := os.Stat(filepath); err == nil {
@@ -199,7 +207,8 @@ func parseEastAsianWidth(filename string, data *UnicodeData) error {
 	return scanner.Err()
 }
 
-// parseEmojiData parses the emoji-data.txt file for Extended_Pictographic and Emoji_Presentation
+// parseEmojiData parses the emoji-data.txt file for Extended_Pictographic, Emoji_Presentation
+// and Emoji_Modifier_Base
 func parseEmojiData(filename string, data *UnicodeData) error {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -253,11 +262,6 @@ func parseEmojiData(filename string, data *UnicodeData) error {
 			r1, r2 = rune(codepoint), rune(codepoint)
 		}
 
-		// Skip characters below 0xFF (ASCII range is handled specially)
-		if r2 < 0xFF {
-			continue
-		}
-
 		// Check if this is a Regional Indicator character (range 1F1E6..1F1FF)
 		// Regional Indicator characters can appear with any property, but we identify them by range
 		const regionalIndicatorStart = 0x1F1E6
@@ -271,8 +275,8 @@ func parseEmojiData(filename string, data *UnicodeData) error {
 			continue
 		}
 
-		// We're only interested in Extended_Pictographic and Emoji_Presentation for non-Regional Indicator characters
-		if propertyStr != "Extended_Pictographic" && propertyStr != "Emoji_Presentation" {
+		// We're only interested in Extended_Pictographic, Emoji_Presentation and Emoji_Modifier_Base for non-Regional Indicator characters
+		if propertyStr != "Extended_Pictographic" && propertyStr != "Emoji_Presentation" && propertyStr != "Emoji_Modifier_Base" {
 			continue
 		}
 
@@ -283,6 +287,8 @@ func parseEmojiData(filename string, data *UnicodeData) error {
 				data.ExtendedPictographic[r] = true
 			case "Emoji_Presentation":
 				data.EmojiPresentation[r] = true
+			case "Emoji_Modifier_Base":
+				data.EmojiModifierBase[r] = true
 			}
 		}
 	}
@@ -75,3 +75,93 @@ func TestParseEmojiVariationSequencesErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestParseVendoredData(t *testing.T) {
+	// The vendored data files parse without downloading
+	for _, name := range []string{"EastAsianWidth.txt", "emoji-data.txt", "emoji-variation-sequences.txt"} {
+		if _, err := os.Stat(filepath.Join(defaultDataDir, name)); err != nil {
+			t.Fatalf("vendored %s is missing: %v", name, err)
+		}
+	}
+
+	data, err := ParseUnicodeData(defaultDataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eaw := []struct {
+		r        rune
+		expected string
+	}{
+		{'A', "Na"},
+		{0x4E00, "W"},
+		{0xFF21, "F"},
+		{0xFF76, "H"},
+		{0x00A7, "A"},
+		{0x268A, "W"}, // changed in Unicode 16
+		{0x2E3A, "N"}, // TWO-EM DASH is neutral, not wide
+		{0x2E3B, "N"}, // THREE-EM DASH
+	}
+	for _, tt := range eaw {
+		if got := data.EastAsianWidth[tt.r]; got != tt.expected {
+			t.Errorf("EastAsianWidth[%U] = %q, want %q", tt.r, got, tt.expected)
+		}
+	}
+
+	if !data.ExtendedPictographic[0x1F600] {
+		t.Errorf("ExtendedPictographic[U+1F600] = false, want true")
+	}
+	// Extended_Pictographic covers only some of the Mahjong, Domino and
+	// Playing Card blocks, and includes Latin-1 symbols
+	for _, r := range []rune{0x00A9, 0x1F004, 0x1F02C, 0x1F0CF} {
+		if !data.ExtendedPictographic[r] {
+			t.Errorf("ExtendedPictographic[%U] = false, want true", r)
+		}
+	}
+	for _, r := range []rune{0x1F000, 0x1F030, 0x1F0A1} {
+		if data.ExtendedPictographic[r] {
+			t.Errorf("ExtendedPictographic[%U] = true, want false", r)
+		}
+	}
+	if !data.EmojiPresentation[0x1F600] {
+		t.Errorf("EmojiPresentation[U+1F600] = false, want true")
+	}
+	if data.EmojiPresentation[0x263A] {
+		t.Errorf("EmojiPresentation[U+263A] = true, want false")
+	}
+	// ☝ and 👍 take skin tones; 😀 does not
+	for _, r := range []rune{0x261D, 0x1F44D} {
+		if !data.EmojiModifierBase[r] {
+			t.Errorf("EmojiModifierBase[%U] = false, want true", r)
+		}
+	}
+	if data.EmojiModifierBase[0x1F600] {
+		t.Errorf("EmojiModifierBase[U+1F600] = true, want false")
+	}
+	if !data.VS16Eligible[0x263A] {
+		t.Errorf("VS16Eligible[U+263A] = false, want true")
+	}
+}
+
+func TestParseUnicodeDataDir(t *testing.T) {
+	// Files are loaded from the given directory, with no download when
+	// they are present
+	dir := t.TempDir()
+	for _, name := range []string{"EastAsianWidth.txt", "emoji-data.txt", "emoji-variation-sequences.txt"} {
+		b, err := os.ReadFile(filepath.Join(defaultDataDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := ParseUnicodeData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := data.EastAsianWidth[0x4E00]; got != "W" {
+		t.Errorf("EastAsianWidth[U+4E00] = %q, want %q", got, "W")
+	}
+}
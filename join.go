@@ -0,0 +1,55 @@
+package displaywidth
+
+import "strings"
+
+// JoinTruncate joins items with sep, and truncates the result to maxWidth,
+// dropping whole items where possible. See [Options.JoinTruncate].
+func JoinTruncate(items []string, sep string, maxWidth int, tail string) string {
+	return DefaultOptionsValue().JoinTruncate(items, sep, maxWidth, tail)
+}
+
+// JoinTruncate joins items with sep, as strings.Join, and truncates the
+// result to maxWidth, for compact lists such as "a, b, c, +3 more".
+//
+// If the joined items are wider than maxWidth, it keeps as many leading
+// items as fit, followed by sep and tail, so that no item is cut. The widths
+// of sep and tail count against maxWidth. If not even the first item fits
+// that way, it falls back to truncating the joined items at a grapheme
+// cluster boundary, as [Options.TruncateString], with tail.
+func (options Options) JoinTruncate(items []string, sep string, maxWidth int, tail string) string {
+	joined := strings.Join(items, sep)
+	if options.String(joined) <= maxWidth {
+		return joined
+	}
+
+	// Keep leading items while they, and the separators between them, fit
+	kept := 0
+	if options.contextual() {
+		// Widths depend on context, such as the column for TabWidth, so
+		// each candidate is measured whole
+		for kept < len(items) && options.String(strings.Join(items[:kept+1], sep)+sep+tail) <= maxWidth {
+			kept++
+		}
+	} else {
+		sepWidth := options.String(sep)
+		budget := maxWidth - sepWidth - options.String(tail)
+
+		width := 0
+		for i, item := range items {
+			w := options.String(item)
+			if i > 0 {
+				w += sepWidth
+			}
+			if width+w > budget {
+				break
+			}
+			width += w
+			kept++
+		}
+	}
+
+	if kept == 0 {
+		return options.TruncateString(joined, maxWidth, tail)
+	}
+	return strings.Join(items[:kept], sep) + sep + tail
+}
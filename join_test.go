@@ -0,0 +1,52 @@
+package displaywidth
+
+import "testing"
+
+func TestJoinTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []string
+		sep      string
+		maxWidth int
+		tail     string
+		expected string
+	}{
+		{"empty", nil, ", ", 10, "…", ""},
+		{"fits", []string{"a", "b", "c"}, ", ", 7, "…", "a, b, c"},
+		{"drops whole items", []string{"apple", "banana", "cherry"}, ", ", 16, "…", "apple, banana, …"},
+		{"drops more items", []string{"apple", "banana", "cherry"}, ", ", 15, "…", "apple, …"},
+		{"more tail", []string{"a", "b", "c", "d", "e", "f"}, ", ", 14, "+3 more", "a, b, +3 more"},
+
+		// CJK items are measured, not counted
+		{"CJK fits", []string{"東京", "大阪"}, "、", 10, "…", "東京、大阪"},
+		{"CJK drops whole items", []string{"東京", "大阪", "名古屋"}, "、", 13, "…", "東京、大阪、…"},
+		{"CJK drops odd width", []string{"東京", "大阪", "名古屋"}, "、", 12, "…", "東京、…"},
+
+		// If no whole item fits, the first is cut at a grapheme boundary
+		{"cuts first item", []string{"internationalization", "b"}, ", ", 8, "…", "interna…"},
+		{"cuts CJK item", []string{"名古屋市", "東京"}, "、", 6, "…", "名古…"},
+		{"cuts CJK odd width", []string{"名古屋市", "東京"}, "、", 7, "…", "名古屋…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := defaultOptions.JoinTruncate(tt.items, tt.sep, tt.maxWidth, tt.tail)
+			if got != tt.expected {
+				t.Errorf("JoinTruncate(%q, %q, %d, %q) = %q, want %q", tt.items, tt.sep, tt.maxWidth, tt.tail, got, tt.expected)
+			}
+			if w := String(got); w > tt.maxWidth {
+				t.Errorf("JoinTruncate() = %q has width %d, want <= %d", got, w, tt.maxWidth)
+			}
+		})
+	}
+
+	// The tabs of an item depend on its column in the joined items
+	items := []string{"a", "abcdefg\tz", "b"}
+	if got := (Options{TabWidth: 8}).JoinTruncate(items, ", ", 15, "…"); got != "a, …" {
+		t.Errorf("JoinTruncate(%q) with TabWidth = %q, want %q", items, got, "a, …")
+	}
+
+	if got := JoinTruncate([]string{"a", "b", "c"}, ",", 4, "…"); got != "a,…" {
+		t.Errorf("JoinTruncate() = %q, want %q", got, "a,…")
+	}
+}
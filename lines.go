@@ -0,0 +1,111 @@
+package displaywidth
+
+import "strings"
+
+// FirstLineWidth returns the display width of the first line of s, and the
+// rest of s after the first newline. See [Options.FirstLineWidth].
+func FirstLineWidth(s string) (width int, rest string) {
+	return DefaultOptionsValue().FirstLineWidth(s)
+}
+
+// FirstLineWidth returns the display width of the first line of s, for the
+// given options, and the rest of s after the first newline, for single-line
+// labels that may contain one. It does not split the rest of s.
+//
+// The first line ends at the first "\n"; a "\r" immediately before it is
+// part of the line ending, as in "\r\n", and is not measured. If s has no
+// newline, width is the width of s, and rest is empty.
+func (options Options) FirstLineWidth(s string) (width int, rest string) {
+	s = untilNUL(s, options)
+	i := strings.IndexByte(s, '\n')
+	if i < 0 {
+		return options.String(s), ""
+	}
+
+	line := s[:i]
+	if strings.HasSuffix(line, "\r") {
+		line = line[:len(line)-1]
+	}
+	return options.String(line), s[i+1:]
+}
+
+// LineWidths returns the display width of each line of s. See
+// [Options.LineWidths].
+func LineWidths(s string) []int {
+	return DefaultOptionsValue().LineWidths(s)
+}
+
+// LineWidths returns the display width of each line of s, in order, with the
+// given options, for laying out a paragraph in a box.
+//
+// Lines are separated by "\n" or "\r\n", as in [Options.FirstLineWidth]. As
+// in [Options.NormalizeBlock], a trailing newline ends the last line, and
+// does not add an empty line of width 0, and an empty string has no lines,
+// and returns nil. Each line is measured on its own, so TabWidth columns
+// start at 0 on every line.
+func (options Options) LineWidths(s string) []int {
+	s = untilNUL(s, options)
+	var widths []int
+	for s != "" {
+		var w int
+		w, s = options.FirstLineWidth(s)
+		widths = append(widths, w)
+	}
+	return widths
+}
+
+// MaxLineWidth returns the display width of the widest line of s. See
+// [Options.MaxLineWidth].
+func MaxLineWidth(s string) int {
+	return DefaultOptionsValue().MaxLineWidth(s)
+}
+
+// MaxLineWidth returns the display width of the widest line of s, with the
+// given options, which is the maximum of [Options.LineWidths], or 0 if s is
+// empty. It does not allocate a slice of widths.
+func (options Options) MaxLineWidth(s string) int {
+	s = untilNUL(s, options)
+	max := 0
+	for s != "" {
+		var w int
+		w, s = options.FirstLineWidth(s)
+		if w > max {
+			max = w
+		}
+	}
+	return max
+}
+
+// TruncateLines truncates each line of s to maxWidth. See
+// [Options.TruncateLines].
+func TruncateLines(s string, maxWidth int, tail string) string {
+	return DefaultOptionsValue().TruncateLines(s, maxWidth, tail)
+}
+
+// TruncateLines truncates each line of s independently to the given
+// maxWidth, as [Options.TruncateString] does, appending tail to each line
+// that is truncated, so that a block of text fits a column.
+//
+// Lines are separated by "\n" or "\r\n". The number of lines and each line
+// ending are preserved, including a trailing newline.
+func (options Options) TruncateLines(s string, maxWidth int, tail string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for {
+		i := strings.IndexByte(s, '\n')
+		line, ending := s, ""
+		if i >= 0 {
+			line, ending = s[:i], "\n"
+		}
+		if strings.HasSuffix(line, "\r") {
+			line, ending = line[:len(line)-1], "\r"+ending
+		}
+
+		b.WriteString(options.TruncateString(line, maxWidth, tail))
+		b.WriteString(ending)
+		if i < 0 {
+			return b.String()
+		}
+		s = s[i+1:]
+	}
+}
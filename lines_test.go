@@ -0,0 +1,149 @@
+package displaywidth
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFirstLineWidth(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options Options
+		width   int
+		rest    string
+	}{
+		{"empty", "", defaultOptions, 0, ""},
+		{"no newline", "hello", defaultOptions, 5, ""},
+		{"no newline CJK", "中文", defaultOptions, 4, ""},
+		{"newline", "hello\nworld", defaultOptions, 5, "world"},
+		{"CRLF", "hello\r\nworld", defaultOptions, 5, "world"},
+		{"CRLF caret notation", "hello\r\nworld", Options{CaretNotation: true}, 5, "world"},
+		{"lone CR", "ab\rcd\nef", defaultOptions, 4, "ef"},
+		{"leading newline", "\nhello", defaultOptions, 0, "hello"},
+		{"trailing newline", "hello\n", defaultOptions, 5, ""},
+		{"several lines", "a\nbb\nccc", defaultOptions, 1, "bb\nccc"},
+		{"CJK", "中文\n字符", defaultOptions, 4, "字符"},
+		{"emoji", "👋🏽 hi\r\nthere", defaultOptions, 5, "there"},
+		{"escape sequences", "\x1b[31mab\x1b[0m\ncd", controlSequences, 2, "cd"},
+		{"tabs", "a\tb\n\tc", Options{TabWidth: 4}, 5, "\tc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, rest := tt.options.FirstLineWidth(tt.input)
+			if width != tt.width || rest != tt.rest {
+				t.Errorf("FirstLineWidth(%q) = (%d, %q), want (%d, %q)", tt.input, width, rest, tt.width, tt.rest)
+			}
+		})
+	}
+
+	if width, rest := FirstLineWidth("ab\ncd"); width != 2 || rest != "cd" {
+		t.Errorf("FirstLineWidth() = (%d, %q), want (2, %q)", width, rest, "cd")
+	}
+}
+
+func TestLineWidths(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  Options
+		expected []int
+	}{
+		{"empty", "", defaultOptions, nil},
+		{"single line", "hello", defaultOptions, []int{5}},
+		{"two lines", "hello\nworld!", defaultOptions, []int{5, 6}},
+		{"CRLF", "hello\r\nworld!\r\n", defaultOptions, []int{5, 6}},
+		{"trailing newline", "hello\n", defaultOptions, []int{5}},
+		{"trailing empty line", "hello\n\n", defaultOptions, []int{5, 0}},
+		{"only newline", "\n", defaultOptions, []int{0}},
+		{"empty lines", "a\n\n\nb", defaultOptions, []int{1, 0, 0, 1}},
+		{"leading newline", "\nab", defaultOptions, []int{0, 2}},
+		{"CJK", "中文\n字符测试\nabc", defaultOptions, []int{4, 8, 3}},
+		{"emoji", "👋🏽\nhi", defaultOptions, []int{2, 2}},
+		{"ambiguous EAW", "★\n★★", eawOptions, []int{2, 4}},
+		{"escape sequences", "\x1b[31mab\x1b[0m\ncd", controlSequences, []int{2, 2}},
+		{"tabs restart each line", "abc\tx\n\tx", Options{TabWidth: 4}, []int{5, 5}},
+		{"lone CR", "ab\rcd\ne", defaultOptions, []int{4, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.LineWidths(tt.input)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("LineWidths(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+
+			// MaxLineWidth is the maximum of LineWidths
+			max := 0
+			for _, w := range got {
+				if w > max {
+					max = w
+				}
+			}
+			if m := tt.options.MaxLineWidth(tt.input); m != max {
+				t.Errorf("MaxLineWidth(%q) = %d, want %d", tt.input, m, max)
+			}
+
+			// One width per line of NormalizeBlock
+			if n := len(tt.options.NormalizeBlock(tt.input, AlignLeft)); n != len(got) {
+				t.Errorf("LineWidths(%q) has %d lines, NormalizeBlock has %d", tt.input, len(got), n)
+			}
+		})
+	}
+
+	if got := LineWidths("a\n中"); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("LineWidths() = %v, want [1 2]", got)
+	}
+	if got := MaxLineWidth("a\n中"); got != 2 {
+		t.Errorf("MaxLineWidth() = %d, want 2", got)
+	}
+}
+
+func TestTruncateLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxWidth int
+		options  Options
+		expected string
+	}{
+		{"empty", "", 5, defaultOptions, ""},
+		{"single line fits", "hello", 5, defaultOptions, "hello"},
+		{"single line", "hello world", 8, defaultOptions, "hello w…"},
+		{"each line", "hello world\nshort\ngoodbye world", 8, defaultOptions, "hello w…\nshort\ngoodbye…"},
+		{"CRLF", "hello world\r\nshort\r\n", 8, defaultOptions, "hello w…\r\nshort\r\n"},
+		{"mixed endings", "hello world\r\nhello world\n", 8, defaultOptions, "hello w…\r\nhello w…\n"},
+		{"trailing newline", "hello world\n", 8, defaultOptions, "hello w…\n"},
+		{"empty lines", "\n\nhello world\n\n", 8, defaultOptions, "\n\nhello w…\n\n"},
+		{"only newlines", "\n\r\n", 3, defaultOptions, "\n\r\n"},
+		{"lone CR stays in line", "ab\rcd\nx", 3, defaultOptions, "ab\r…\nx"},
+		{"CJK", "中文字符\nab\n中a中", 5, defaultOptions, "中文…\nab\n中a中"},
+		{"CJK odd", "中文字符测试\n中文", 4, defaultOptions, "中…\n中文"},
+		{"combining", "e\u0301e\u0301e\u0301e\u0301\ne\u0301", 3, defaultOptions, "e\u0301e\u0301…\ne\u0301"},
+		{"emoji", "👋🏽👋🏽👋🏽\n🇯🇵", 5, defaultOptions, "👋🏽👋🏽…\n🇯🇵"},
+		{"escape sequences", "\x1b[31mhello world\x1b[0m\nok", 8, controlSequences, "\x1b[31mhello w…\x1b[0m\nok"},
+		{"ambiguous EAW", "★★★★\n★", 5, eawOptions, "★…\n★"}, // the tail is ambiguous too,
+		{"tabs", "a\tbc\nab\tc", 5, Options{TabWidth: 4}, "a\t…\nab\tc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.TruncateLines(tt.input, tt.maxWidth, "…")
+			if got != tt.expected {
+				t.Errorf("TruncateLines(%q, %d) = %q, want %q", tt.input, tt.maxWidth, got, tt.expected)
+			}
+			if n, want := strings.Count(got, "\n"), strings.Count(tt.input, "\n"); n != want {
+				t.Errorf("TruncateLines(%q, %d) has %d newlines, want %d", tt.input, tt.maxWidth, n, want)
+			}
+			if m := tt.options.MaxLineWidth(got); m > tt.maxWidth {
+				t.Errorf("TruncateLines(%q, %d) has a line of width %d", tt.input, tt.maxWidth, m)
+			}
+		})
+	}
+
+	if got := TruncateLines("hello world\nhi", 6, "..."); got != "hel...\nhi" {
+		t.Errorf("TruncateLines() = %q, want %q", got, "hel...\nhi")
+	}
+}
@@ -0,0 +1,40 @@
+package displaywidth
+
+import "testing"
+
+// BenchmarkGraphemeWidth isolates the per-cluster lookup on inputs that
+// exercise the VS15, VS16, regional indicator and emoji modifier
+// comparisons.
+func BenchmarkGraphemeWidth(b *testing.B) {
+	clusters := []struct {
+		name    string
+		cluster string
+		options Options
+	}{
+		{"emoji", "😀", defaultOptions},
+		{"flag", "🇺🇸", defaultOptions},
+		{"flag split", "🇺🇸", Options{SplitFlags: true}},
+		{"VS16", "❤️", defaultOptions},
+		{"VS15", "⌛︎", Options{HonorVS15: true}},
+		{"keycap", "1️⃣", defaultOptions},
+		{"modifier", "👋🏽", defaultOptions},
+		{"CJK", "中", defaultOptions},
+	}
+
+	for _, c := range clusters {
+		s := c.cluster
+		bs := []byte(c.cluster)
+		b.Run(c.name+"/string", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = graphemeWidth(s, c.options)
+			}
+		})
+		b.Run(c.name+"/bytes", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = graphemeWidth(bs, c.options)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package displaywidth
+
+// Measurer measures the display width of strings, remembering the most
+// recent string and its width, so that measuring the same string again, as a
+// render loop does for layout and then drawing, returns immediately.
+//
+// The zero value is ready to use, with the zero Options. A Measurer is not
+// safe for concurrent use; use one per goroutine.
+type Measurer struct {
+	// Options are the options used for measuring. They may be changed
+	// between calls, which invalidates the remembered width. Options that
+	// are pointers, such as EmojiSet, are compared by identity, so a change
+	// to the value they point to is not detected.
+	Options Options
+
+	// cached are the options with which width was measured
+	cached Options
+	last   string
+	width  int
+	ok     bool
+}
+
+// String returns the display width of s, as [Options.String] with m.Options.
+// If s and m.Options are the same as in the previous call, it returns the
+// remembered width without measuring.
+func (m *Measurer) String(s string) int {
+	if m.ok && s == m.last && m.Options == m.cached {
+		return m.width
+	}
+
+	m.width = m.Options.String(s)
+	m.last = s
+	m.cached = m.Options
+	m.ok = true
+	return m.width
+}
+
+// Reset forgets the remembered string, releasing it for garbage collection.
+func (m *Measurer) Reset() {
+	m.last = ""
+	m.width = 0
+	m.ok = false
+}
@@ -0,0 +1,71 @@
+package displaywidth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMeasurer(t *testing.T) {
+	var m Measurer
+
+	tests := []struct {
+		input    string
+		options  Options
+		expected int
+	}{
+		{"hello", defaultOptions, 5},
+		{"hello", defaultOptions, 5}, // remembered
+		{"中文", defaultOptions, 4},
+		{"", defaultOptions, 0},
+		{"★", defaultOptions, 1},
+		{"★", eawOptions, 2}, // options changed
+		{"★", defaultOptions, 1},
+		{"\x1b[31mab\x1b[0m", defaultOptions, 9},
+		{"\x1b[31mab\x1b[0m", controlSequences, 2},
+	}
+
+	for _, tt := range tests {
+		m.Options = tt.options
+		if got := m.String(tt.input); got != tt.expected {
+			t.Errorf("String(%q) with %+v = %d, want %d", tt.input, tt.options, got, tt.expected)
+		}
+	}
+
+	// An equal string at a different address is remembered too
+	a := strings.Repeat("ab", 3)
+	b := strings.Repeat("ab", 3)
+	m.Options = defaultOptions
+	if m.String(a) != 6 || m.String(b) != 6 {
+		t.Errorf("String(%q) != 6", a)
+	}
+
+	m.Reset()
+	if m.ok || m.last != "" {
+		t.Errorf("Reset() did not forget the remembered string")
+	}
+	if got := m.String("abc"); got != 3 {
+		t.Errorf("String(%q) after Reset = %d, want 3", "abc", got)
+	}
+}
+
+func BenchmarkMeasurer(b *testing.B) {
+	s := "Hello, 世界! 👋🏽 " + strings.Repeat("text ", 10)
+
+	// The repeat-measure pattern of a render loop: layout, then draw
+	b.Run("Options", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = defaultOptions.String(s)
+			_ = defaultOptions.String(s)
+		}
+	})
+
+	b.Run("Measurer", func(b *testing.B) {
+		var m Measurer
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = m.String(s)
+			_ = m.String(s)
+		}
+	})
+}
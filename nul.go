@@ -0,0 +1,54 @@
+package displaywidth
+
+import (
+	"bytes"
+	"strings"
+)
+
+// WidthUntilNUL calculates the display width of s up to its first NUL byte,
+// as a renderer of C strings would stop there, and returns the offset of
+// the NUL, or -1 if there is none. See [Options.WidthUntilNUL].
+func WidthUntilNUL(s string) (width int, nulAt int) {
+	return DefaultOptionsValue().WidthUntilNUL(s)
+}
+
+// WidthUntilNUL calculates the display width of s up to its first NUL byte,
+// for the given options, and returns the offset of the NUL, or -1 if there
+// is none.
+//
+// [Options.String] measures NUL as width 0 and continues past it;
+// WidthUntilNUL instead models C string semantics, where a NUL terminates
+// the string, without the caller trimming it first. The text before the NUL
+// is measured on its own, so an escape sequence or grapheme cluster that the
+// NUL interrupts is measured as it stands.
+func (options Options) WidthUntilNUL(s string) (width int, nulAt int) {
+	nulAt = strings.IndexByte(s, 0)
+	if nulAt < 0 {
+		return options.String(s), -1
+	}
+	return options.String(s[:nulAt]), nulAt
+}
+
+// untilNUL returns s up to its first NUL byte when the StopAtNUL option is
+// true, and all of s otherwise. Each function that measures s calls it
+// first. See [Options.StopAtNUL].
+func untilNUL[T ~string | ~[]byte](s T, options Options) T {
+	if !options.StopAtNUL {
+		return s
+	}
+
+	var i int
+	switch v := any(s).(type) {
+	case string:
+		i = strings.IndexByte(v, 0)
+	case []byte:
+		i = bytes.IndexByte(v, 0)
+	default:
+		// Handles named types
+		i = strings.IndexByte(string(s), 0)
+	}
+	if i < 0 {
+		return s
+	}
+	return s[:i]
+}
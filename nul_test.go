@@ -0,0 +1,168 @@
+package displaywidth
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWidthUntilNUL(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		options Options
+		width   int
+		nulAt   int
+	}{
+		{"empty", "", defaultOptions, 0, -1},
+		{"no NUL", "hello", defaultOptions, 5, -1},
+		{"NUL only", "\x00", defaultOptions, 0, 0},
+		{"leading NUL", "\x00hello", defaultOptions, 0, 0},
+		{"trailing NUL", "hello\x00", defaultOptions, 5, 5},
+		{"middle NUL", "hello\x00world", defaultOptions, 5, 5},
+		{"several NULs", "ab\x00cd\x00ef", defaultOptions, 2, 2},
+		{"CJK", "中文\x00字符", defaultOptions, 4, 6},
+		{"emoji", "😀\x00😀", defaultOptions, 2, 4},
+		{"ZWJ sequence interrupted", "👩\u200D\x00💻", defaultOptions, 2, 7},
+		{"combining mark after NUL", "e\x00\u0301", defaultOptions, 1, 1},
+		{"escape sequence", "\x1b[31mab\x00cd\x1b[0m", controlSequences, 2, 7},
+		{"tabs", "\tab\x00\tcd", Options{TabWidth: 8}, 10, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, nulAt := tt.options.WidthUntilNUL(tt.input)
+			if width != tt.width || nulAt != tt.nulAt {
+				t.Errorf("WidthUntilNUL(%q) = (%d, %d), want (%d, %d)", tt.input, width, nulAt, tt.width, tt.nulAt)
+			}
+		})
+	}
+
+	// Unlike String, which continues past NUL
+	if got := String("hello\x00world"); got != 10 {
+		t.Errorf("String() = %d, want 10", got)
+	}
+	if width, nulAt := WidthUntilNUL("hello\x00world"); width != 5 || nulAt != 5 {
+		t.Errorf("WidthUntilNUL() = (%d, %d), want (5, 5)", width, nulAt)
+	}
+}
+
+func TestStopAtNUL(t *testing.T) {
+	stop := Options{StopAtNUL: true}
+
+	tests := []struct {
+		name     string
+		input    string
+		options  Options
+		expected int
+	}{
+		{"no NUL", "hello", stop, 5},
+		{"middle NUL", "hello\x00world", stop, 5},
+		{"middle NUL continues", "hello\x00world", defaultOptions, 10},
+		{"leading NUL", "\x00hello", stop, 0},
+		{"leading NUL continues", "\x00hello", defaultOptions, 5},
+		{"trailing NUL", "hello\x00", stop, 5},
+		{"several NULs", "ab\x00cd\x00ef", stop, 2},
+		{"several NULs continue", "ab\x00cd\x00ef", defaultOptions, 6},
+		{"CJK", "中文\x00字符", stop, 4},
+		{"CJK continues", "中文\x00字符", defaultOptions, 8},
+		{"combining mark after NUL", "e\x00\u0301", stop, 1},
+		{"escape sequence", "\x1b[31mab\x00cd\x1b[0m", Options{StopAtNUL: true, ControlSequences: true}, 2},
+		{"tabs", "\tab\x00\tcd", Options{StopAtNUL: true, TabWidth: 8}, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.String(tt.input); got != tt.expected {
+				t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := tt.options.Bytes([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Bytes(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if tt.options.StopAtNUL {
+				if width, _ := tt.options.WidthUntilNUL(tt.input); width != tt.expected {
+					t.Errorf("WidthUntilNUL(%q) = %d, want %d", tt.input, width, tt.expected)
+				}
+			}
+		})
+	}
+
+	// A lone NUL is width 0 either way
+	if got := stop.Rune(0); got != 0 {
+		t.Errorf("Rune(0) = %d, want 0", got)
+	}
+}
+
+// TestStopAtNULEntryPoints verifies that each function that measures s stops
+// at the first NUL, as String does.
+func TestStopAtNULEntryPoints(t *testing.T) {
+	inputs := []string{
+		"ab\x00cdefgh中",
+		"中±\x00中\n中",
+		"\x00\U0001F600",
+		"\U0001F1FA\x00\U0001F1F8",
+		"a\tb\x00\tc",
+	}
+	optionSets := []Options{
+		{StopAtNUL: true},
+		{StopAtNUL: true, ContextualAmbiguous: true},
+		{StopAtNUL: true, TabWidth: 4},
+		{StopAtNUL: true, AmbiguousScaledWidth: 1},
+	}
+
+	for _, options := range optionSets {
+		for _, s := range inputs {
+			nul := strings.IndexByte(s, 0)
+			prefix := s[:nul]
+			want := options.String(s)
+			if want != options.String(prefix) {
+				t.Fatalf("String(%q) = %d, does not stop at NUL", s, want)
+			}
+
+			if got, _ := options.WidthAndEmoji(s); got != want {
+				t.Errorf("WidthAndEmoji(%q) = %d, want %d", s, got, want)
+			}
+			if options.WiderThan(s, want) || !options.WiderThan(s, want-1) {
+				t.Errorf("WiderThan(%q) disagrees with width %d", s, want)
+			}
+			if got := options.Overflow(s, 0); got != want {
+				t.Errorf("Overflow(%q, 0) = %d, want %d", s, got, want)
+			}
+			for i := 0; i <= len(s); i++ {
+				if got := options.BytesPair([]byte(s[:i]), []byte(s[i:])); got != want {
+					t.Errorf("BytesPair(%q, %q) = %d, want %d", s[:i], s[i:], got, want)
+				}
+			}
+			if got, _ := options.CheckPrintable(s); got != want {
+				t.Errorf("CheckPrintable(%q) = %d, want %d", s, got, want)
+			}
+			if got, err := options.WidthChecked(s); got != want || err != nil {
+				t.Errorf("WidthChecked(%q) = (%d, %v), want (%d, nil)", s, got, err, want)
+			}
+			if got, expected := options.ExplainString(s), options.ExplainString(prefix); !reflect.DeepEqual(got, expected) {
+				t.Errorf("ExplainString(%q) = %v, want %v", s, got, expected)
+			}
+			gotWidth, consumed := options.BytesUntil([]byte(s), ',', -1)
+			if width, _ := options.BytesUntil([]byte(prefix), ',', -1); gotWidth != width || consumed != nul {
+				t.Errorf("BytesUntil(%q) = (%d, %d), want (%d, %d)", s, gotWidth, consumed, width, nul)
+			}
+
+			if got, expected := options.EndColumn(0, s), options.EndColumn(0, prefix); got != expected {
+				t.Errorf("EndColumn(0, %q) = %d, want %d", s, got, expected)
+			}
+			if got, expected := options.ScaledWidth(s, 2), options.ScaledWidth(prefix, 2); got != expected {
+				t.Errorf("ScaledWidth(%q, 2) = %d, want %d", s, got, expected)
+			}
+			if got, expected := options.LineWidths(s), options.LineWidths(prefix); !reflect.DeepEqual(got, expected) {
+				t.Errorf("LineWidths(%q) = %v, want %v", s, got, expected)
+			}
+			if got, expected := options.MaxLineWidth(s), options.MaxLineWidth(prefix); got != expected {
+				t.Errorf("MaxLineWidth(%q) = %d, want %d", s, got, expected)
+			}
+			gotWidth, gotPending := options.StringPending(s)
+			if width, pending := options.StringPending(prefix); gotWidth != width || gotPending != pending {
+				t.Errorf("StringPending(%q) = (%d, %d), want (%d, %d)", s, gotWidth, gotPending, width, pending)
+			}
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package displaywidth
 
+import (
+	"sync/atomic"
+	"unicode"
+)
+
 // Options allows you to specify the treatment of ambiguous East Asian
 // characters and ANSI escape sequences.
 type Options struct {
@@ -8,6 +13,23 @@ type Options struct {
 	// are treated as width 1. When true, they are width 2.
 	EastAsianWidth bool
 
+	// NarrowAmbiguous lists East Asian Ambiguous code points that remain
+	// width 1 when EastAsianWidth is true, for locales that treat some
+	// ambiguous characters, such as Greek letters in Western mathematics, as
+	// narrow. When nil (default), all ambiguous characters follow
+	// EastAsianWidth. It also excludes the listed code points from
+	// ContextualAmbiguous and AmbiguousScaledWidth. Code points that are not
+	// ambiguous are unaffected.
+	NarrowAmbiguous *unicode.RangeTable
+
+	// CombiningOverride lists code points to treat as zero width, such as
+	// combining marks added to Unicode after this package's tables were
+	// generated. A grapheme cluster that begins with a listed code point has
+	// width 0; a listed code point following a base character adds nothing to
+	// the cluster's width. ASCII is unaffected. When nil (default), the
+	// built-in tables apply.
+	CombiningOverride *unicode.RangeTable
+
 	// ControlSequences specifies whether to ignore 7-bit ECMA-48 escape sequences
 	// when calculating the display width. When false (default), ANSI escape
 	// sequences are treated as just a series of characters. When true, they are
@@ -17,14 +39,310 @@ type Options struct {
 	// when calculating the display width. When false (default), these are treated
 	// as just a series of characters. When true, they are treated as a single
 	// zero-width unit.
+	//
+	// Control strings opened by a C1 introducer (DCS, SOS, OSC, PM, APC) extend
+	// through the 8-bit ST (0x9C), including their payload. Since 0x9C is also
+	// a UTF-8 continuation byte, a payload character whose encoding contains
+	// it ends the string early.
 	ControlSequences8Bit bool
+	// OSCFilter classifies OSC (operating system command) sequences, such
+	// as OSC 0 to set the window title or OSC 8 for hyperlinks, when
+	// ControlSequences or ControlSequences8Bit is true. When nil (default),
+	// every OSC is zero width, like other escape sequences. When set, an OSC
+	// is zero width only if OSCFilter.ZeroWidth returns true for it, and is
+	// otherwise measured as the printable text it would be without
+	// ControlSequences. Other escape sequences, such as SGR, are unaffected.
+	OSCFilter *OSCFilter
+
+	// SplitFlags specifies whether to measure the regional indicators of a
+	// flag individually. When false (default), a pair of regional indicators
+	// forms a flag of width 2. When true, each regional indicator in the pair
+	// is measured on its own, as width 2, so a flag is width 4. This models
+	// terminals that lack flag support and draw each indicator as a separate
+	// letter glyph.
+	SplitFlags bool
+
+	// AmbiguousScaledWidth is the width, in units of 1/scale cells, of East
+	// Asian Ambiguous grapheme clusters as measured by [Options.ScaledWidth].
+	// When zero (default), they are measured like any other cluster, as their
+	// width times scale. It has no effect on other methods.
+	AmbiguousScaledWidth int
+
+	// WideScaledWidth is the width, in units of 1/scale cells, of East Asian
+	// Wide and Fullwidth grapheme clusters, such as CJK, as measured by
+	// [Options.ScaledWidth]. Emoji are excluded; see EmojiScaledWidth. When
+	// zero (default), they are measured as their width times scale. It has no
+	// effect on other methods.
+	WideScaledWidth int
+
+	// EmojiScaledWidth is the width, in units of 1/scale cells, of emoji
+	// grapheme clusters, including flags and emoji via VS16, as measured by
+	// [Options.ScaledWidth]. When zero (default), they are measured as their
+	// width times scale. It has no effect on other methods.
+	EmojiScaledWidth int
+
+	// TabWidth specifies the distance between tab stops, for expanding
+	// horizontal tabs. When zero (default), a tab is width 0, as with other
+	// control characters. When positive, a tab advances to the next tab stop,
+	// i.e. the next multiple of TabWidth columns, counted from the start of
+	// the string or the most recent newline or carriage return, or vertical
+	// tab or form feed with VerticalControls.
+	//
+	// Since the width of a tab depends on its column, TabWidth is honored by
+	// String and Bytes, which track the column, by truncation, which
+	// measures the kept text as String would, and by WrapOffsets and
+	// FitsInBox, which measure each line as String would. Rune and the
+	// Graphemes iterators have no context, and measure a tab as width 0.
+	TabWidth int
+
+	// VerticalControls specifies whether vertical tab (U+000B) and form
+	// feed (U+000C) reset the column, like a newline, for expanding tabs
+	// with TabWidth and for EndColumn, modeling renderers that advance to a
+	// new line or page. When false (default), they are zero-width controls
+	// that do not affect the column. Either way, they are width 0.
+	VerticalControls bool
+
+	// DECGraphics specifies whether to treat the SCS (select character set)
+	// escape sequences of VT100-style terminals, such as ESC ( 0 to enter DEC
+	// Special Graphics and ESC ( B to return to ASCII, as zero width, even
+	// when ControlSequences is false. When false (default), they are treated
+	// like any other escape sequence. Letters within DEC Special Graphics are
+	// drawn as line-drawing glyphs, which are width 1, so their width is
+	// unchanged.
+	//
+	// Like TabWidth, DECGraphics is honored by String and Bytes.
+	DECGraphics bool
+
+	// CaretNotation specifies whether C0 control characters (0x00-0x1F) and
+	// DEL (0x7F) are displayed in caret notation, such as ^A for 0x01 and ^?
+	// for DEL, as by cat -v. When false (default), they are width 0. When
+	// true, each is width 2, for the caret and the letter. Unlike cat -v,
+	// tabs and newlines are included, so CRLF is width 4.
+	//
+	// Use [Options.RenderCaret] to rewrite control characters into caret
+	// notation.
+	CaretNotation bool
+
+	// MaxCombiningPerCluster specifies the number of combining marks a
+	// grapheme cluster can stack before it takes more width, modeling
+	// terminals that cannot stack marks indefinitely, as in "Zalgo" text.
+	// When zero (default), marks are unlimited and zero width. When
+	// positive, a cluster with more than MaxCombiningPerCluster marks gets 1
+	// more column for each further group of up to MaxCombiningPerCluster
+	// marks.
+	//
+	// Combining marks are those in the general categories Mn
+	// (Nonspacing_Mark) and Me (Enclosing_Mark), excluding variation
+	// selectors. ZWJ and emoji tags are not marks.
+	MaxCombiningPerCluster int
+
+	// MaxClusterBytes caps the number of bytes of a grapheme cluster that
+	// are measured, protecting renderers from adversarial input such as
+	// "Zalgo" text or long ZWJ chains. When zero (default), clusters are
+	// unlimited. When positive, a longer cluster is measured by its first
+	// MaxClusterBytes bytes, and each further MaxClusterBytes bytes, or
+	// part thereof, count as another cluster of the same width, as if the
+	// cluster were broken into pieces.
+	MaxClusterBytes int
+
+	// WideEnclosingMarks specifies whether a grapheme cluster containing an
+	// enclosing mark (general category Me), such as U+20DD COMBINING
+	// ENCLOSING CIRCLE, is one column wider than its base, modeling
+	// terminals that draw the enclosure around the character. When false
+	// (default), enclosing marks are zero width, like other combining marks,
+	// so the cluster is as wide as its base.
+	//
+	// An emoji keycap, with VS16 before U+20E3 COMBINING ENCLOSING KEYCAP,
+	// is drawn as an emoji, and is unaffected.
+	WideEnclosingMarks bool
+
+	// EmojiFallback specifies whether an emoji ZWJ sequence is measured as
+	// its components, as rendered by terminals whose fonts lack a glyph for
+	// the sequence. When false (default), a ZWJ sequence such as the family
+	// U+1F468 U+200D U+1F469 U+200D U+1F467 is a single emoji of width 2.
+	// When true, it is the sum of the widths of its components, separated
+	// by ZWJ (U+200D), so the family is width 6. Modifiers and variation
+	// selectors belong to their component.
+	EmojiFallback bool
+
+	// VisibleJoiners specifies whether ZERO WIDTH JOINER (U+200D) and ZERO
+	// WIDTH NON-JOINER (U+200C) are width 1, for debugging and visualization
+	// tools that render them as visible markers. When false (default), they
+	// are zero width, and emoji ZWJ sequences compose as usual. When true, a
+	// grapheme cluster containing joiners is measured as its parts between
+	// the joiners, each joiner adding 1, so the family U+1F468 U+200D
+	// U+1F469 U+200D U+1F467 is width 8. It does not reflect how terminals
+	// render text.
+	VisibleJoiners bool
+
+	// UnknownWidth specifies the width of code points in the unassigned
+	// planes 4 through 13 (U+40000-U+DFFFF), for forward compatibility with
+	// future Unicode versions. When zero (default), they are width 1, like
+	// other code points without width properties. Typical values are 1 or 2.
+	UnknownWidth int
+
+	// EmojiSet restricts which emoji are wide, such as to the emoji supported
+	// by a known font. When nil (default), all emoji are treated as usual.
+	// When set, an emoji grapheme cluster is width 2 only if its base code
+	// point is in EmojiSet; otherwise it is width 1, modeling a font that
+	// renders unknown emoji narrow.
+	//
+	// An emoji is a grapheme cluster whose base code point is
+	// Extended_Pictographic or a regional indicator, and which would
+	// otherwise be width 2 by virtue of default emoji presentation or VS16.
+	// Other wide characters, such as CJK, are unaffected, as are ambiguous
+	// characters made wide by EastAsianWidth.
+	EmojiSet *unicode.RangeTable
+
+	// EmojiWidth specifies the width of emoji grapheme clusters, for
+	// text-mode terminals that render every glyph in one cell. When zero
+	// (default), emoji are width 2. Typically 1.
+	//
+	// It applies to the emoji described by EmojiSet, including flags, ZWJ
+	// and modifier sequences, and to any character promoted to emoji
+	// presentation by VS16, such as keycaps. With SplitFlags, each regional
+	// indicator of a flag is EmojiWidth. Emoji outside of EmojiSet remain
+	// width 1.
+	EmojiWidth int
+
+	// HonorVS15 specifies whether VS15 (U+FE0E), which requests text
+	// presentation, narrows an emoji that has default emoji presentation.
+	// When false (default), VS15 is a no-op for width, per Unicode TR51, so
+	// an emoji such as U+231B followed by VS15 remains width 2. When true, an
+	// Extended_Pictographic base of width 2 followed directly by VS15 is
+	// width 1, modeling terminals that honor VS15. Other wide characters,
+	// such as CJK, are unaffected.
+	//
+	// This is the inverse of VS16 (U+FE0F), which always widens a text
+	// presentation base to width 2.
+	HonorVS15 bool
+
+	// PrivateUseWidth specifies the width of private use code points
+	// (U+E000-U+F8FF, U+F0000-U+FFFFD and U+100000-U+10FFFD), which icon
+	// fonts such as Nerd Fonts often fill with wide glyphs. When zero
+	// (default), they are measured as usual: EastAsianWidth.txt marks them
+	// as East Asian Ambiguous, so they are width 1, or 2 when EastAsianWidth
+	// is true. When positive, they are PrivateUseWidth, regardless of
+	// EastAsianWidth.
+	PrivateUseWidth int
+
+	// HalfwidthAsWide specifies whether East Asian Halfwidth (H) characters,
+	// such as halfwidth katakana (U+FF61-U+FF9F), are width 2. When false
+	// (default), they are width 1, per UAX #11. When true, they are width 2,
+	// modeling legacy systems that render them in a full cell.
+	HalfwidthAsWide bool
+
+	// NeutralAsWide specifies whether non-ASCII characters that are East
+	// Asian Neutral (N), such as Latin-1 letters, Cyrillic and most symbols,
+	// are width 2. When false (default), they are width 1, per UAX #11. When
+	// true, they are width 2, modeling very old CJK terminals that rendered
+	// all such characters in a full cell. ASCII, East Asian Narrow (Na) and
+	// Halfwidth (H) characters remain width 1; ambiguous characters follow
+	// EastAsianWidth; zero-width characters remain width 0.
+	NeutralAsWide bool
+
+	// ContextualAmbiguous specifies whether East Asian Ambiguous characters
+	// take their width from context, per UAX #11. When false (default), their
+	// width is determined by EastAsianWidth alone. When true, an ambiguous
+	// grapheme cluster that follows a wide one, such as a CJK character, is
+	// width 2, even when EastAsianWidth is false. Zero-width clusters, such
+	// as combining marks and escape sequences, do not change the context;
+	// newlines reset it.
+	//
+	// Like TabWidth, ContextualAmbiguous is honored by String and Bytes, by
+	// truncation and by wrapping. A truncation tail is measured where it is
+	// placed, so an ambiguous tail such as "…" after a wide character is
+	// width 2.
+	ContextualAmbiguous bool
+
+	// AmbiguousInCJKContext specifies whether East Asian Ambiguous characters
+	// take their width from their neighbors, as some editors do. When true,
+	// an ambiguous grapheme cluster is width 2 when the visible cluster
+	// before or after it is East Asian Wide or Fullwidth, such as a CJK
+	// character, and width 1 otherwise, regardless of EastAsianWidth. Emoji
+	// are not CJK context. Zero-width clusters do not separate neighbors;
+	// newlines do. It takes precedence over ContextualAmbiguous.
+	//
+	// Like TabWidth, AmbiguousInCJKContext is honored by String and Bytes, by
+	// truncation and by wrapping. Truncation measures the kept text as String
+	// would, so an ambiguous cluster before the cut is narrow if its CJK
+	// neighbor is dropped, and the tail is measured where it is placed.
+	AmbiguousInCJKContext bool
+
+	// TruncateKeepFirst specifies whether truncation always keeps the first
+	// visible grapheme cluster of a non-empty string. When false (default),
+	// truncation ensures the result fits within maxWidth, so a maxWidth
+	// smaller than the tail yields just the tail, such as "...". When true,
+	// the first cluster of nonzero width is kept before the tail, even if the
+	// result then exceeds maxWidth, as UIs that always show at least the
+	// first character do.
+	TruncateKeepFirst bool
+
+	// EnsureReset specifies whether truncation appends an SGR reset
+	// (ESC [ 0 m) when the result would otherwise end with colors or other
+	// graphic attributes still set, such as when the input's own reset is
+	// missing. When false (default), only escape sequences already in the
+	// input are preserved. EnsureReset requires ControlSequences, so that the
+	// reset does not count toward the width.
+	EnsureReset bool
+
+	// StopAtNUL specifies whether measuring stops at the first NUL byte
+	// (U+0000), as a renderer of C strings would. When false (default), NUL
+	// is a zero-width control character, and measurement continues past it.
+	//
+	// It applies to each function that returns the width of s, such as
+	// String, Bytes, WiderThan, WidthAndEmoji, BytesPair, EndColumn and
+	// ExplainString, but not to functions that return or iterate over parts
+	// of s, such as TruncateString, WrapOffsets and StringGraphemes. See
+	// also [Options.WidthUntilNUL], which reports the offset of the NUL.
+	StopAtNUL bool
+}
+
+// OSCFilter classifies OSC sequences for the OSCFilter option. It is a
+// pointer in Options, rather than a func, so that Options remain comparable.
+type OSCFilter struct {
+	// ZeroWidth reports whether the OSC sequence with the given payload is
+	// zero width. The payload is the bytes between the introducer (ESC ] or
+	// 0x9D) and the terminator (BEL, ESC \ or 0x9C), such as "0;title" or
+	// "8;;https://example.com". ZeroWidth must not retain or modify it.
+	ZeroWidth func(payload []byte) bool
 }
 
 // DefaultOptions is the default options for the display width
 // calculation, which is EastAsianWidth false, ControlSequences false, and
 // ControlSequences8Bit false.
+//
+// The package-level functions, such as [String], use DefaultOptions unless
+// overridden by [SetDefaultOptions]. Modifying DefaultOptions is not safe
+// while other goroutines call them; use SetDefaultOptions instead.
 var DefaultOptions = Options{
 	EastAsianWidth:       false,
 	ControlSequences:     false,
 	ControlSequences8Bit: false,
 }
+
+// defaultOptionsOverride holds the *Options set by SetDefaultOptions.
+var defaultOptionsOverride atomic.Value
+
+// SetDefaultOptions sets the options used by the package-level functions,
+// such as [String], [Bytes] and [Rune], in place of [DefaultOptions]. It is
+// intended for process-wide configuration, such as an EastAsianWidth setting
+// detected from the locale, without passing Options to every call.
+//
+// It is safe to call concurrently with the package-level functions, which
+// read the options atomically: each call uses either the old options or the
+// new ones. To ensure that all calls see the new options, call
+// SetDefaultOptions at startup, before any concurrent use.
+func SetDefaultOptions(options Options) {
+	defaultOptionsOverride.Store(&options)
+}
+
+// DefaultOptionsValue returns the options used by the package-level
+// functions: those set by [SetDefaultOptions], if any, otherwise
+// [DefaultOptions].
+func DefaultOptionsValue() Options {
+	if o, ok := defaultOptionsOverride.Load().(*Options); ok {
+		return *o
+	}
+	return DefaultOptions
+}
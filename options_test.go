@@ -0,0 +1,823 @@
+package displaywidth
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unicode"
+)
+
+func TestSplitFlags(t *testing.T) {
+	splitFlags := Options{SplitFlags: true}
+
+	tests := []widthTest{
+		{"flag US combined", "🇺🇸", defaultOptions, 2},
+		{"flag US split", "🇺🇸", splitFlags, 4},
+		{"flag JP split", "🇯🇵", splitFlags, 4},
+		{"two flags combined", "🇺🇸🇯🇵", defaultOptions, 4},
+		{"two flags split", "🇺🇸🇯🇵", splitFlags, 8},
+		{"text with flag split", "Go 🇺🇸!", splitFlags, 3 + 4 + 1},
+		{"lone regional indicator combined", "🇺", defaultOptions, 2},
+		{"lone regional indicator split", "🇺", splitFlags, 2},
+		{"odd regional indicators split", "🇺🇸🇯", splitFlags, 4 + 2},
+		{"non-flag emoji split", "😀", splitFlags, 2},
+		{"CJK split", "中", splitFlags, 2},
+		{"flag EAW split", "🇺🇸", Options{SplitFlags: true, EastAsianWidth: true}, 4},
+	}
+
+	testWidths(t, tests)
+
+	// A split flag is still a single grapheme cluster, so truncation
+	// keeps or drops it whole.
+	if got := splitFlags.TruncateString("🇺🇸🇯🇵", 4, ""); got != "🇺🇸" {
+		t.Errorf("TruncateString with SplitFlags = %q, want %q", got, "🇺🇸")
+	}
+	if got := splitFlags.TruncateString("🇺🇸🇯🇵", 3, ""); got != "" {
+		t.Errorf("TruncateString with SplitFlags = %q, want %q", got, "")
+	}
+}
+
+func TestTabWidth(t *testing.T) {
+	tab4 := Options{TabWidth: 4}
+	tab8 := Options{TabWidth: 8}
+
+	tests := []widthTest{
+		{"tab default", "\t", defaultOptions, 0},
+		{"tab default in text", "a\tb", defaultOptions, 2},
+
+		{"tab alone", "\t", tab4, 4},
+		{"two tabs", "\t\t", tab4, 8},
+		{"tab after one", "a\t", tab4, 4},
+		{"tab after three", "abc\t", tab4, 4},
+		{"tab after four", "abcd\t", tab4, 8},
+		{"tab between", "a\tb", tab4, 5},
+		{"tab 8", "ab\tc", tab8, 9},
+
+		// Wide characters advance the column by 2
+		{"tab after CJK", "中\t", tab4, 4},
+		{"tab after CJK and ASCII", "中文a\t", tab4, 8},
+		{"tab after emoji", "😀a\tb", tab4, 5},
+
+		// Zero-width clusters do not advance the column
+		{"tab after combining", "e\u0301\t", tab4, 4},
+		{"tab after escape", "\x1b[31m\t", Options{TabWidth: 4, ControlSequences: true}, 4},
+
+		// Newlines and carriage returns reset the column
+		{"tab after newline", "abc\n\t", tab4, 3 + 4},
+		{"tab after CRLF", "abc\r\n\t", tab4, 3 + 4},
+		{"tab after CR", "abc\r\t", tab4, 3 + 4},
+		{"tabs on two lines", "a\tb\nab\tc", tab4, 5 + 5},
+
+		// Vertical tabs and form feeds are zero width, and reset the column
+		// only with VerticalControls
+		{"vertical tab", "a\vb", defaultOptions, 2},
+		{"form feed", "a\fb", defaultOptions, 2},
+		{"tab after form feed", "abc\f\t", tab4, 4},
+		{"tab after vertical tab", "abc\v\t", tab4, 4},
+		{"tab after form feed, VerticalControls", "abc\f\t", Options{TabWidth: 4, VerticalControls: true}, 3 + 4},
+		{"tab after vertical tab, VerticalControls", "abc\v\t", Options{TabWidth: 4, VerticalControls: true}, 3 + 4},
+		{"tabs on two pages", "a\tb\fab\tc", Options{TabWidth: 4, VerticalControls: true}, 5 + 5},
+		{"VerticalControls without tabs", "a\v\fb", Options{VerticalControls: true}, 2},
+
+		// Ambiguous characters are measured before the tab
+		{"tab after ambiguous EAW", "★\t", Options{TabWidth: 4, EastAsianWidth: true}, 4},
+		{"tab after ambiguous EAW odd", "★a\t", Options{TabWidth: 4, EastAsianWidth: true}, 4},
+	}
+
+	testWidths(t, tests)
+
+	// Without context, Rune measures a tab as 0
+	if got := tab4.Rune('\t'); got != 0 {
+		t.Errorf("Rune('\\t') = %d, want 0", got)
+	}
+}
+
+func TestDECGraphics(t *testing.T) {
+	dec := Options{DECGraphics: true}
+	decCS := Options{DECGraphics: true, ControlSequences: true}
+
+	// "lqqk" draws a box top in DEC Special Graphics: ┌──┐
+	const box = "\x1b(0lqqk\x1b(B"
+
+	tests := []widthTest{
+		// Without DECGraphics, the escapes are printable text
+		{"default", box, defaultOptions, 2 + 4 + 2},
+		{"ControlSequences", box, controlSequences, 4},
+
+		{"box", box, dec, 4},
+		{"box with ControlSequences", box, decCS, 4},
+		{"enter only", "\x1b(0", dec, 0},
+		{"G1 set", "\x1b)0x\x1b)B", dec, 1},
+		{"G2 set", "\x1b*0x", dec, 1},
+		{"G3 set", "\x1b+0x", dec, 1},
+		{"96-character set", "\x1b-Ax", dec, 1},
+		{"intermediate", "\x1b(%5x", dec, 1},
+		{"letter runs", "a\x1b(0lqk\x1b(Bb\x1b(0mqj\x1b(Bc", dec, 9},
+		{"mixed with CJK", "中\x1b(0q\x1b(B文", dec, 5},
+
+		// Other escapes are still printable without ControlSequences
+		{"SGR", "\x1b[31m\x1b(0q\x1b(B", dec, 4 + 1},
+		{"SGR with ControlSequences", "\x1b[31m\x1b(0q\x1b(B", decCS, 1},
+
+		// Combines with TabWidth
+		{"tab", "\x1b(0q\x1b(B\t", Options{DECGraphics: true, TabWidth: 4}, 4},
+	}
+
+	testWidths(t, tests)
+
+	// Truncation keeps the escapes whole, and measures them as zero width
+	if got := dec.TruncateString(box, 3, ""); got != "\x1b(0lqq" {
+		t.Errorf("TruncateString(%q, 3) = %q, want %q", box, got, "\x1b(0lqq")
+	}
+}
+
+func TestMaxCombiningPerCluster(t *testing.T) {
+	marks := func(n int) string {
+		return strings.Repeat("\u0301", n)
+	}
+	max2 := Options{MaxCombiningPerCluster: 2}
+	max3 := Options{MaxCombiningPerCluster: 3}
+
+	tests := []widthTest{
+		// Default is unlimited
+		{"default", "e" + marks(50), defaultOptions, 1},
+
+		{"no marks", "e", max2, 1},
+		{"at limit", "e" + marks(2), max2, 1},
+		{"one over", "e" + marks(3), max2, 2},
+		{"second group full", "e" + marks(4), max2, 2},
+		{"third group", "e" + marks(5), max2, 3},
+		{"max 3", "e" + marks(7), max3, 1 + 2},
+		{"zalgo", "Z" + marks(30) + "a" + marks(30), max3, 2 * (1 + 9)},
+		{"mixed marks", "a\u0300\u0301\u0302\u0303\u20DD", max2, 1 + 2},
+
+		// Wide bases keep their width
+		{"CJK base", "中" + marks(3), max2, 2 + 1},
+
+		// Variation selectors, ZWJ and tags are not marks
+		{"VS16", "\u2764\uFE0F", Options{MaxCombiningPerCluster: 1}, 2},
+		{"ZWJ sequence", "👩\u200D💻", Options{MaxCombiningPerCluster: 1}, 2},
+		{"flag tag sequence", "\U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", Options{MaxCombiningPerCluster: 1}, 2},
+		{"keycap", "1\uFE0F\u20E3", Options{MaxCombiningPerCluster: 1}, 2},
+	}
+
+	testWidths(t, tests)
+}
+
+func TestUnknownWidth(t *testing.T) {
+	unknown2 := Options{UnknownWidth: 2}
+
+	tests := []widthTest{
+		// Planes 4-13 are unassigned
+		{"plane 4 default", "\U00040000", defaultOptions, 1},
+		{"plane 4", "\U00040000", unknown2, 2},
+		{"plane 8", "\U00080123", unknown2, 2},
+		{"plane 13 last", "\U000DFFFF", unknown2, 2},
+		{"explicit 1", "\U00050000", Options{UnknownWidth: 1}, 1},
+		{"in text", "a\U00060000b", unknown2, 4},
+
+		// Assigned and reserved planes are unaffected
+		{"ASCII", "a", unknown2, 1},
+		{"CJK", "中", unknown2, 2},
+		{"plane 3 CJK extension", "\U00030000", unknown2, 2},
+		{"plane 14 tag", "\U000E0041", unknown2, 0},
+		{"plane 15 private use", "\U000F0000", unknown2, 1},
+		{"plane 1 unassigned", "\U00011FF0", unknown2, 1},
+
+		// Combining marks on an unknown base do not add width
+		{"unknown with combining", "\U00040000\u0301", unknown2, 2},
+	}
+
+	testWidths(t, tests)
+
+	if got := unknown2.Rune(0x40000); got != 2 {
+		t.Errorf("Rune(U+40000) = %d, want 2", got)
+	}
+}
+
+func TestEmojiSet(t *testing.T) {
+	// A font that supports only 😀 (U+1F600), ❤ (U+2764) and the US flag's
+	// regional indicators
+	allowed := Options{EmojiSet: &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 0x2764, Hi: 0x2764, Stride: 1},
+		},
+		R32: []unicode.Range32{
+			{Lo: 0x1F1F8, Hi: 0x1F1F8, Stride: 1}, // S
+			{Lo: 0x1F1FA, Hi: 0x1F1FA, Stride: 1}, // U
+			{Lo: 0x1F600, Hi: 0x1F600, Stride: 1},
+		},
+	}}
+	empty := Options{EmojiSet: &unicode.RangeTable{}}
+
+	tests := []widthTest{
+		{"nil set", "😁", defaultOptions, 2},
+
+		{"allowed", "😀", allowed, 2},
+		{"not allowed", "😁", allowed, 1},
+		{"empty set", "😀", empty, 1},
+		{"allowed VS16", "\u2764\uFE0F", allowed, 2},
+		{"not allowed VS16", "\u263A\uFE0F", allowed, 1},
+		{"allowed flag", "🇺🇸", allowed, 2},
+		{"not allowed flag", "🇯🇵", allowed, 1},
+		{"allowed modifier", "😀\U0001F3FB", allowed, 2},
+		{"not allowed ZWJ sequence", "👩\u200D💻", allowed, 1},
+		{"mixed", "😀😁a", allowed, 2 + 1 + 1},
+
+		// Other wide characters are unaffected
+		{"CJK", "中文", empty, 4},
+		{"fullwidth", "Ａ", empty, 2},
+		{"ASCII", "abc", empty, 3},
+		{"keycap", "1\uFE0F\u20E3", empty, 2},
+		{"ambiguous EAW", "\u2605", Options{EmojiSet: &unicode.RangeTable{}, EastAsianWidth: true}, 2},
+	}
+
+	testWidths(t, tests)
+
+	if got := empty.Rune('😀'); got != 1 {
+		t.Errorf("Rune('😀') = %d, want 1", got)
+	}
+}
+
+func TestHonorVS15(t *testing.T) {
+	vs15 := Options{HonorVS15: true}
+
+	tests := []widthTest{
+		// By default, VS15 is a no-op, per TR51
+		{"default emoji with VS15", "\u231B\uFE0E", defaultOptions, 2},
+
+		// Emoji presentation base, narrowed by VS15
+		{"hourglass with VS15", "\u231B\uFE0E", vs15, 1},
+		{"watch with VS15", "\u231A\uFE0E", vs15, 1},
+		{"soccer ball with VS15", "\u26BD\uFE0E", vs15, 1},
+		{"anchor with VS15", "\u2693\uFE0E", vs15, 1},
+		{"VS15 in text", "a\u231B\uFE0Eb", vs15, 3},
+
+		// Without VS15, emoji presentation remains
+		{"hourglass", "\u231B", vs15, 2},
+		{"hourglass with VS16", "\u231B\uFE0F", vs15, 2},
+		{"grinning face", "😀", vs15, 2},
+
+		// The inverse: VS16 widens a text presentation base, regardless
+		{"heart", "\u2764", vs15, 1},
+		{"heart with VS16", "\u2764\uFE0F", vs15, 2},
+		{"heart with VS15", "\u2764\uFE0E", vs15, 1},
+
+		// Other wide characters are unaffected
+		{"CJK with VS15", "中\uFE0E", vs15, 2},
+		{"fullwidth with VS15", "Ａ\uFE0E", vs15, 2},
+
+		// VS15 must directly follow the base
+		{"VS15 after modifier", "\U0001F44D\U0001F3FD\uFE0E", vs15, 2},
+	}
+
+	testWidths(t, tests)
+}
+
+func TestPrivateUseWidth(t *testing.T) {
+	pua2 := Options{PrivateUseWidth: 2}
+
+	tests := []widthTest{
+		// By default, private use is East Asian Ambiguous
+		{"BMP default", "\uE000", defaultOptions, 1},
+		{"BMP default EAW", "\uE000", eawOptions, 2},
+		{"plane 15 default", "\U000F0000", defaultOptions, 1},
+		{"plane 15 default EAW", "\U000F0000", eawOptions, 2},
+		{"plane 16 default", "\U00100000", defaultOptions, 1},
+
+		{"BMP first", "\uE000", pua2, 2},
+		{"BMP last", "\uF8FF", pua2, 2},
+		{"Nerd Font folder", "\uF07B", pua2, 2},
+		{"Powerline arrow", "\uE0B0", pua2, 2},
+		{"plane 15 first", "\U000F0000", pua2, 2},
+		{"plane 15 last", "\U000FFFFD", pua2, 2},
+		{"plane 16 first", "\U00100000", pua2, 2},
+		{"plane 16 last", "\U0010FFFD", pua2, 2},
+		{"icons in text", "\uF07B src", pua2, 2 + 4},
+
+		// Explicit width overrides EastAsianWidth
+		{"width 1 with EAW", "\uE000", Options{PrivateUseWidth: 1, EastAsianWidth: true}, 1},
+
+		// Neighbors are unaffected
+		{"after BMP", "\uF900", pua2, 2},
+		{"ASCII", "a", pua2, 1},
+		{"plane 15 noncharacter", "\U000FFFFE", pua2, 1},
+	}
+
+	testWidths(t, tests)
+
+	if got := pua2.Rune(0xE000); got != 2 {
+		t.Errorf("Rune(U+E000) = %d, want 2", got)
+	}
+}
+
+func TestContextualAmbiguous(t *testing.T) {
+	ctx := Options{ContextualAmbiguous: true}
+
+	tests := []widthTest{
+		{"default after CJK", "中★", defaultOptions, 2 + 1},
+
+		{"alone", "★", ctx, 1},
+		{"after ASCII", "a★", ctx, 1 + 1},
+		{"after CJK", "中★", ctx, 2 + 2},
+		{"after hiragana", "あ→", ctx, 2 + 2},
+		{"after emoji", "😀★", ctx, 2 + 2},
+		{"before CJK", "★中", ctx, 1 + 2},
+		{"run after CJK", "中★★★", ctx, 2 + 2 + 2 + 2},
+		{"after CJK then ASCII", "中a★", ctx, 2 + 1 + 1},
+		{"after CJK then space", "中 ★", ctx, 2 + 1 + 1},
+		{"Greek after CJK", "中\u03B1", ctx, 2 + 2},
+
+		// Zero-width clusters do not change the context
+		{"after combining", "中\u0301★", ctx, 2 + 2},
+		{"after escape", "中\x1b[31m★", Options{ContextualAmbiguous: true, ControlSequences: true}, 2 + 2},
+
+		// Newlines reset the context
+		{"after newline", "中\n★", ctx, 2 + 1},
+
+		// Non-ambiguous narrow characters are unaffected
+		{"ASCII after CJK", "中a", ctx, 2 + 1},
+		{"Latin after CJK", "中\u01A1", ctx, 2 + 1},
+
+		// With EastAsianWidth, ambiguous characters are already wide
+		{"EAW", "a★", Options{ContextualAmbiguous: true, EastAsianWidth: true}, 1 + 2},
+	}
+
+	testWidths(t, tests)
+}
+
+func TestHalfwidthAsWide(t *testing.T) {
+	wide := Options{HalfwidthAsWide: true}
+
+	tests := []struct {
+		name     string
+		input    string
+		narrow   int
+		expected int
+	}{
+		{"halfwidth katakana ka", "\uFF76", 1, 2},
+		{"halfwidth katakana word", "\uFF76\uFF80\uFF76\uFF85", 4, 8},
+		{"halfwidth katakana with voiced mark", "\uFF76\uFF9E", 1, 2},
+		{"halfwidth ideographic full stop", "\uFF61", 1, 2},
+		{"halfwidth prolonged sound mark", "\uFF70", 1, 2},
+		{"halfwidth Hangul", "\uFFA1\uFFC2", 2, 4},
+		{"halfwidth arrow", "\uFFE9", 1, 2},
+		{"won sign", "\u20A9", 1, 2},
+		{"halfwidth Hangul filler", "\uFFA0", 1, 2},
+		{"fullwidth katakana unaffected", "カ", 2, 2},
+		{"fullwidth Latin unaffected", "Ａ", 2, 2},
+		{"ASCII unaffected", "abc", 3, 3},
+		{"mixed", "abc\uFF76\uFF80", 5, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultOptions.String(tt.input); got != tt.narrow {
+				t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.narrow)
+			}
+			if got := wide.String(tt.input); got != tt.expected {
+				t.Errorf("String(%q) with HalfwidthAsWide = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := wide.Bytes([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Bytes(%q) with HalfwidthAsWide = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	// Only halfwidth characters are affected
+	for r := rune(0x80); r <= 0x1FFFF; r++ {
+		if r >= 0xD800 && r <= 0xDFFF {
+			continue
+		}
+		want := Rune(r)
+		if isHalfwidth(r) && want == 1 {
+			want = 2
+		}
+		if got := wide.Rune(r); got != want {
+			t.Fatalf("Rune(%U) with HalfwidthAsWide = %d, want %d", r, got, want)
+		}
+	}
+}
+
+func TestSetDefaultOptions(t *testing.T) {
+	t.Cleanup(func() { defaultOptionsOverride = atomic.Value{} })
+
+	if got := DefaultOptionsValue(); got != DefaultOptions {
+		t.Errorf("DefaultOptionsValue() = %+v, want DefaultOptions", got)
+	}
+	if got := String("★"); got != 1 {
+		t.Errorf("String() = %d, want 1", got)
+	}
+
+	SetDefaultOptions(Options{EastAsianWidth: true})
+
+	if got := DefaultOptionsValue(); !got.EastAsianWidth {
+		t.Errorf("DefaultOptionsValue() = %+v, want EastAsianWidth", got)
+	}
+	if DefaultOptions.EastAsianWidth {
+		t.Errorf("SetDefaultOptions() modified DefaultOptions")
+	}
+	if got := String("★"); got != 2 {
+		t.Errorf("String() = %d, want 2", got)
+	}
+	if got := Bytes([]byte("★")); got != 2 {
+		t.Errorf("Bytes() = %d, want 2", got)
+	}
+	if got := Rune('★'); got != 2 {
+		t.Errorf("Rune() = %d, want 2", got)
+	}
+	if got := TruncateString("★★★", 4, ""); got != "★★" {
+		t.Errorf("TruncateString() = %q, want %q", got, "★★")
+	}
+}
+
+func TestSetDefaultOptionsConcurrent(t *testing.T) {
+	// Run with -race: setting the default concurrently with calls is safe,
+	// and each call sees either the old or the new options.
+	t.Cleanup(func() { defaultOptionsOverride = atomic.Value{} })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if w := String("★"); w != 1 && w != 2 {
+					t.Errorf("String() = %d, want 1 or 2", w)
+					return
+				}
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		SetDefaultOptions(Options{EastAsianWidth: j%2 == 0})
+	}
+	wg.Wait()
+}
+
+func TestEmojiFallback(t *testing.T) {
+	fallback := Options{EmojiFallback: true}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"family", "👨\u200D👩\u200D👧", 6},
+		{"family of four", "👨\u200D👩\u200D👧\u200D👦", 8},
+		{"technologist", "👩\u200D💻", 4},
+		{"health worker", "🧑\u200D⚕\uFE0F", 4},
+		{"health worker without VS16", "🧑\u200D⚕", 3},
+		{"technologist with skin tone", "👩🏽\u200D💻", 4},
+		{"rainbow flag", "🏳\uFE0F\u200D🌈", 4},
+		{"couple with heart", "👩\u200D❤\uFE0F\u200D👨", 6},
+		{"family with text", "a👨\u200D👩\u200D👧b", 8},
+		{"trailing ZWJ", "👨\u200D", 2},
+
+		// Not ZWJ sequences, or not emoji
+		{"single emoji", "😀", 2},
+		{"skin tone", "👍🏽", 2},
+		{"flag", "🇺🇸", 2},
+		{"keycap", "1\uFE0F\u20E3", 2},
+		{"Devanagari with ZWJ", "\u0915\u094D\u200D\u0937", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fallback.String(tt.input); got != tt.expected {
+				t.Errorf("String(%q) with EmojiFallback = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := fallback.Bytes([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Bytes(%q) with EmojiFallback = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	// Default collapses a ZWJ sequence to width 2
+	if got := String("👨\u200D👩\u200D👧"); got != 2 {
+		t.Errorf("String() = %d, want 2", got)
+	}
+}
+
+func TestOSCFilter(t *testing.T) {
+	// Window titles are measured, other OSC sequences are zero width
+	titles := &OSCFilter{ZeroWidth: func(payload []byte) bool {
+		return !bytes.HasPrefix(payload, []byte("0;")) && !bytes.HasPrefix(payload, []byte("2;"))
+	}}
+	filtered := Options{ControlSequences: true, OSCFilter: titles}
+
+	tests := []widthTest{
+		{"SGR", "\x1b[31mab\x1b[0m", filtered, 2},
+		{"title BEL", "\x1b]0;hi\x07ab", filtered, 7},  // "]0;hi" and "ab"
+		{"title ST", "\x1b]2;hi\x1b\\ab", filtered, 8}, // "]2;hi", "\" and "ab"
+		{"hyperlink", "\x1b]8;;https://example.com\x07link\x1b]8;;\x07", filtered, 4},
+		{"title and SGR", "\x1b[1m\x1b]0;hi\x07ab\x1b[0m", filtered, 7},
+		{"no filter", "\x1b]0;hi\x07ab", controlSequences, 2},
+		{"no ControlSequences", "\x1b]8;;x\x07ab", Options{OSCFilter: titles}, 7}, // "]8;;x" and "ab"
+		{"8-bit hyperlink", "\x9d8;;x\x9cab", Options{ControlSequences8Bit: true, OSCFilter: titles}, 2},
+		{"tabs", "\x1b]0;hi\x07\tab", Options{ControlSequences: true, TabWidth: 8, OSCFilter: titles}, 10},
+		{"DECGraphics", "\x1b(0\x1b]0;hi\x07ab", Options{ControlSequences: true, DECGraphics: true, OSCFilter: titles}, 7},
+	}
+
+	testWidths(t, tests)
+
+	// The payload excludes the introducer and terminator
+	var payloads []string
+	record := Options{ControlSequences: true, ControlSequences8Bit: true, OSCFilter: &OSCFilter{ZeroWidth: func(payload []byte) bool {
+		payloads = append(payloads, string(payload))
+		return true
+	}}}
+	record.String("\x1b]0;a\x07\x1b]2;b\x1b\\\x1b[31m\x9d8;;c\x9c")
+	expected := []string{"0;a", "2;b", "8;;c"}
+	if strings.Join(payloads, "|") != strings.Join(expected, "|") {
+		t.Errorf("payloads = %q, want %q", payloads, expected)
+	}
+}
+
+func TestEmojiWidth(t *testing.T) {
+	text := Options{EmojiWidth: 1}
+
+	tests := []widthTest{
+		{"emoji", "😀", text, 1},
+		{"emoji string", "😀😀😀", text, 3},
+		{"flag", "🇺🇸", text, 1},
+		{"lone regional indicator", "🇺", text, 1},
+		{"ZWJ sequence", "👩\u200D💻", text, 1},
+		{"family", "👨\u200D👩\u200D👧\u200D👦", text, 1},
+		{"skin tone", "👋🏽", text, 1},
+		{"VS16", "❤\uFE0F", text, 1},
+		{"keycap", "1\uFE0F\u20E3", text, 1},
+		{"mixed", "a😀b🇺🇸c", text, 5},
+
+		// Other wide characters are unaffected
+		{"CJK", "中文", text, 4},
+		{"fullwidth", "Ａ", text, 2},
+		{"ambiguous EAW", "★", Options{EmojiWidth: 1, EastAsianWidth: true}, 2},
+
+		// Interaction with other options
+		{"SplitFlags", "🇺🇸", Options{EmojiWidth: 1, SplitFlags: true}, 2},
+		{"EmojiFallback", "👩\u200D💻", Options{EmojiWidth: 1, EmojiFallback: true}, 2},
+		{"EmojiSet", "😀🚀", Options{EmojiWidth: 3, EmojiSet: &unicode.RangeTable{R32: []unicode.Range32{{Lo: 0x1F600, Hi: 0x1F600, Stride: 1}}}}, 4},
+		{"default", "😀🇺🇸❤\uFE0F", defaultOptions, 6},
+	}
+
+	testWidths(t, tests)
+
+	if got := text.Rune('😀'); got != 1 {
+		t.Errorf("Rune(%q) = %d, want 1", '😀', got)
+	}
+}
+
+func TestWideEnclosingMarks(t *testing.T) {
+	wide := Options{WideEnclosingMarks: true}
+
+	tests := []struct {
+		name     string
+		input    string
+		narrow   int
+		expected int
+	}{
+		{"circled digit", "1\u20DD", 1, 2},
+		{"circled letter", "a\u20DD", 1, 2},
+		{"enclosing square", "a\u20DE", 1, 2},
+		{"circled wide", "中\u20DD", 2, 3},
+		{"text keycap", "1\u20E3", 1, 2},
+		{"emoji keycap", "1\uFE0F\u20E3", 2, 2},
+		{"Cyrillic millions sign", "\u0430\u0489", 1, 2},
+		{"with other marks", "e\u0301\u20DD", 1, 2},
+		{"nonspacing mark", "e\u0301", 1, 1},
+		{"lone enclosing mark", "\u20DD", 0, 0},
+		{"text", "a1\u20DDb", 3, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultOptions.String(tt.input); got != tt.narrow {
+				t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.narrow)
+			}
+			if got := wide.String(tt.input); got != tt.expected {
+				t.Errorf("String(%q) with WideEnclosingMarks = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := wide.Bytes([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Bytes(%q) with WideEnclosingMarks = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNarrowAmbiguous(t *testing.T) {
+	greek := &unicode.RangeTable{R16: []unicode.Range16{
+		{Lo: 0x0391, Hi: 0x03A9, Stride: 1},
+		{Lo: 0x03B1, Hi: 0x03C9, Stride: 1},
+	}}
+	narrowGreek := Options{EastAsianWidth: true, NarrowAmbiguous: greek}
+
+	tests := []widthTest{
+		{"Greek EAW", "αβγ", eawOptions, 6},
+		{"Greek narrow", "αβγ", narrowGreek, 3},
+		{"Greek capital narrow", "ΣΩ", narrowGreek, 2},
+		{"Greek without EAW", "αβγ", Options{NarrowAmbiguous: greek}, 3},
+		{"other ambiguous stays wide", "α±§", narrowGreek, 1 + 2 + 2},
+		{"Cyrillic stays wide", "б", narrowGreek, 2},
+		{"wide unaffected", "中", Options{NarrowAmbiguous: &unicode.RangeTable{R16: []unicode.Range16{{Lo: 0x4E2D, Hi: 0x4E2D, Stride: 1}}}}, 2},
+		{"formula", "x = α + β", narrowGreek, 9},
+		{"contextual", "中α", Options{ContextualAmbiguous: true, NarrowAmbiguous: greek}, 3},
+		{"contextual unlisted", "中±", Options{ContextualAmbiguous: true, NarrowAmbiguous: greek}, 4},
+	}
+
+	testWidths(t, tests)
+
+	if got := narrowGreek.Rune('α'); got != 1 {
+		t.Errorf("Rune(%q) = %d, want 1", 'α', got)
+	}
+	scaled := Options{EastAsianWidth: true, NarrowAmbiguous: greek, AmbiguousScaledWidth: 15}
+	if got := scaled.ScaledWidth("α±", 10); got != 10+15 {
+		t.Errorf("ScaledWidth(%q) = %d, want %d", "α±", got, 10+15)
+	}
+}
+
+func TestMaxClusterBytes(t *testing.T) {
+	// "e" with a thousand combining marks, 2001 bytes in a single cluster
+	zalgo := "e" + strings.Repeat("\u0301", 1000)
+	chain := strings.Repeat("\U0001F469\u200D", 99) + "\U0001F469" // 100 emoji, 697 bytes
+
+	tests := []widthTest{
+		{"zalgo unlimited", zalgo, defaultOptions, 1},
+		{"zalgo 64", zalgo, Options{MaxClusterBytes: 64}, 32},
+		{"zalgo 1000", zalgo, Options{MaxClusterBytes: 1000}, 3},
+		{"zalgo 2001", zalgo, Options{MaxClusterBytes: 2001}, 1},
+		{"ZWJ chain unlimited", chain, defaultOptions, 2},
+		{"ZWJ chain 100", chain, Options{MaxClusterBytes: 100}, 2 * 7},
+		{"short clusters unaffected", "e\u0301中😀", Options{MaxClusterBytes: 4}, 1 + 2 + 2},
+		{"cut at rune boundary", "中\u0301\u0301", Options{MaxClusterBytes: 4}, 2 * 2},
+		{"zero-width leader", "\u200B" + strings.Repeat("\u0301", 10), Options{MaxClusterBytes: 4}, 0},
+		{"zalgo in text", "ab" + zalgo + "cd", Options{MaxClusterBytes: 500}, 2 + 5 + 2},
+	}
+
+	testWidths(t, tests)
+}
+
+func TestCombiningOverride(t *testing.T) {
+	// U+E0080 is unassigned; pretend a future Unicode version makes it a mark
+	newMark := &unicode.RangeTable{R32: []unicode.Range32{{Lo: 0xE0080, Hi: 0xE0080, Stride: 1}}}
+	override := Options{CombiningOverride: newMark}
+
+	tests := []widthTest{
+		{"new mark default", "\U000E0080", defaultOptions, 1},
+		{"new mark override", "\U000E0080", override, 0},
+		{"after base default", "a\U000E0080", defaultOptions, 2},
+		{"after base override", "a\U000E0080", override, 1},
+		{"after wide override", "中\U000E0080", override, 2},
+		{"repeated override", "a\U000E0080\U000E0080", override, 1},
+		{"with EAW", "中\U000E0080", Options{EastAsianWidth: true, CombiningOverride: newMark}, 2},
+		{"existing cluster unaffected", "e\u0301", override, 1},
+		{"emoji unaffected", "😀", override, 2},
+		{"ASCII unaffected", "a", Options{CombiningOverride: &unicode.RangeTable{R16: []unicode.Range16{{Lo: 'a', Hi: 'a', Stride: 1}}}}, 1},
+		{"listed existing mark", "e\u0301", Options{CombiningOverride: &unicode.RangeTable{R16: []unicode.Range16{{Lo: 0x0301, Hi: 0x0301, Stride: 1}}}}, 1},
+		{"overrides PrivateUseWidth", "\uE000", Options{PrivateUseWidth: 2, CombiningOverride: &unicode.RangeTable{R16: []unicode.Range16{{Lo: 0xE000, Hi: 0xE000, Stride: 1}}}}, 0},
+	}
+
+	testWidths(t, tests)
+
+	if got := override.Rune(0xE0080); got != 0 {
+		t.Errorf("Rune(%U) = %d, want 0", 0xE0080, got)
+	}
+	if got := override.ExplainString("\U000E0080"); len(got) != 1 || got[0].Reason != ReasonZeroWidth {
+		t.Errorf("ExplainString(%q) = %+v, want ReasonZeroWidth", "\U000E0080", got)
+	}
+}
+
+func TestAmbiguousInCJKContext(t *testing.T) {
+	cjk := Options{AmbiguousInCJKContext: true}
+
+	tests := []widthTest{
+		{"alone", "★", cjk, 1},
+		{"between ASCII", "a★b", cjk, 1 + 1 + 1},
+		{"after CJK", "中★", cjk, 2 + 2},
+		{"before CJK", "★中", cjk, 2 + 2},
+		{"between CJK", "中★文", cjk, 2 + 2 + 2},
+		{"after CJK before ASCII", "中★a", cjk, 2 + 2 + 1},
+		{"after ASCII before CJK", "a★中", cjk, 1 + 2 + 2},
+		{"run after CJK", "中★★", cjk, 2 + 2 + 1},
+		{"run before CJK", "★★中", cjk, 1 + 2 + 2},
+		{"Greek in Japanese", "角度θは", cjk, 2 + 2 + 2 + 2},
+		{"Greek in English", "angle θ is", cjk, 10},
+		{"after fullwidth", "Ａ→", cjk, 2 + 2},
+		{"after space", "中 ★", cjk, 2 + 1 + 1},
+
+		// Emoji are not CJK context
+		{"after emoji", "😀★", cjk, 2 + 1},
+		{"before flag", "★🇯🇵", cjk, 1 + 2},
+
+		// Zero-width clusters do not separate neighbors
+		{"combining before CJK", "★\u0301中", cjk, 2 + 2},
+		{"escape after CJK", "中\x1b[31m★", Options{AmbiguousInCJKContext: true, ControlSequences: true}, 2 + 2},
+		{"escape before CJK", "★\x1b[31m中", Options{AmbiguousInCJKContext: true, ControlSequences: true}, 2 + 2},
+
+		// Newlines separate neighbors
+		{"newline after CJK", "中\n★", cjk, 2 + 1},
+		{"newline before CJK", "★\n中", cjk, 1 + 2},
+
+		// Tabs are neighbors, and the column includes the widened cluster
+		{"tab before CJK", "★\t中", Options{AmbiguousInCJKContext: true, TabWidth: 8}, 1 + 7 + 2},
+		{"before CJK then tab", "★中\t", Options{AmbiguousInCJKContext: true, TabWidth: 8}, 2 + 2 + 4},
+
+		// EastAsianWidth does not widen ambiguous characters outside CJK
+		{"EAW between ASCII", "a★b", Options{AmbiguousInCJKContext: true, EastAsianWidth: true}, 1 + 1 + 1},
+		{"EAW after CJK", "中★", Options{AmbiguousInCJKContext: true, EastAsianWidth: true}, 2 + 2},
+
+		// Precedence over ContextualAmbiguous, which would widen after emoji
+		{"with ContextualAmbiguous", "😀★", Options{AmbiguousInCJKContext: true, ContextualAmbiguous: true}, 2 + 1},
+
+		// Non-ambiguous characters are unaffected
+		{"ASCII before CJK", "a中", cjk, 1 + 2},
+	}
+
+	testWidths(t, tests)
+}
+
+func TestNeutralAsWide(t *testing.T) {
+	neutral := Options{NeutralAsWide: true}
+
+	tests := []widthTest{
+		{"Latin-1 default", "ñç", defaultOptions, 2},
+		{"Latin-1", "ñç", neutral, 4},
+		{"Latin-1 capital", "ÄÖ", neutral, 4},
+		{"copyright", "©", neutral, 2},
+		{"Greek tonos", "ά", neutral, 2},
+		{"Hebrew", "א", neutral, 2},
+		{"place of interest", "⌘", neutral, 2},
+		{"ASCII with combining", "c\u0327\u0301", neutral, 1},
+		{"Latin-1 with combining", "ç\u0301", neutral, 2},
+
+		// ASCII, Narrow and Halfwidth stay narrow
+		{"ASCII", "hello", neutral, 5},
+		{"cent sign", "¢", neutral, 1},
+		{"yen sign", "¥", neutral, 1},
+		{"mathematical bracket", "⟦", neutral, 1},
+		{"halfwidth katakana", "ｱ", neutral, 1},
+		{"halfwidth katakana as wide", "ｱ", Options{NeutralAsWide: true, HalfwidthAsWide: true}, 2},
+
+		// Ambiguous characters follow EastAsianWidth
+		{"ambiguous", "é→", neutral, 2},
+		{"ambiguous EAW", "é→", Options{NeutralAsWide: true, EastAsianWidth: true}, 4},
+
+		// Wide, zero width, emoji and controls are unaffected
+		{"CJK", "中", neutral, 2},
+		{"zero-width space", "\u200B", neutral, 0},
+		{"emoji", "😀", neutral, 2},
+		{"VS16", "☺\uFE0F", neutral, 2},
+		{"C1 control", "\u0085", neutral, 0},
+		{"invalid UTF-8", "\xff\xc3", neutral, String("\xff\xc3")},
+
+		{"mixed", "Añb中", neutral, 1 + 2 + 1 + 2},
+	}
+
+	testWidths(t, tests)
+
+	if got := neutral.Rune('ñ'); got != 2 {
+		t.Errorf("Rune(%q) = %d, want 2", 'ñ', got)
+	}
+}
+
+func TestVisibleJoiners(t *testing.T) {
+	visible := Options{VisibleJoiners: true}
+	const family = "👨\u200D👩\u200D👧"
+
+	tests := []widthTest{
+		// Default: joiners are zero width, and emoji compose as usual
+		{"family default", family, defaultOptions, 2},
+		{"ZWJ default", "\u200D", defaultOptions, 0},
+		{"ZWNJ default", "\u200C", defaultOptions, 0},
+		{"between letters default", "a\u200Db", defaultOptions, 2},
+		{"rainbow flag default", "🏳\uFE0F\u200D🌈", defaultOptions, 2},
+		{"skin tone ZWJ default", "👩🏽\u200D💻", defaultOptions, 2},
+
+		{"family", family, visible, 2 + 1 + 2 + 1 + 2},
+		{"ZWJ", "\u200D", visible, 1},
+		{"ZWNJ", "\u200C", visible, 1},
+		{"between letters", "a\u200Db", visible, 3},
+		{"ZWNJ in Persian", "\u0645\u06CC\u200C\u062E\u0648\u0627\u0647\u0645", visible, 8},
+		{"rainbow flag", "🏳\uFE0F\u200D🌈", visible, 2 + 1 + 2},
+		{"skin tone ZWJ", "👩🏽\u200D💻", visible, 2 + 1 + 2},
+		{"trailing joiner", "👨\u200D", visible, 3},
+		{"double joiner", "a\u200D\u200Db", visible, 4},
+
+		// Other characters are unaffected
+		{"emoji", "😀", visible, 2},
+		{"CJK", "中文", visible, 4},
+		{"ZWSP", "\u200B", visible, 0},
+		{"EmojiFallback", family, Options{VisibleJoiners: true, EmojiFallback: true}, 8},
+	}
+
+	testWidths(t, tests)
+
+	if got := visible.Rune(0x200D); got != 1 {
+		t.Errorf("Rune(U+200D) = %d, want 1", got)
+	}
+	if got := Rune(0x200D); got != 0 {
+		t.Errorf("Rune(U+200D) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,73 @@
+package displaywidth
+
+import (
+	"github.com/clipperhouse/uax29/v2/graphemes"
+)
+
+// Overflow returns the number of columns by which s exceeds maxWidth, or 0 if
+// s fits. See [Options.Overflow].
+func Overflow(s string, maxWidth int) int {
+	return DefaultOptionsValue().Overflow(s, maxWidth)
+}
+
+// Overflow returns the number of columns by which s exceeds maxWidth, with
+// the given options, or 0 if s fits. It is max(0, String(s) - maxWidth).
+//
+// Reporting the exact amount requires measuring all of s. To only check
+// whether s fits, use [Options.WiderThan], which stops early.
+func (options Options) Overflow(s string, maxWidth int) int {
+	if over := options.String(s) - maxWidth; over > 0 {
+		return over
+	}
+	return 0
+}
+
+// WiderThan reports whether the width of s is greater than maxWidth. See
+// [Options.WiderThan].
+func WiderThan(s string, maxWidth int) bool {
+	return DefaultOptionsValue().WiderThan(s, maxWidth)
+}
+
+// WiderThan reports whether the width of s, with the given options, is
+// greater than maxWidth. It is equivalent to String(s) > maxWidth, but stops
+// measuring as soon as the width exceeds maxWidth.
+func (options Options) WiderThan(s string, maxWidth int) bool {
+	s = untilNUL(s, options)
+	if options.contextual() {
+		return options.String(s) > maxWidth
+	}
+
+	width := 0
+	for pos := 0; pos < len(s); {
+		// Same ASCII optimization as String
+		if asciiLen := printableASCIILength(s[pos:]); asciiLen > 0 {
+			width += asciiLen
+			pos += asciiLen
+			if width > maxWidth {
+				return true
+			}
+			continue
+		}
+
+		g := graphemes.FromString(s[pos:])
+		g.AnsiEscapeSequences = options.ControlSequences
+		g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+
+		start := pos
+		for g.Next() {
+			v := g.Value()
+			width += graphemeWidth(v, options)
+			pos += len(v)
+			if width > maxWidth {
+				return true
+			}
+			if pos < len(s) && s[pos] >= 0x20 && s[pos] <= 0x7E {
+				break
+			}
+		}
+		if pos == start {
+			pos++
+		}
+	}
+	return width > maxWidth
+}
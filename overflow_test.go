@@ -0,0 +1,71 @@
+package displaywidth
+
+import "testing"
+
+func TestOverflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxWidth int
+		options  Options
+		expected int
+	}{
+		{"empty", "", 0, defaultOptions, 0},
+		{"empty negative", "", -1, defaultOptions, 1},
+		{"fits with room", "hello", 10, defaultOptions, 0},
+		{"fits exactly", "hello", 5, defaultOptions, 0},
+		{"over by one", "hello", 4, defaultOptions, 1},
+		{"over by many", "hello world", 5, defaultOptions, 6},
+
+		// Wide characters
+		{"CJK fits exactly", "中文", 4, defaultOptions, 0},
+		{"CJK straddling", "中文", 3, defaultOptions, 1},
+		{"CJK over", "中文字", 3, defaultOptions, 3},
+		{"emoji straddling", "a😀", 2, defaultOptions, 1},
+
+		// Options
+		{"ambiguous", "★★", 2, defaultOptions, 0},
+		{"ambiguous EAW", "★★", 2, eawOptions, 2},
+		{"escape sequences", "\x1b[31mred\x1b[0m", 3, controlSequences, 0},
+		{"escape sequences as text", "\x1b[31mred\x1b[0m", 3, defaultOptions, 7},
+		{"tabs", "a\tb", 4, Options{TabWidth: 8}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.Overflow(tt.input, tt.maxWidth); got != tt.expected {
+				t.Errorf("Overflow(%q, %d) = %d, want %d", tt.input, tt.maxWidth, got, tt.expected)
+			}
+			wider := tt.expected > 0
+			if got := tt.options.WiderThan(tt.input, tt.maxWidth); got != wider {
+				t.Errorf("WiderThan(%q, %d) = %v, want %v", tt.input, tt.maxWidth, got, wider)
+			}
+		})
+	}
+
+	if got := Overflow("hello", 3); got != 2 {
+		t.Errorf("Overflow() = %d, want 2", got)
+	}
+	if !WiderThan("hello", 3) || WiderThan("hello", 5) {
+		t.Errorf("WiderThan() is inconsistent with String()")
+	}
+}
+
+func TestWiderThanMatchesString(t *testing.T) {
+	inputs := []string{
+		"", "hello", "中文字符", "a😀b", "éé", "🇺🇸🇯🇵",
+		"\x1b[31mhello\x1b[0m world", "mixed 中文 and ASCII text, long enough for the fast path",
+	}
+	options := []Options{defaultOptions, eawOptions, controlSequences, controlSequencesBoth}
+
+	for _, opt := range options {
+		for _, input := range inputs {
+			width := opt.String(input)
+			for maxWidth := -1; maxWidth <= width+1; maxWidth++ {
+				if got, want := opt.WiderThan(input, maxWidth), width > maxWidth; got != want {
+					t.Errorf("WiderThan(%q, %d) = %v, want %v", input, maxWidth, got, want)
+				}
+			}
+		}
+	}
+}
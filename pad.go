@@ -0,0 +1,41 @@
+package displaywidth
+
+import "strings"
+
+// Pad appends spaces to s until its display width reaches width, for
+// left-aligned columns. If s is already at least width wide, it is returned
+// unchanged; use [Options.TruncateString] to cut it.
+func (options Options) Pad(s string, width int) string {
+	n := width - options.String(s)
+	if n <= 0 {
+		return s
+	}
+	return s + strings.Repeat(" ", n)
+}
+
+// Pad appends spaces to s until its display width reaches width. See
+// [Options.Pad].
+func Pad(s string, width int) string {
+	return DefaultOptionsValue().Pad(s, width)
+}
+
+// PadLeft prepends spaces to s until its display width reaches width, for
+// right-aligned columns. If s is already at least width wide, it is returned
+// unchanged.
+//
+// With [Options.TabWidth], the spaces move the tab stops of s, so if s
+// contains tabs, the result may be wider or narrower than width. Expand tabs
+// before padding such text.
+func (options Options) PadLeft(s string, width int) string {
+	n := width - options.String(s)
+	if n <= 0 {
+		return s
+	}
+	return strings.Repeat(" ", n) + s
+}
+
+// PadLeft prepends spaces to s until its display width reaches width. See
+// [Options.PadLeft].
+func PadLeft(s string, width int) string {
+	return DefaultOptionsValue().PadLeft(s, width)
+}
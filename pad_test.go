@@ -0,0 +1,45 @@
+package displaywidth
+
+import "testing"
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		input   string
+		width   int
+		right   string
+		left    string
+	}{
+		{"ASCII", defaultOptions, "abc", 5, "abc  ", "  abc"},
+		{"CJK", defaultOptions, "中文", 6, "中文  ", "  中文"},
+		{"emoji", defaultOptions, "😀", 3, "😀 ", " 😀"},
+		{"combining", defaultOptions, "é", 3, "é  ", "  é"},
+		{"exact", defaultOptions, "中文", 4, "中文", "中文"},
+		{"wider", defaultOptions, "hello", 3, "hello", "hello"},
+		{"zero width", defaultOptions, "abc", 0, "abc", "abc"},
+		{"negative width", defaultOptions, "abc", -1, "abc", "abc"},
+		{"empty", defaultOptions, "", 2, "  ", "  "},
+		{"ambiguous", defaultOptions, "★", 2, "★ ", " ★"},
+		{"ambiguous EAW", eawOptions, "★", 2, "★", "★"},
+		{"escape sequence", controlSequences, "\x1b[31mab\x1b[0m", 4, "\x1b[31mab\x1b[0m  ", "  \x1b[31mab\x1b[0m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.Pad(tt.input, tt.width); got != tt.right {
+				t.Errorf("Pad(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.right)
+			}
+			if got := tt.options.PadLeft(tt.input, tt.width); got != tt.left {
+				t.Errorf("PadLeft(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.left)
+			}
+		})
+	}
+
+	if got := Pad("中", 3); got != "中 " {
+		t.Errorf("Pad() = %q, want %q", got, "中 ")
+	}
+	if got := PadLeft("中", 3); got != " 中" {
+		t.Errorf("PadLeft() = %q, want %q", got, " 中")
+	}
+}
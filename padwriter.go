@@ -0,0 +1,81 @@
+package displaywidth
+
+import "io"
+
+// PadWriter writes cells of text, each padded or truncated to an exact
+// display width, for aligned output such as table rows. Each cell is written
+// directly to W, without building an intermediate string.
+//
+// A PadWriter holds no state between cells, so each call to WriteCell is
+// independent.
+type PadWriter struct {
+	// W is the writer to which cells are written, such as a
+	// *strings.Builder.
+	W io.Writer
+	// Options are the options used to measure and truncate cells.
+	Options Options
+}
+
+// spaces is written in chunks for padding.
+const spaces = "                                "
+
+// WriteCell writes s to W, padded with spaces to exactly width columns, with
+// the given alignment. If s is wider than width, it is truncated at a
+// grapheme cluster boundary, as [Options.TruncateString] with no tail, and
+// padded if a wide character did not fit. If width is not positive, nothing
+// is written.
+//
+// It returns the number of bytes written, and any error from W.
+func (p PadWriter) WriteCell(s string, width int, align Align) (int, error) {
+	if width <= 0 {
+		return 0, nil
+	}
+
+	w := p.Options.String(s)
+	if w > width {
+		s = p.Options.TruncateString(s, width, "")
+		w = p.Options.String(s)
+	}
+
+	pad := width - w
+	left := 0
+	switch align {
+	case AlignRight:
+		left = pad
+	case AlignCenter:
+		left = pad / 2
+	}
+
+	total := 0
+	n, err := writeSpaces(p.W, left)
+	total += n
+	if err != nil {
+		return total, err
+	}
+	n, err = io.WriteString(p.W, s)
+	total += n
+	if err != nil {
+		return total, err
+	}
+	n, err = writeSpaces(p.W, pad-left)
+	total += n
+	return total, err
+}
+
+// writeSpaces writes n spaces to w.
+func writeSpaces(w io.Writer, n int) (int, error) {
+	total := 0
+	for n > 0 {
+		chunk := spaces
+		if n < len(chunk) {
+			chunk = chunk[:n]
+		}
+		written, err := io.WriteString(w, chunk)
+		total += written
+		if err != nil {
+			return total, err
+		}
+		n -= len(chunk)
+	}
+	return total, nil
+}
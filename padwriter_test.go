@@ -0,0 +1,140 @@
+package displaywidth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPadWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		width    int
+		align    Align
+		options  Options
+		expected string
+	}{
+		{"left", "ab", 5, AlignLeft, defaultOptions, "ab   "},
+		{"right", "ab", 5, AlignRight, defaultOptions, "   ab"},
+		{"center", "ab", 5, AlignCenter, defaultOptions, " ab  "},
+		{"exact", "abcde", 5, AlignLeft, defaultOptions, "abcde"},
+		{"empty", "", 3, AlignLeft, defaultOptions, "   "},
+		{"zero width", "abc", 0, AlignLeft, defaultOptions, ""},
+		{"truncated", "abcdefg", 5, AlignLeft, defaultOptions, "abcde"},
+		{"truncated right", "abcdefg", 5, AlignRight, defaultOptions, "abcde"},
+		{"CJK", "中文", 6, AlignRight, defaultOptions, "  中文"},
+		{"CJK truncated and padded", "中文字", 5, AlignLeft, defaultOptions, "中文 "},
+		{"emoji", "😀", 4, AlignCenter, defaultOptions, " 😀 "},
+		{"long padding", "a", 40, AlignRight, defaultOptions, strings.Repeat(" ", 39) + "a"},
+		{"escape sequences", "\x1b[31mab\x1b[0m", 4, AlignLeft, controlSequences, "\x1b[31mab\x1b[0m  "},
+		{"ambiguous EAW", "★", 3, AlignLeft, eawOptions, "★ "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			p := PadWriter{W: &b, Options: tt.options}
+			n, err := p.WriteCell(tt.input, tt.width, tt.align)
+			if err != nil {
+				t.Fatalf("WriteCell(%q) returned error: %v", tt.input, err)
+			}
+			if got := b.String(); got != tt.expected {
+				t.Errorf("WriteCell(%q, %d) wrote %q, want %q", tt.input, tt.width, got, tt.expected)
+			}
+			if n != b.Len() {
+				t.Errorf("WriteCell(%q) = %d, wrote %d bytes", tt.input, n, b.Len())
+			}
+			if tt.width > 0 {
+				if w := tt.options.String(b.String()); w != tt.width {
+					t.Errorf("WriteCell(%q) wrote width %d, want %d", tt.input, w, tt.width)
+				}
+			}
+		})
+	}
+}
+
+func TestPadWriterRow(t *testing.T) {
+	widths := []int{6, 4, 8}
+	aligns := []Align{AlignLeft, AlignRight, AlignCenter}
+	rows := [][]string{
+		{"名前", "年齢", "都市"},
+		{"Alice", "30", "Tokyo"},
+		{"José", "4", "São Paulo"},
+	}
+	expected := []string{
+		"名前  |年齢|  都市  ",
+		"Alice |  30| Tokyo  ",
+		"José  |   4|São Paul",
+	}
+
+	var b strings.Builder
+	p := PadWriter{W: &b}
+	for i, row := range rows {
+		b.Reset()
+		for j, cell := range row {
+			if j > 0 {
+				b.WriteByte('|')
+			}
+			if _, err := p.WriteCell(cell, widths[j], aligns[j]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := b.String(); got != expected[i] {
+			t.Errorf("row %d = %q, want %q", i, got, expected[i])
+		}
+	}
+}
+
+type errWriter struct{ n int }
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errors.New("short write")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestPadWriterError(t *testing.T) {
+	p := PadWriter{W: &errWriter{n: 3}}
+	n, err := p.WriteCell("abc", 6, AlignRight)
+	if err == nil {
+		t.Errorf("WriteCell() returned no error")
+	}
+	if n != 3 {
+		t.Errorf("WriteCell() = %d, want 3", n)
+	}
+}
+
+func BenchmarkPadWriter(b *testing.B) {
+	cells := []string{"Alice", "30", "Tokyo", "名前", "😀 ok", "a longer cell that is truncated"}
+	const width = 12
+
+	b.Run("WriteCell", func(b *testing.B) {
+		var sb strings.Builder
+		p := PadWriter{W: &sb}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sb.Reset()
+			for _, c := range cells {
+				_, _ = p.WriteCell(c, width, AlignLeft)
+			}
+		}
+	})
+
+	// Naive padding, which counts bytes rather than columns
+	b.Run("Sprintf", func(b *testing.B) {
+		var sb strings.Builder
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sb.Reset()
+			for _, c := range cells {
+				sb.WriteString(fmt.Sprintf("%-*s", width, c))
+			}
+		}
+	})
+}
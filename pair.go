@@ -0,0 +1,112 @@
+package displaywidth
+
+import (
+	"unicode/utf8"
+
+	"github.com/clipperhouse/uax29/v2/graphemes"
+)
+
+// BytesPair calculates the display width of the concatenation of a and b,
+// such as the two halves of a ring buffer, without joining them. See
+// [Options.BytesPair].
+func BytesPair(a, b []byte) int {
+	return DefaultOptionsValue().BytesPair(a, b)
+}
+
+// BytesPair calculates the display width of the concatenation of a and b,
+// for the given options, without joining them. It is equivalent to
+// Bytes(append(a, b...)), but copies only the region around the seam, where
+// a grapheme cluster, a UTF-8 rune or an escape sequence may straddle a and b.
+func (options Options) BytesPair(a, b []byte) int {
+	if cut := untilNUL(a, options); len(cut) < len(a) {
+		return options.Bytes(cut)
+	}
+	b = untilNUL(b, options)
+
+	if len(a) == 0 {
+		return options.Bytes(b)
+	}
+	if len(b) == 0 {
+		return options.Bytes(a)
+	}
+
+	seam := options.stableEnd(a)
+	width, state := widthFrom(a[:seam], contextState{}, options)
+	head := a[seam:]
+
+	// Grow the copied region into b until it contains a boundary that
+	// can't be affected by the bytes that follow.
+	for n := 16; ; n *= 2 {
+		if n > len(b) {
+			n = len(b)
+		}
+		joined := make([]byte, 0, len(head)+n)
+		joined = append(joined, head...)
+		joined = append(joined, b[:n]...)
+
+		if n == len(b) {
+			w, _ := widthFrom(joined, state, options)
+			return width + w
+		}
+
+		if cut := options.stableEnd(joined); cut >= len(head) {
+			w, s := widthFrom(joined[:cut], state, options)
+			rest, _ := widthFrom(b[cut-len(head):], s, options)
+			return width + w + rest
+		}
+	}
+}
+
+// stableEnd returns the largest offset in s that is a grapheme cluster
+// boundary regardless of the bytes that follow s.
+//
+// A grapheme cluster boundary depends only on the text before it and the
+// rune after it, so appending to s can only extend its last cluster. The
+// exceptions are an escape sequence that is incomplete at the end of s, which
+// appears as a lone introducer byte followed by ordinary clusters, and a
+// UTF-8 sequence that is incomplete at the end of a prefix of s, which
+// clusters differently when the prefix is measured on its own.
+func (options Options) stableEnd(s []byte) int {
+	escapes := options.ControlSequences || options.DECGraphics
+
+	g := graphemes.FromBytes(s)
+	g.AnsiEscapeSequences = escapes
+	g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+
+	last, lone := 0, -1
+	for g.Next() {
+		if !partialRuneEnd(s[:g.Start()]) {
+			last = g.Start()
+		}
+		v := g.Value()
+
+		introducer := (escapes && v[0] == 0x1B) || (options.ControlSequences8Bit && v[0] >= 0x80 && v[0] <= 0x9F)
+		switch {
+		case !introducer:
+		case len(v) == 1:
+			// Possibly the start of an incomplete escape sequence
+			if lone < 0 {
+				lone = last
+			}
+		default:
+			// A complete escape sequence ends any earlier one
+			lone = -1
+		}
+	}
+
+	if lone >= 0 && lone < last {
+		return lone
+	}
+	return last
+}
+
+// partialRuneEnd reports whether s ends with an incomplete UTF-8 sequence,
+// such as the first two bytes of a three-byte rune.
+func partialRuneEnd(s []byte) bool {
+	for i := len(s) - 1; i >= 0 && i > len(s)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(s[i]) {
+			return !utf8.FullRune(s[i:])
+		}
+	}
+	return false
+}
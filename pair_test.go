@@ -0,0 +1,105 @@
+package displaywidth
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestBytesPair(t *testing.T) {
+	inputs := []string{
+		"",
+		"hello world",
+		"中文字符",
+		"e\u0301e\u0301",
+		"a" + strings.Repeat("\u0301", 20) + "b", // cluster longer than the initial copy
+		"🇺🇸🇯🇵🇺",
+		"👨\u200D👩\u200D👧\u200D👦 family",
+		"❤\uFE0F heart",
+		"1\uFE0F\u20E3",
+		"한",
+		"a\r\nb",
+		"a\tb\tc",
+		"\x1b[31mred\x1b[0m",
+		"\x1b]0;a window title\x07text",
+		"\x1b]0;title\x1b\\text",
+		"\x1b[38;5;196mcolor\x1b[0m\x1b[1m",
+		"\x9b31mred\x9b0m",
+		"\x1b(0lqqk\x1b(B",
+		"x\x1b\ny\x1b[0m",
+		"\xff\xfe invalid \xe4\xb8",
+		"x\xe4\xb8\x00y",
+		"a\xf0\x9f\x98 b\xe4",
+		"中±中 ±a ±±",
+		"±中 中±± 中",
+	}
+	options := []Options{
+		defaultOptions,
+		eawOptions,
+		controlSequences,
+		controlSequences8Bit,
+		controlSequencesBoth,
+		{TabWidth: 4},
+		{DECGraphics: true},
+		{TabWidth: 8, ControlSequences: true},
+		{ContextualAmbiguous: true},
+		{AmbiguousInCJKContext: true},
+	}
+
+	for _, opt := range options {
+		for _, input := range inputs {
+			s := []byte(input)
+			want := opt.Bytes(s)
+			for i := 0; i <= len(s); i++ {
+				if got := opt.BytesPair(s[:i], s[i:]); got != want {
+					t.Errorf("BytesPair(%q, %q) with options %+v = %d, want %d", s[:i], s[i:], opt, got, want)
+				}
+			}
+		}
+	}
+
+	if got := BytesPair([]byte("中"[:2]), []byte("中"[2:]+"a")); got != 3 {
+		t.Errorf("BytesPair() split rune = %d, want 3", got)
+	}
+
+	// An incomplete rune before the seam clusters differently on its own
+	if got := BytesPair([]byte("x\xe4\xb8\x00"), []byte("y")); got != 3 {
+		t.Errorf("BytesPair() incomplete rune = %d, want 3", got)
+	}
+}
+
+// TestBytesPairDifferential compares BytesPair against Bytes of the joined
+// halves, for random text split at random offsets, including options that
+// carry context across the seam.
+func TestBytesPairDifferential(t *testing.T) {
+	pieces := []string{
+		"a", " ", "中", "±", "°", "α", "é", "e\u0301", "\t", "\n", "\r\n",
+		"😀", "🇺🇸", "❤\uFE0F", "\x1b[31m", "\x1b(0", "\x1b(B", "\xff", "\xe4\xb8", "\x00", "한",
+	}
+	options := []Options{
+		defaultOptions,
+		{ContextualAmbiguous: true},
+		{ContextualAmbiguous: true, TabWidth: 4},
+		{ContextualAmbiguous: true, ControlSequences: true},
+		{AmbiguousInCJKContext: true},
+		{AmbiguousInCJKContext: true, TabWidth: 4},
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		var buf bytes.Buffer
+		for n := rng.Intn(12); n >= 0; n-- {
+			buf.WriteString(pieces[rng.Intn(len(pieces))])
+		}
+		s := buf.Bytes()
+		split := rng.Intn(len(s) + 1)
+
+		for _, opt := range options {
+			want := opt.Bytes(s)
+			if got := opt.BytesPair(s[:split], s[split:]); got != want {
+				t.Fatalf("BytesPair(%q, %q) with options %+v = %d, want %d", s[:split], s[split:], opt, got, want)
+			}
+		}
+	}
+}
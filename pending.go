@@ -0,0 +1,81 @@
+package displaywidth
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// StringPending calculates the display width of the complete grapheme
+// clusters of s, and the byte length of a trailing cluster whose width may
+// still change as input arrives. See [Options.StringPending].
+func StringPending(s string) (stableWidth int, pendingBytes int) {
+	return DefaultOptionsValue().StringPending(s)
+}
+
+// StringPending calculates the display width of the complete grapheme
+// clusters of s, with the given options, and the byte length of a trailing
+// cluster whose width may still change as input arrives. It is intended for
+// incremental input, such as IME composition, where measuring the pending
+// cluster as final would make a live-updating widget flicker.
+//
+// The trailing cluster is pending if it:
+//   - ends with ZWJ (U+200D), awaiting the next emoji of a ZWJ sequence
+//   - is a lone regional indicator, awaiting its pair to form a flag
+//   - is a character that VS16 (U+FE0F) would widen, without a variation
+//     selector yet, such as U+263A
+//   - ends with an incomplete UTF-8 encoding
+//   - is a lone ESC, when ControlSequences is true
+//
+// Otherwise, pendingBytes is 0, and stableWidth is the width of s. A
+// combining mark may be appended to any cluster, but it does not change the
+// cluster's width.
+func (options Options) StringPending(s string) (stableWidth int, pendingBytes int) {
+	s = untilNUL(s, options)
+	if s == "" {
+		return 0, 0
+	}
+
+	g := options.StringGraphemes(s)
+	start := 0
+	for g.Next() {
+		start = g.Start()
+	}
+
+	if !options.isPending(s[start:]) {
+		return options.String(s), 0
+	}
+	return options.String(s[:start]), len(s) - start
+}
+
+// isPending reports whether the width of the trailing grapheme cluster v may
+// change as input is appended. See [Options.StringPending].
+func (options Options) isPending(v string) bool {
+	switch {
+	case strings.HasSuffix(v, zwj):
+		return true
+	case len(v) == 4 && isRegionalIndicator(v, 0):
+		return true
+	case endsIncomplete(v):
+		return true
+	case options.ControlSequences && v == "\x1b":
+		return true
+	}
+
+	// A text presentation character, which VS16 would widen
+	r, size := utf8.DecodeRuneInString(v)
+	if size == len(v) && r >= utf8.RuneSelf && LookupProperty(r).IsVS16Eligible() {
+		return true
+	}
+	return false
+}
+
+// endsIncomplete reports whether s ends with the beginning of a multi-byte
+// UTF-8 encoding, which may be completed by the bytes that follow.
+func endsIncomplete(s string) bool {
+	for i := len(s) - 1; i >= 0 && i >= len(s)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(s[i]) {
+			return !utf8.FullRuneInString(s[i:])
+		}
+	}
+	return false
+}
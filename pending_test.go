@@ -0,0 +1,53 @@
+package displaywidth
+
+import "testing"
+
+func TestStringPending(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		input   string
+		stable  int
+		pending int
+	}{
+		{"empty", defaultOptions, "", 0, 0},
+		{"ASCII", defaultOptions, "hello", 5, 0},
+		{"CJK", defaultOptions, "中文", 4, 0},
+		{"complete emoji", defaultOptions, "hi😀", 4, 0},
+		{"complete flag", defaultOptions, "🇺🇸", 2, 0},
+
+		{"lone regional indicator", defaultOptions, "ab\U0001F1FA", 2, 4},
+		{"flag, then lone regional indicator", defaultOptions, "🇺🇸\U0001F1EF", 2, 4},
+		{"trailing ZWJ", defaultOptions, "a👨‍", 1, 7},
+		{"ZWJ sequence awaiting more", defaultOptions, "👨‍👩‍", 0, 14},
+		{"complete ZWJ sequence", defaultOptions, "👨‍👩", 2, 0},
+		{"text presentation awaiting VS16", defaultOptions, "a☺", 1, 3},
+		{"text presentation with VS16", defaultOptions, "a☺️", 3, 0},
+		{"text presentation with VS15", defaultOptions, "a☺︎", 2, 0},
+		// The segmenter joins the incomplete encoding to the preceding character
+		{"incomplete UTF-8", defaultOptions, "a\xE4\xB8", 0, 3},
+		{"incomplete 4-byte UTF-8", defaultOptions, "ab\xF0\x9F", 1, 3},
+		{"invalid byte is complete", defaultOptions, "a\xFF", 2, 0},
+		{"lone ESC", controlSequences, "hi\x1b", 2, 1},
+		{"lone ESC without ControlSequences", defaultOptions, "hi\x1b", 2, 0},
+		{"complete escape", controlSequences, "hi\x1b[0m", 2, 0},
+		{"keycap digit is not pending", defaultOptions, "12", 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stable, pending := tt.options.StringPending(tt.input)
+			if stable != tt.stable || pending != tt.pending {
+				t.Errorf("StringPending(%q) = (%d, %d), want (%d, %d)", tt.input, stable, pending, tt.stable, tt.pending)
+			}
+			// The stable width is that of the complete prefix
+			if want := tt.options.String(tt.input[:len(tt.input)-pending]); stable != want {
+				t.Errorf("StringPending(%q) stable width = %d, String() of prefix = %d", tt.input, stable, want)
+			}
+		})
+	}
+
+	if stable, pending := StringPending("🇺"); stable != 0 || pending != 4 {
+		t.Errorf("StringPending() = (%d, %d), want (0, 4)", stable, pending)
+	}
+}
@@ -0,0 +1,66 @@
+package displaywidth
+
+import "unicode/utf8"
+
+// CheckPrintable calculates the display width of s, and reports the byte
+// offset of the first control character in s, or -1 if there is none. See
+// [Options.CheckPrintable].
+func CheckPrintable(s string, allow ...rune) (width int, firstControl int) {
+	return DefaultOptionsValue().CheckPrintable(s, allow...)
+}
+
+// CheckPrintable calculates the display width of s, for the given options,
+// and reports the byte offset of the first control character in s, or -1 if
+// there is none. It is intended for strict validation of untrusted text
+// before writing it to a terminal, where control characters could move the
+// cursor or inject escape sequences.
+//
+// Control characters are C0 (U+0000-U+001F), DEL (U+007F) and C1
+// (U+0080-U+009F). ESC is reported even when [Options.ControlSequences] is
+// true, as are raw 8-bit C1 bytes when [Options.ControlSequences8Bit] is
+// true.
+//
+// Control characters in allow are permitted, and not reported, such as '\t'
+// and '\n' for text that may span lines:
+//
+//	width, firstControl := CheckPrintable(s, '\t', '\n')
+//
+// The width is that of [Options.String], regardless of control characters.
+func (options Options) CheckPrintable(s string, allow ...rune) (width int, firstControl int) {
+	return options.String(s), options.firstControl(s, allow)
+}
+
+// firstControl returns the byte offset of the first control character in s
+// that is not in allow, or -1. See [Options.CheckPrintable].
+func (options Options) firstControl(s string, allow []rune) int {
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if asciiWidth(b) == 0 && !allowed(rune(b), allow) {
+				return i
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r >= 0x80 && r <= 0x9F && !allowed(r, allow) {
+			return i
+		}
+		if r == utf8.RuneError && size == 1 && options.ControlSequences8Bit && b >= 0x80 && b <= 0x9F && !allowed(rune(b), allow) {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
+
+// allowed reports whether r is in allow.
+func allowed(r rune, allow []rune) bool {
+	for _, a := range allow {
+		if r == a {
+			return true
+		}
+	}
+	return false
+}
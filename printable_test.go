@@ -0,0 +1,58 @@
+package displaywidth
+
+import "testing"
+
+func TestCheckPrintable(t *testing.T) {
+	tabNewline := []rune{'\t', '\n'}
+
+	tests := []struct {
+		name         string
+		options      Options
+		input        string
+		allow        []rune
+		width        int
+		firstControl int
+	}{
+		{"empty", defaultOptions, "", nil, 0, -1},
+		{"ASCII", defaultOptions, "hello", nil, 5, -1},
+		{"CJK and emoji", defaultOptions, "中文😀", nil, 6, -1},
+		{"combining", defaultOptions, "é", nil, 1, -1},
+		{"ZWJ and VS16", defaultOptions, "👩‍💻 ☺️", nil, 5, -1},
+
+		{"ESC", defaultOptions, "ab\x1b[2Jcd", nil, 7, 2},
+		{"ESC with ControlSequences", controlSequences, "ab\x1b[2Jcd", nil, 4, 2},
+		{"cursor movement", controlSequences, "\x1b[10;10H", nil, 0, 0},
+		{"BEL", defaultOptions, "ding\a", nil, 4, 4},
+		{"NUL", defaultOptions, "a\x00", nil, 1, 1},
+		{"DEL", defaultOptions, "abc\x7f", nil, 3, 3},
+		{"CR", defaultOptions, "safe\roverwrite", nil, 13, 4},
+		{"CR allowing tab and newline", defaultOptions, "safe\roverwrite", tabNewline, 13, 4},
+		{"C1 CSI as UTF-8", defaultOptions, "中\u009B2J", nil, 4, 3},
+		{"C1 NEL as UTF-8", defaultOptions, "a\u0085", nil, 1, 1},
+		{"raw 8-bit CSI", defaultOptions, "a\x9B2J", nil, 4, -1},
+		{"raw 8-bit CSI with ControlSequences8Bit", controlSequences8Bit, "a\x9B2J", nil, 1, 1},
+		{"UTF-8 continuation is not C1", controlSequences8Bit, "Ĝ", nil, 1, -1},
+
+		{"tab", defaultOptions, "a\tb", nil, 2, 1},
+		{"newline", defaultOptions, "a\nb", nil, 2, 1},
+		{"tab allowing tab and newline", defaultOptions, "a\tb", tabNewline, 2, -1},
+		{"newline allowing tab and newline", defaultOptions, "a\nb", tabNewline, 2, -1},
+		{"tab, then ESC allowing tab and newline", defaultOptions, "a\tb\x1b", tabNewline, 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, firstControl := tt.options.CheckPrintable(tt.input, tt.allow...)
+			if width != tt.width || firstControl != tt.firstControl {
+				t.Errorf("CheckPrintable(%q) = (%d, %d), want (%d, %d)", tt.input, width, firstControl, tt.width, tt.firstControl)
+			}
+		})
+	}
+
+	if width, firstControl := CheckPrintable("hi\x1b"); width != 2 || firstControl != 2 {
+		t.Errorf("CheckPrintable() = (%d, %d), want (2, 2)", width, firstControl)
+	}
+	if width, firstControl := CheckPrintable("a\u0085b", 0x85); width != 2 || firstControl != -1 {
+		t.Errorf("CheckPrintable() allowing NEL = (%d, %d), want (2, -1)", width, firstControl)
+	}
+}
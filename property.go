@@ -0,0 +1,138 @@
+package displaywidth
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Property is a set of character properties relevant to display width, as
+// returned by [LookupProperty]. It is intended for advanced users who want to
+// query the classification of a character directly. Use [Property.Has] or the
+// Is methods to test for individual properties.
+//
+// The width properties (PropertyZeroWidth, PropertyWide,
+// PropertyEastAsianAmbiguous and PropertyVS16Eligible) are those used by the
+// width calculation. The others are informational.
+//
+// The values of the constants are stable.
+type Property uint8
+
+const (
+	// PropertyZeroWidth is always width 0, such as combining marks, control
+	// characters, and format characters.
+	PropertyZeroWidth = Property(_Zero_Width)
+	// PropertyWide is always width 2, such as East Asian Wide (W) and
+	// Fullwidth (F) characters, emoji with default emoji presentation, and
+	// regional indicators.
+	PropertyWide = Property(_Wide)
+	// PropertyEastAsianAmbiguous is East Asian Ambiguous (A), which is
+	// width 1, or width 2 when the EastAsianWidth option is true.
+	PropertyEastAsianAmbiguous = Property(_East_Asian_Ambiguous)
+	// PropertyVS16Eligible is width 1, but width 2 when followed by VS16
+	// (U+FE0F), which requests emoji presentation.
+	PropertyVS16Eligible = Property(_VS16_Eligible)
+
+	// PropertyEmoji is Extended_Pictographic or a regional indicator, i.e.
+	// a character that may begin an emoji grapheme cluster. It does not
+	// imply width 2 on its own; see PropertyWide and PropertyVS16Eligible.
+	PropertyEmoji Property = 1 << 4
+	// PropertyCombining is a combining mark, in the general categories
+	// Mn (Nonspacing_Mark) or Me (Enclosing_Mark). Combining marks are
+	// also PropertyZeroWidth.
+	PropertyCombining Property = 1 << 5
+)
+
+// LookupProperty returns the properties of a rune. Invalid runes have no
+// properties.
+//
+// The smallest unit of display width is a grapheme cluster, not a rune, so
+// the properties of a single rune do not determine the width of a string.
+func LookupProperty(r rune) Property {
+	if !utf8.ValidRune(r) {
+		return 0
+	}
+	if r < utf8.RuneSelf {
+		if asciiWidth(byte(r)) == 0 {
+			return PropertyZeroWidth
+		}
+		return 0
+	}
+
+	var buf [4]byte
+	n := utf8.EncodeRune(buf[:], r)
+	p, _ := lookup(buf[:n])
+	prop := Property(p)
+
+	if unicode.Is(extendedPictographic, r) || (r >= 0x1F1E6 && r <= 0x1F1FF) {
+		prop |= PropertyEmoji
+	}
+	if unicode.In(r, unicode.Mn, unicode.Me) {
+		prop |= PropertyCombining
+	}
+	return prop
+}
+
+// Has reports whether p includes all of the properties in flag.
+func (p Property) Has(flag Property) bool {
+	return p&flag == flag
+}
+
+// IsZeroWidth reports whether p includes PropertyZeroWidth.
+func (p Property) IsZeroWidth() bool {
+	return p.Has(PropertyZeroWidth)
+}
+
+// IsWide reports whether p includes PropertyWide.
+func (p Property) IsWide() bool {
+	return p.Has(PropertyWide)
+}
+
+// IsAmbiguous reports whether p includes PropertyEastAsianAmbiguous.
+func (p Property) IsAmbiguous() bool {
+	return p.Has(PropertyEastAsianAmbiguous)
+}
+
+// IsVS16Eligible reports whether p includes PropertyVS16Eligible.
+func (p Property) IsVS16Eligible() bool {
+	return p.Has(PropertyVS16Eligible)
+}
+
+// IsEmoji reports whether p includes PropertyEmoji.
+func (p Property) IsEmoji() bool {
+	return p.Has(PropertyEmoji)
+}
+
+// IsCombining reports whether p includes PropertyCombining.
+func (p Property) IsCombining() bool {
+	return p.Has(PropertyCombining)
+}
+
+// RangeRuneWidths groups the runes from lo to hi inclusive by their width.
+// See [Options.RangeRuneWidths].
+func RangeRuneWidths(lo, hi rune) map[int][]rune {
+	return DefaultOptionsValue().RangeRuneWidths(lo, hi)
+}
+
+// RangeRuneWidths groups the runes from lo to hi inclusive by their width,
+// as measured by [Options.Rune], in ascending order. It is a developer tool,
+// for coverage reports and for validating the width tables against
+// expectations; the length of each slice is a histogram of the range.
+//
+// Invalid runes, such as surrogates, are skipped.
+func (options Options) RangeRuneWidths(lo, hi rune) map[int][]rune {
+	widths := make(map[int][]rune)
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > unicode.MaxRune {
+		hi = unicode.MaxRune
+	}
+	for r := lo; r <= hi; r++ {
+		if !utf8.ValidRune(r) {
+			continue
+		}
+		w := options.Rune(r)
+		widths[w] = append(widths[w], r)
+	}
+	return widths
+}
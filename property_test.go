@@ -0,0 +1,183 @@
+package displaywidth
+
+import (
+	"testing"
+	"unicode"
+)
+
+func TestPropertyValues(t *testing.T) {
+	// The exported values are stable, and the width properties match the
+	// trie's internal properties.
+	tests := []struct {
+		name     string
+		got      Property
+		internal property
+		expected Property
+	}{
+		{"PropertyZeroWidth", PropertyZeroWidth, _Zero_Width, 1},
+		{"PropertyWide", PropertyWide, _Wide, 2},
+		{"PropertyEastAsianAmbiguous", PropertyEastAsianAmbiguous, _East_Asian_Ambiguous, 4},
+		{"PropertyVS16Eligible", PropertyVS16Eligible, _VS16_Eligible, 8},
+		{"PropertyEmoji", PropertyEmoji, 0, 16},
+		{"PropertyCombining", PropertyCombining, 0, 32},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.expected)
+		}
+		if tt.internal != 0 && Property(tt.internal) != tt.got {
+			t.Errorf("%s = %d, internal property is %d", tt.name, tt.got, tt.internal)
+		}
+	}
+}
+
+func TestLookupProperty(t *testing.T) {
+	tests := []struct {
+		name     string
+		r        rune
+		expected Property
+	}{
+		{"ASCII", 'a', 0},
+		{"space", ' ', 0},
+		{"control", '\x07', PropertyZeroWidth},
+		{"DEL", '\x7F', PropertyZeroWidth},
+		{"CJK", '中', PropertyWide},
+		{"fullwidth", 'Ａ', PropertyWide},
+		{"ambiguous", '§', PropertyEastAsianAmbiguous},
+		{"ambiguous emoji", '♠', PropertyEastAsianAmbiguous | PropertyVS16Eligible | PropertyEmoji},
+		{"ambiguous Greek", 'α', PropertyEastAsianAmbiguous},
+		{"emoji", '😀', PropertyWide | PropertyEmoji},
+		{"text presentation emoji", '☺', PropertyVS16Eligible | PropertyEmoji},
+		{"regional indicator", '\U0001F1FA', PropertyWide | PropertyEmoji},
+		{"combining acute", '\u0301', PropertyZeroWidth | PropertyCombining},
+		{"enclosing circle", '\u20DD', PropertyZeroWidth | PropertyCombining},
+		{"zero width space", '\u200B', PropertyZeroWidth},
+		{"spacing mark", '\u0903', 0},
+		{"surrogate", 0xD800, 0},
+		{"out of range", 0x110000, 0},
+		{"negative", -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LookupProperty(tt.r); got != tt.expected {
+				t.Errorf("LookupProperty(%U) = %06b, want %06b", tt.r, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPropertyMethods(t *testing.T) {
+	p := PropertyZeroWidth | PropertyCombining
+
+	if !p.Has(PropertyZeroWidth) || !p.Has(PropertyZeroWidth|PropertyCombining) {
+		t.Errorf("Has() = false, want true")
+	}
+	if p.Has(PropertyWide) || p.Has(PropertyZeroWidth|PropertyWide) {
+		t.Errorf("Has() = true, want false")
+	}
+
+	methods := []struct {
+		name string
+		fn   func(Property) bool
+		flag Property
+	}{
+		{"IsZeroWidth", Property.IsZeroWidth, PropertyZeroWidth},
+		{"IsWide", Property.IsWide, PropertyWide},
+		{"IsAmbiguous", Property.IsAmbiguous, PropertyEastAsianAmbiguous},
+		{"IsVS16Eligible", Property.IsVS16Eligible, PropertyVS16Eligible},
+		{"IsEmoji", Property.IsEmoji, PropertyEmoji},
+		{"IsCombining", Property.IsCombining, PropertyCombining},
+	}
+
+	for _, m := range methods {
+		if !m.fn(m.flag) {
+			t.Errorf("%s(%06b) = false, want true", m.name, m.flag)
+		}
+		if m.fn(^m.flag) {
+			t.Errorf("%s(%06b) = true, want false", m.name, ^m.flag)
+		}
+	}
+}
+
+func TestLookupPropertyMatchesRune(t *testing.T) {
+	// The width properties agree with the width of a lone rune
+	for r := rune(0); r <= 0x1FFFF; r++ {
+		if r >= 0xD800 && r <= 0xDFFF {
+			continue
+		}
+		p := LookupProperty(r)
+		w := DefaultOptions.Rune(r)
+
+		var expected int
+		switch {
+		case p.IsZeroWidth():
+			expected = 0
+		case p.IsWide():
+			expected = 2
+		default:
+			expected = 1
+		}
+		if w != expected {
+			t.Fatalf("LookupProperty(%U) = %06b, but Rune() = %d", r, p, w)
+		}
+	}
+}
+
+func TestRangeRuneWidths(t *testing.T) {
+	tests := []struct {
+		name     string
+		lo, hi   rune
+		expected map[int]int // width to count
+	}{
+		{"CJK Unified Ideographs", 0x4E00, 0x9FFF, map[int]int{2: 0x9FFF - 0x4E00 + 1}},
+		{"Hangul Syllables", 0xAC00, 0xD7A3, map[int]int{2: 0xD7A3 - 0xAC00 + 1}},
+		{"combining diacritics", 0x0300, 0x036F, map[int]int{0: 0x036F - 0x0300 + 1}},
+		{"printable ASCII", 0x20, 0x7E, map[int]int{1: 0x7E - 0x20 + 1}},
+		{"C0 controls", 0x00, 0x1F, map[int]int{0: 0x20}},
+		{"ASCII", 0x00, 0x7F, map[int]int{0: 0x21, 1: 0x5F}},
+		{"surrogates", 0xD800, 0xDFFF, map[int]int{}},
+		{"around surrogates", 0xD7FF, 0xE000, map[int]int{1: 2}},
+		{"empty range", 0x100, 0xFF, map[int]int{}},
+		{"beyond MaxRune", unicode.MaxRune, unicode.MaxRune + 10, map[int]int{1: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RangeRuneWidths(tt.lo, tt.hi)
+			if len(got) != len(tt.expected) {
+				t.Errorf("RangeRuneWidths(%U, %U) has widths %v, want %v", tt.lo, tt.hi, keys(got), tt.expected)
+			}
+			for w, n := range tt.expected {
+				if len(got[w]) != n {
+					t.Errorf("RangeRuneWidths(%U, %U)[%d] has %d runes, want %d", tt.lo, tt.hi, w, len(got[w]), n)
+				}
+			}
+			for w, runes := range got {
+				for i, r := range runes {
+					if Rune(r) != w {
+						t.Errorf("RangeRuneWidths(%U, %U)[%d] contains %U of width %d", tt.lo, tt.hi, w, r, Rune(r))
+					}
+					if i > 0 && r <= runes[i-1] {
+						t.Errorf("RangeRuneWidths(%U, %U)[%d] is not ascending at %U", tt.lo, tt.hi, w, r)
+					}
+				}
+			}
+		})
+	}
+
+	// Ambiguous characters move with EastAsianWidth
+	got := Options{EastAsianWidth: true}.RangeRuneWidths(0x2460, 0x24E9) // circled numbers and letters
+	if len(got[2]) != 0x24E9-0x2460+1 {
+		t.Errorf("RangeRuneWidths() with EastAsianWidth has %d runes of width 2, want %d", len(got[2]), 0x24E9-0x2460+1)
+	}
+}
+
+func keys(m map[int][]rune) []int {
+	var k []int
+	for w := range m {
+		k = append(k, w)
+	}
+	return k
+}
@@ -0,0 +1,45 @@
+package displaywidth
+
+import "strings"
+
+// RepeatToWidth repeats s until the display width of the result reaches
+// width, for example to draw a horizontal rule or border from a multi-cell
+// glyph.
+//
+// If the width of s does not evenly divide width, the final repetition is
+// truncated at a grapheme cluster boundary. When the next grapheme cluster is
+// wide and would overshoot, it is omitted, so the result may be one column
+// narrower than width. The result is never wider than width.
+//
+// It returns an empty string if width is not positive, or if s has zero width.
+func (options Options) RepeatToWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	unit := options.String(s)
+	if unit == 0 {
+		return ""
+	}
+
+	n := width / unit
+	rem := width - n*unit
+
+	var b strings.Builder
+	b.Grow(len(s) * (n + 1))
+	for i := 0; i < n; i++ {
+		b.WriteString(s)
+	}
+	if rem > 0 {
+		b.WriteString(options.TruncateString(s, rem, ""))
+	}
+	return b.String()
+}
+
+// RepeatToWidth repeats s until the display width of the result reaches
+// width, truncating the final repetition at a grapheme cluster boundary.
+//
+// The result may be one column narrower than width, when a wide grapheme
+// cluster would overshoot. See [Options.RepeatToWidth].
+func RepeatToWidth(s string, width int) string {
+	return DefaultOptionsValue().RepeatToWidth(s, width)
+}
@@ -0,0 +1,65 @@
+package displaywidth
+
+import "testing"
+
+func TestRepeatToWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		width    int
+		options  Options
+		expected string
+	}{
+		{"empty input", "", 5, defaultOptions, ""},
+		{"zero width", "-", 0, defaultOptions, ""},
+		{"negative width", "-", -1, defaultOptions, ""},
+		{"zero-width unit", "\u200b", 5, defaultOptions, ""},
+
+		{"narrow unit", "-", 5, defaultOptions, "-----"},
+		{"narrow multi-char unit", "-=", 5, defaultOptions, "-=-=-"},
+
+		// Width-2 unit divides evenly
+		{"ambiguous unit", "═", 4, defaultOptions, "════"}, // U+2550 is ambiguous, width 1 by default
+		{"CJK unit even", "中", 6, defaultOptions, "中中中"},
+		{"emoji unit even", "😀", 4, defaultOptions, "😀😀"},
+
+		// Width-2 unit does not divide evenly: one column short
+		{"CJK unit odd", "中", 5, defaultOptions, "中中"},
+		{"emoji unit odd", "😀", 3, defaultOptions, "😀"},
+		{"CJK unit too wide", "中", 1, defaultOptions, ""},
+
+		// Mixed unit: final repetition truncated at a grapheme boundary
+		{"mixed unit", "a中", 7, defaultOptions, "a中a中a"},
+		{"mixed unit wide overshoot", "中a", 5, defaultOptions, "中a中"},
+
+		// Ambiguous unit under EastAsianWidth
+		{"ambiguous unit EAW", "═", 5, eawOptions, "══"},
+
+		// Grapheme clusters are never split
+		{"combining unit", "é", 3, defaultOptions, "ééé"},
+		{"flag unit", "🇺🇸", 5, defaultOptions, "🇺🇸🇺🇸"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.RepeatToWidth(tt.input, tt.width)
+			if got != tt.expected {
+				t.Errorf("RepeatToWidth(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.expected)
+			}
+
+			w := tt.options.String(got)
+			if w > tt.width && tt.width > 0 {
+				t.Errorf("RepeatToWidth(%q, %d) has width %d, want <= %d", tt.input, tt.width, w, tt.width)
+			}
+			if tt.options.String(tt.input) > 0 && tt.width > 0 && w < tt.width-1 {
+				t.Errorf("RepeatToWidth(%q, %d) has width %d, want >= %d", tt.input, tt.width, w, tt.width-1)
+			}
+		})
+	}
+}
+
+func TestRepeatToWidthDefault(t *testing.T) {
+	if got := RepeatToWidth("─", 3); got != "───" {
+		t.Errorf("RepeatToWidth(%q, 3) = %q, want %q", "─", got, "───")
+	}
+}
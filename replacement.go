@@ -0,0 +1,83 @@
+package displaywidth
+
+import "unicode/utf8"
+
+// ReplacementWidth calculates the display width of s as a terminal would
+// show it after replacing invalid UTF-8 with U+FFFD. See
+// [Options.ReplacementWidth].
+func ReplacementWidth(s []byte) int {
+	return DefaultOptionsValue().ReplacementWidth(s)
+}
+
+// ReplacementWidth calculates the display width of s, for the given
+// options, as a terminal would show it after replacing invalid UTF-8 with
+// U+FFFD, per the "substitution of maximal subparts" practice of the Unicode
+// Standard (section 3.9). Each maximal subpart of an ill-formed sequence,
+// such as a truncated multi-byte rune, becomes a single U+FFFD of width 1.
+//
+// The two agree for valid UTF-8. For invalid UTF-8, [Options.Bytes] counts
+// width 1 for each grapheme cluster of invalid bytes, as segmented by UAX
+// #29, which does not follow the replacement practice: it may merge an
+// ill-formed sequence, such as an encoded surrogate, into one cluster, or
+// split a truncated sequence into one cluster per byte.
+func (options Options) ReplacementWidth(s []byte) int {
+	if utf8.Valid(s) {
+		return options.Bytes(s)
+	}
+
+	// Replace each maximal subpart with a single invalid byte, which is
+	// width 1 regardless of options such as EastAsianWidth.
+	buf := make([]byte, 0, len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRune(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			size = maximalSubpart(s[i:])
+			buf = append(buf, 0xFF)
+		} else {
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return options.Bytes(buf)
+}
+
+// maximalSubpart returns the length of the maximal subpart at the start of
+// s, which begins with an ill-formed sequence: the longest prefix of a
+// well-formed sequence, or 1 if there is none. See Table 3-7 of the Unicode
+// Standard.
+func maximalSubpart(s []byte) int {
+	b := s[0]
+
+	// The range of the second byte, and the number of continuation bytes
+	lo, hi, need := byte(0x80), byte(0xBF), 0
+	switch {
+	case b >= 0xC2 && b <= 0xDF:
+		need = 1
+	case b == 0xE0:
+		lo, need = 0xA0, 2
+	case b == 0xED:
+		hi, need = 0x9F, 2
+	case b >= 0xE1 && b <= 0xEF:
+		need = 2
+	case b == 0xF0:
+		lo, need = 0x90, 3
+	case b == 0xF4:
+		hi, need = 0x8F, 3
+	case b >= 0xF1 && b <= 0xF3:
+		need = 3
+	default:
+		return 1
+	}
+
+	n := 1
+	for ; n <= need && n < len(s); n++ {
+		c := s[n]
+		if n > 1 {
+			lo, hi = 0x80, 0xBF
+		}
+		if c < lo || c > hi {
+			break
+		}
+	}
+	return n
+}
@@ -0,0 +1,109 @@
+package displaywidth
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/clipperhouse/displaywidth/testdata"
+)
+
+func TestReplacementWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"valid", "hello 中文", 10},
+		{"empty", "", 0},
+		{"lone continuation", "\x80", 1},
+		{"two continuations", "\x80\xBF", 2},
+		{"truncated 2-byte", "a\xC3", 2},
+		{"truncated 3-byte", "a\xE4\xB8", 2},
+		{"truncated 4-byte", "a\xF0\x9F\x98", 2},
+		{"truncated 4-byte before ASCII", "\xF0\x9F\x98a", 2},
+		{"truncated 3-byte before CJK", "\xE4\xB8中", 3},
+		{"overlong", "\xC0\xAF", 2},
+		{"surrogate", "\xED\xA0\x80", 3},
+		{"out of range", "\xF4\x90\x80\x80", 4},
+		{"invalid lead", "\xF5\x80", 2},
+		{"FF", "\xFF", 1},
+
+		// Tables 3-8 through 3-11 of the Unicode Standard, section 3.9
+		{"table 3-8", "\x61\xF1\x80\x80\xE1\x80\xC2\x62\x80\x63\x80\xBF\x64", 10},
+		{"table 3-9 non-shortest forms", "\xC0\xAF\xE0\x80\xBF\xF0\x81\x82\x41", 9},
+		{"table 3-10 surrogates", "\xED\xA0\x80\xED\xBF\xBF\xED\xAF\x41", 9},
+		{"table 3-11 out of range", "\xF4\x91\x92\x93\xFF\x41\x80\xBF\x42", 9},
+		{"table 3-11 truncated", "\xE1\x80\xE2\xF0\x91\x92\xF1\xBF\x41", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReplacementWidth([]byte(tt.input)); got != tt.expected {
+				t.Errorf("ReplacementWidth(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			// Replacement is width 1 regardless of options
+			if got := eawOptions.ReplacementWidth([]byte(tt.input)); got != tt.expected {
+				t.Errorf("ReplacementWidth(%q) with EastAsianWidth = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReplacementWidthCorpus(t *testing.T) {
+	invalid, err := testdata.InvalidUTF8()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var differ int
+	for _, line := range bytes.Split(invalid, []byte("\n")) {
+		got := ReplacementWidth(line)
+		if want := Bytes(replaceMaximalSubparts(line)); got != want {
+			t.Errorf("ReplacementWidth(%q) = %d, want %d", line, got, want)
+		}
+		if b := Bytes(line); got != b {
+			if utf8.Valid(line) {
+				t.Errorf("ReplacementWidth(%q) = %d, Bytes() = %d, want equal for valid UTF-8", line, got, b)
+			}
+			differ++
+		}
+	}
+	if differ == 0 {
+		t.Errorf("ReplacementWidth() never differs from Bytes() over the corpus")
+	}
+}
+
+// replaceMaximalSubparts is a reference implementation, which replaces each
+// maximal subpart with U+FFFD. A prefix of an ill-formed sequence is part of
+// a maximal subpart if it can be completed to a valid rune by continuation
+// bytes.
+func replaceMaximalSubparts(s []byte) []byte {
+	var out []byte
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRune(s[i:])
+		if r != utf8.RuneError || size != 1 {
+			out = append(out, s[i:i+size]...)
+			i += size
+			continue
+		}
+
+		n := 1
+	prefixes:
+		for m := 3; m > 1; m-- {
+			if i+m > len(s) {
+				continue
+			}
+			for k := 1; m+k <= utf8.UTFMax; k++ {
+				candidate := append(append([]byte{}, s[i:i+m]...), bytes.Repeat([]byte{0x80}, k)...)
+				if r, size := utf8.DecodeRune(candidate); r != utf8.RuneError && size == len(candidate) {
+					n = m
+					break prefixes
+				}
+			}
+		}
+		out = append(out, "�"...)
+		i += n
+	}
+	return out
+}
@@ -0,0 +1,36 @@
+package displaywidth
+
+import "strings"
+
+// ReverseString reverses the order of the grapheme clusters of s. See
+// [Options.ReverseString].
+func ReverseString(s string) string {
+	return DefaultOptionsValue().ReverseString(s)
+}
+
+// ReverseString reverses the order of the grapheme clusters of s, for
+// display effects such as mirrored text. Unlike reversing bytes or runes,
+// it keeps each cluster intact, so a letter with combining marks, an emoji
+// ZWJ sequence or a flag remains readable.
+//
+// Clusters are segmented as [Options.StringGraphemes] segments them, so when
+// ControlSequences is true, each escape sequence is kept whole, although its
+// effect then applies to different text. The width of the result equals the
+// width of s, except for options that depend on context, such as TabWidth
+// and ContextualAmbiguous.
+func (options Options) ReverseString(s string) string {
+	var starts []int
+	g := options.StringGraphemes(s)
+	for g.Next() {
+		starts = append(starts, g.Start())
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	end := len(s)
+	for i := len(starts) - 1; i >= 0; i-- {
+		b.WriteString(s[starts[i]:end])
+		end = starts[i]
+	}
+	return b.String()
+}
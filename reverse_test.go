@@ -0,0 +1,57 @@
+package displaywidth
+
+import "testing"
+
+func TestReverseString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  Options
+		expected string
+	}{
+		{"empty", "", defaultOptions, ""},
+		{"single", "a", defaultOptions, "a"},
+		{"ASCII", "hello", defaultOptions, "olleh"},
+		{"CJK", "中文字", defaultOptions, "字文中"},
+
+		// Combining marks stay with their base
+		{"combining", "cafe\u0301", defaultOptions, "e\u0301fac"},
+		{"multiple combining", "a\u0323\u0301b", defaultOptions, "ba\u0323\u0301"},
+
+		// Emoji sequences stay intact
+		{"emoji", "a😀b", defaultOptions, "b😀a"},
+		{"ZWJ sequence", "👨\u200d👩\u200d👧!", defaultOptions, "!👨\u200d👩\u200d👧"},
+		{"flags", "🇺🇸🇯🇵", defaultOptions, "🇯🇵🇺🇸"},
+		{"skin tone", "👋🏽x", defaultOptions, "x👋🏽"},
+		{"VS16", "❤\uFE0Fa", defaultOptions, "a❤\uFE0F"},
+		{"keycap", "1\uFE0F\u20E32", defaultOptions, "21\uFE0F\u20E3"},
+
+		// CRLF is a single cluster
+		{"CRLF", "a\r\nb", defaultOptions, "b\r\na"},
+
+		// Escape sequences are kept whole when ControlSequences is true
+		{"escape", "\x1b[31mab", controlSequences, "ba\x1b[31m"},
+		{"escape default", "\x1b[31m", defaultOptions, "m13[\x1b"},
+
+		{"invalid UTF-8", "a\xffb", defaultOptions, "b\xffa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.ReverseString(tt.input)
+			if got != tt.expected {
+				t.Errorf("ReverseString(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+			if w, want := tt.options.String(got), tt.options.String(tt.input); w != want {
+				t.Errorf("ReverseString(%q) has width %d, want %d", tt.input, w, want)
+			}
+			if back := tt.options.ReverseString(got); back != tt.input {
+				t.Errorf("ReverseString(ReverseString(%q)) = %q, want the input", tt.input, back)
+			}
+		})
+	}
+
+	if got := ReverseString("ab中"); got != "中ba" {
+		t.Errorf("ReverseString() = %q, want %q", got, "中ba")
+	}
+}
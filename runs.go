@@ -0,0 +1,103 @@
+package displaywidth
+
+// Runs is an iterator over runs of consecutive grapheme clusters of equal
+// width. It is useful for renderers that advance the cursor by a run at a
+// time.
+//
+// Iterate using the Next method. Each run spans Count grapheme clusters, each
+// of the same Width, so the run occupies Count × Width columns.
+type Runs[T ~string | ~[]byte] struct {
+	s T
+	g Graphemes[T]
+	// pending indicates that g holds the first cluster of the next run
+	pending bool
+
+	start, end   int
+	width, count int
+}
+
+// Next advances the iterator to the next run.
+func (r *Runs[T]) Next() bool {
+	if !r.pending && !r.g.Next() {
+		return false
+	}
+	r.pending = false
+
+	r.start = r.g.Start()
+	r.end = r.g.End()
+	r.width = r.g.Width()
+	r.count = 1
+
+	for r.g.Next() {
+		if r.g.Width() != r.width {
+			r.pending = true
+			break
+		}
+		r.end = r.g.End()
+		r.count++
+	}
+	return true
+}
+
+// Value returns the current run.
+func (r *Runs[T]) Value() T {
+	return r.s[r.start:r.end]
+}
+
+// Width returns the display width of each grapheme cluster in the current
+// run.
+func (r *Runs[T]) Width() int {
+	return r.width
+}
+
+// Count returns the number of grapheme clusters in the current run.
+func (r *Runs[T]) Count() int {
+	return r.count
+}
+
+// Start returns the byte position of the current run in the original string
+// or []byte.
+func (r *Runs[T]) Start() int {
+	return r.start
+}
+
+// End returns the byte position after the current run in the original string
+// or []byte.
+func (r *Runs[T]) End() int {
+	return r.end
+}
+
+// StringRuns returns an iterator over runs of grapheme clusters of equal
+// width in the given string.
+//
+// The concatenated runs equal s, and the sum of Count × Width over the runs
+// equals [String].
+func StringRuns(s string) Runs[string] {
+	return DefaultOptionsValue().StringRuns(s)
+}
+
+// StringRuns returns an iterator over runs of grapheme clusters of equal
+// width in the given string, with the given options.
+//
+// The concatenated runs equal s, and the sum of Count × Width over the runs
+// equals [Options.String], except for options that depend on context, such
+// as TabWidth, which the Graphemes iterators do not honor.
+func (options Options) StringRuns(s string) Runs[string] {
+	return Runs[string]{s: s, g: options.StringGraphemes(s)}
+}
+
+// BytesRuns returns an iterator over runs of grapheme clusters of equal
+// width in the given []byte.
+//
+// The concatenated runs equal s, and the sum of Count × Width over the runs
+// equals [Bytes].
+func BytesRuns(s []byte) Runs[[]byte] {
+	return DefaultOptionsValue().BytesRuns(s)
+}
+
+// BytesRuns returns an iterator over runs of grapheme clusters of equal
+// width in the given []byte, with the given options. See
+// [Options.StringRuns].
+func (options Options) BytesRuns(s []byte) Runs[[]byte] {
+	return Runs[[]byte]{s: s, g: options.BytesGraphemes(s)}
+}
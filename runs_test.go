@@ -0,0 +1,91 @@
+package displaywidth
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRuns(t *testing.T) {
+	type run struct {
+		value string
+		width int
+		count int
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		options  Options
+		expected []run
+	}{
+		{"empty", "", defaultOptions, nil},
+		{"ASCII", "hello", defaultOptions, []run{{"hello", 1, 5}}},
+		{"CJK", "中文", defaultOptions, []run{{"中文", 2, 2}}},
+		{"alternating", "ab中文cd", defaultOptions, []run{
+			{"ab", 1, 2}, {"中文", 2, 2}, {"cd", 1, 2},
+		}},
+		{"zero width", "a\u200B\u200Bb", defaultOptions, []run{
+			{"a", 1, 1}, {"\u200B\u200B", 0, 2}, {"b", 1, 1},
+		}},
+		{"narrow wide zero", "x😀\nyz", defaultOptions, []run{
+			{"x", 1, 1}, {"😀", 2, 1}, {"\n", 0, 1}, {"yz", 1, 2},
+		}},
+		{"combining marks stay with base", "e\u0301e\u0301中", defaultOptions, []run{
+			{"e\u0301e\u0301", 1, 2}, {"中", 2, 1},
+		}},
+		{"escape sequences", "\x1b[31m中\x1b[0mab", controlSequences, []run{
+			{"\x1b[31m", 0, 1}, {"中", 2, 1}, {"\x1b[0m", 0, 1}, {"ab", 1, 2},
+		}},
+		{"ambiguous", "a★b", eawOptions, []run{
+			{"a", 1, 1}, {"★", 2, 1}, {"b", 1, 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []run
+			var concatenated string
+			width := 0
+			prev := 0
+
+			runs := tt.options.StringRuns(tt.input)
+			for runs.Next() {
+				got = append(got, run{runs.Value(), runs.Width(), runs.Count()})
+				if runs.Start() != prev {
+					t.Errorf("Start() = %d, want %d", runs.Start(), prev)
+				}
+				prev = runs.End()
+				concatenated += runs.Value()
+				width += runs.Count() * runs.Width()
+			}
+
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("StringRuns(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+			if concatenated != tt.input {
+				t.Errorf("concatenated runs = %q, want %q", concatenated, tt.input)
+			}
+			if want := tt.options.String(tt.input); width != want {
+				t.Errorf("sum of runs = %d, want String() = %d", width, want)
+			}
+
+			var gotBytes []run
+			bruns := tt.options.BytesRuns([]byte(tt.input))
+			for bruns.Next() {
+				gotBytes = append(gotBytes, run{string(bruns.Value()), bruns.Width(), bruns.Count()})
+			}
+			if !reflect.DeepEqual(gotBytes, tt.expected) {
+				t.Errorf("BytesRuns(%q) = %v, want %v", tt.input, gotBytes, tt.expected)
+			}
+		})
+	}
+
+	runs := StringRuns("ab中")
+	if !runs.Next() || runs.Value() != "ab" {
+		t.Errorf("StringRuns() first run = %q, want %q", runs.Value(), "ab")
+	}
+	bruns := BytesRuns([]byte("ab中"))
+	if !bruns.Next() || string(bruns.Value()) != "ab" {
+		t.Errorf("BytesRuns() first run = %q, want %q", bruns.Value(), "ab")
+	}
+}
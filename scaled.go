@@ -0,0 +1,107 @@
+package displaywidth
+
+import (
+	"github.com/clipperhouse/uax29/v2/graphemes"
+)
+
+// ScaledWidth calculates the display width of a string in units of 1/scale
+// cells, for modeling fonts that do not occupy whole cells.
+//
+// Each grapheme cluster contributes its width times scale, except clusters
+// whose property has a scaled width of its own:
+//   - East Asian Ambiguous clusters contribute [Options.AmbiguousScaledWidth]
+//     units, when it is nonzero
+//   - East Asian Wide and Fullwidth clusters, such as CJK, contribute
+//     [Options.WideScaledWidth] units, when it is nonzero
+//   - Emoji, including flags and emoji via VS16, contribute
+//     [Options.EmojiScaledWidth] units, when it is nonzero
+//
+// For example, with a scale of 3 and an AmbiguousScaledWidth of 2, ambiguous
+// characters occupy 2/3 of a cell. Callers are expected to round the result
+// to whole cells at the end.
+//
+// Widths that depend on context, such as tabs with [Options.TabWidth], are
+// measured as [Options.String] measures them, and then scaled. When all of the
+// scaled widths are zero, the result is exactly String(s) * scale, so a scale
+// of 1 reproduces [Options.String].
+//
+// This is an advanced API; most callers should use [Options.String].
+func (options Options) ScaledWidth(s string, scale int) int {
+	table, ok := options.scaledTable()
+	if !ok {
+		return options.String(s) * scale
+	}
+
+	g := graphemes.FromString(untilNUL(s, options))
+	g.AnsiEscapeSequences = options.ControlSequences || options.DECGraphics
+	g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+
+	total := 0
+	contextual := options.contextual()
+	var state contextState
+	for g.Next() {
+		v := g.Value()
+		w, reason := graphemeWidthReason(v, options)
+		if reason == ReasonEastAsianAmbiguous && isNarrowAmbiguous(v, options.NarrowAmbiguous) {
+			reason = ReasonNarrow
+		}
+
+		if contextual {
+			var next contextState
+			w, next = widthFrom(v, state, options)
+			if state.pending && next.cjk {
+				// With AmbiguousInCJKContext, the column added to an
+				// ambiguous cluster followed by CJK is counted with the
+				// CJK cluster, but belongs to the ambiguous one
+				w--
+				if table[ReasonEastAsianAmbiguous] == 0 {
+					total += scale
+				}
+			}
+			state = next
+		}
+
+		if units := table[reason]; units != 0 {
+			total += units
+			continue
+		}
+		total += w * scale
+	}
+	return total
+}
+
+// scaledTable returns the width, in units of 1/scale cells, of grapheme
+// clusters of each Reason, as set by the scaled width options, or 0 for
+// clusters that contribute their width times scale. ok is false if all of
+// the scaled widths are 0.
+func (options Options) scaledTable() (table [len(reasonNames)]int, ok bool) {
+	table[ReasonEastAsianAmbiguous] = options.AmbiguousScaledWidth
+	table[ReasonEastAsianWide] = options.WideScaledWidth
+	table[ReasonEmoji] = options.EmojiScaledWidth
+	table[ReasonEmojiVS16] = options.EmojiScaledWidth
+	table[ReasonRegionalIndicator] = options.EmojiScaledWidth
+
+	ok = options.AmbiguousScaledWidth != 0 || options.WideScaledWidth != 0 || options.EmojiScaledWidth != 0
+	return table, ok
+}
+
+// isAmbiguousCluster reports whether the grapheme cluster v, of width w, got
+// its width from the East Asian Ambiguous property of its leading rune, as
+// opposed to, say, emoji presentation via VS16.
+func isAmbiguousCluster[T ~string | ~[]byte](v T, w int, options Options) bool {
+	if len(v) < 2 || v[0] < 0xC2 {
+		// ASCII, C1 controls, and invalid UTF-8 are never ambiguous
+		return false
+	}
+	p, _ := lookup(v)
+	if !property(p).is(_East_Asian_Ambiguous) {
+		return false
+	}
+	if isNarrowAmbiguous(v, options.NarrowAmbiguous) {
+		return false
+	}
+	if options.EastAsianWidth {
+		return w == 2
+	}
+	return w == 1
+}
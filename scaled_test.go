@@ -0,0 +1,82 @@
+package displaywidth
+
+import "testing"
+
+func TestScaledWidth(t *testing.T) {
+	thirds := Options{AmbiguousScaledWidth: 2}
+	thirdsEAW := Options{AmbiguousScaledWidth: 2, EastAsianWidth: true}
+	wide := Options{WideScaledWidth: 5}
+	emoji := Options{EmojiScaledWidth: 4}
+	all := Options{AmbiguousScaledWidth: 2, WideScaledWidth: 5, EmojiScaledWidth: 4}
+
+	tests := []struct {
+		name     string
+		input    string
+		scale    int
+		options  Options
+		expected int
+	}{
+		{"empty", "", 3, thirds, 0},
+		{"ASCII", "abc", 3, thirds, 9},
+		{"CJK", "中文", 3, thirds, 12},
+		{"emoji", "😀", 3, thirds, 6},
+		{"ambiguous", "★", 3, thirds, 2},
+		{"ambiguous EAW", "★", 3, thirdsEAW, 2},
+		{"mixed", "a★中", 3, thirds, 3 + 2 + 6},
+		{"ambiguous with combining mark", "★\u0301", 3, thirds, 2},
+		{"ambiguous run", "★★★", 3, thirds, 6}, // rounds to 2 cells
+		{"zero-width", "\u200B", 3, thirds, 0},
+
+		// VS16 promotes an ambiguous base to an emoji; it is no longer
+		// measured as ambiguous
+		{"ambiguous VS16 emoji", "\u2660\uFE0F", 3, thirds, 6}, // ♠️
+
+		// Without AmbiguousScaledWidth, ambiguous clusters scale like the rest
+		{"default options ambiguous", "★", 3, defaultOptions, 3},
+		{"EAW ambiguous", "★", 3, eawOptions, 6},
+
+		// Wide and emoji clusters have scaled widths of their own
+		{"wide", "中文", 3, wide, 10},
+		{"wide with ASCII", "a中", 3, wide, 3 + 5},
+		{"wide excludes emoji", "😀", 3, wide, 6},
+		{"emoji", "😀", 3, emoji, 4},
+		{"emoji flag", "🇺🇸", 3, emoji, 4},
+		{"emoji VS16", "\u2660\uFE0F", 3, emoji, 4},
+		{"emoji excludes wide", "中", 3, emoji, 6},
+		{"all", "a★中😀", 3, all, 3 + 2 + 5 + 4},
+
+		// Widths that depend on context are measured as String measures
+		// them
+		{"tab", "中\tb", 3, Options{WideScaledWidth: 5, TabWidth: 4}, 5 + 6 + 3},
+		{"tab unscaled", "a\tb", 3, Options{EmojiScaledWidth: 4, TabWidth: 4}, 15},
+		{"contextual ambiguous", "中★", 3, Options{AmbiguousScaledWidth: 2, ContextualAmbiguous: true}, 6 + 2},
+		{"contextual ambiguous unscaled", "中★", 3, Options{WideScaledWidth: 5, ContextualAmbiguous: true}, 5 + 6},
+		{"CJK context", "★中", 3, Options{AmbiguousScaledWidth: 2, WideScaledWidth: 5, AmbiguousInCJKContext: true}, 2 + 5},
+		{"CJK context ambiguous unscaled", "★中", 3, Options{WideScaledWidth: 5, AmbiguousInCJKContext: true}, 6 + 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.ScaledWidth(tt.input, tt.scale)
+			if got != tt.expected {
+				t.Errorf("ScaledWidth(%q, %d) = %d, want %d", tt.input, tt.scale, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScaledWidthScaleOne(t *testing.T) {
+	inputs := []string{"", "hello", "中文", "😀👍🏽", "🇺🇸", "é", "★☆", "\x1b[31mred\x1b[0m", "a\t★中★"}
+	options := []Options{
+		defaultOptions, eawOptions, controlSequences,
+		{TabWidth: 4}, {ContextualAmbiguous: true}, {AmbiguousInCJKContext: true},
+	}
+
+	for _, opt := range options {
+		for _, input := range inputs {
+			if got, want := opt.ScaledWidth(input, 1), opt.String(input); got != want {
+				t.Errorf("ScaledWidth(%q, 1) with %+v = %d, want String() = %d", input, opt, got, want)
+			}
+		}
+	}
+}
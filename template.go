@@ -0,0 +1,41 @@
+package displaywidth
+
+import "text/template"
+
+// TemplateFuncs returns functions for text/template (or html/template, by
+// conversion to its FuncMap), for generating aligned output. See
+// [Options.TemplateFuncs].
+func TemplateFuncs() template.FuncMap {
+	return DefaultOptionsValue().TemplateFuncs()
+}
+
+// TemplateFuncs returns functions for text/template, using the given
+// options, for generating aligned output such as CLI tables:
+//
+//	width s               the display width of s, see [Options.String]
+//	truncate width tail s s truncated to width, see [Options.TruncateString]
+//	pad width s           s padded on the right to width, see [Options.Pad]
+//	padLeft width s       s padded on the left to width, see [Options.PadLeft]
+//
+// The string is the last argument, so that it can be piped:
+//
+//	{{.Name | truncate 10 "…" | pad 10}} {{.Size | printf "%d" | padLeft 6}}
+//
+// To use custom options, call TemplateFuncs on them:
+//
+//	opts := displaywidth.Options{EastAsianWidth: true}
+//	t := template.New("table").Funcs(opts.TemplateFuncs())
+func (options Options) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"width": options.String,
+		"truncate": func(width int, tail, s string) string {
+			return options.TruncateString(s, width, tail)
+		},
+		"pad": func(width int, s string) string {
+			return options.Pad(s, width)
+		},
+		"padLeft": func(width int, s string) string {
+			return options.PadLeft(s, width)
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package displaywidth
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	const text = `{{range .}}{{.Name | truncate 8 "…" | pad 8}}|{{printf "%d" .Size | padLeft 4}}|{{width .Name}}
+{{end}}`
+
+	rows := []struct {
+		Name string
+		Size int
+	}{
+		{"readme", 12},
+		{"中文文件名字", 345},
+		{"😀 emoji", 6},
+		{"🇯🇵🇺🇸🇬🇧🇫🇷🇩🇪", 7890},
+	}
+
+	tmpl := template.Must(template.New("table").Funcs(TemplateFuncs()).Parse(text))
+	var b strings.Builder
+	if err := tmpl.Execute(&b, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "" +
+		"readme  |  12|6\n" +
+		"中文文… | 345|12\n" +
+		"😀 emoji|   6|8\n" +
+		"🇯🇵🇺🇸🇬🇧… |7890|10\n"
+	if got := b.String(); got != expected {
+		t.Errorf("Execute() = \n%s, want \n%s", got, expected)
+	}
+
+	// Each line is aligned
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		cols := strings.Split(line, "|")
+		if w := String(cols[0]); w != 8 {
+			t.Errorf("column %q has width %d, want 8", cols[0], w)
+		}
+	}
+}
+
+func TestTemplateFuncsOptions(t *testing.T) {
+	const text = `{{width .}}|{{pad 4 .}}|{{truncate 3 "" .}}`
+
+	tests := []struct {
+		options  Options
+		expected string
+	}{
+		{defaultOptions, "2|★★  |★★"},
+		{eawOptions, "4|★★|★"},
+	}
+
+	for _, tt := range tests {
+		tmpl := template.Must(template.New("").Funcs(tt.options.TemplateFuncs()).Parse(text))
+		var b strings.Builder
+		if err := tmpl.Execute(&b, "★★"); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); got != tt.expected {
+			t.Errorf("Execute() with EastAsianWidth=%v = %q, want %q", tt.options.EastAsianWidth, got, tt.expected)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package displaywidth
 
 import (
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/clipperhouse/uax29/v2/graphemes"
 )
@@ -10,12 +12,18 @@ import (
 // given tail if the string is truncated.
 //
 // It ensures the visible width, including the width of the tail, is less than or
-// equal to maxWidth.
+// equal to maxWidth, unless [Options.TruncateKeepFirst] is true.
 //
 // When [Options.ControlSequences] is true, 7-bit ANSI escape sequences that
 // appear after the truncation point are preserved in the output. This ensures
 // that escape sequences such as SGR resets are not lost, preventing color
 // bleed in terminal output.
+// When [Options.EnsureReset] is also true, an SGR reset is appended if
+// colors would otherwise remain set, such as when the input has no reset.
+//
+// The truncation point never falls inside a 7-bit escape sequence. When
+// [Options.ControlSequences] is false, an escape sequence counts toward the
+// width as printable text, but is kept or dropped as a whole.
 //
 // [Options.ControlSequences8Bit] is ignored by truncation. 8-bit C1 byte values
 // (0x80-0x9F) overlap with UTF-8 multi-byte encoding, so manipulating them
@@ -26,45 +34,212 @@ func (options Options) TruncateString(s string, maxWidth int, tail string) strin
 	// We deliberately ignore ControlSequences8Bit for truncation, see above.
 	options.ControlSequences8Bit = false
 
-	maxWidthWithoutTail := maxWidth - options.String(tail)
+	g := graphemes.FromString(s)
+	g.AnsiEscapeSequences = true // see truncatePosition
+
+	pos, truncated := truncatePosition(g, maxWidth, tailOf(tail, options), options)
+	if !truncated {
+		return s
+	}
+	return options.truncatedString(s, pos, tail)
+}
+
+// TruncationOffset returns the byte offset at which [Options.TruncateString]
+// would cut s, such that s[:offset] plus a tail of width tailWidth fits
+// within maxWidth. It allows callers to render and style their own tail
+// without building the truncated string.
+//
+// The offset is always on a grapheme cluster boundary, and never inside a
+// 7-bit escape sequence. If s fits within maxWidth, the offset is len(s), and
+// no tail is needed.
+//
+// Escape sequences after the offset are not accounted for; see
+// [Options.TruncateString] for preserving them. With options whose widths
+// depend on context, such as [Options.ContextualAmbiguous], the tail may be
+// wider after the offset than on its own; tailWidth is taken as given.
+func (options Options) TruncationOffset(s string, maxWidth int, tailWidth int) int {
+	options.ControlSequences8Bit = false
 
-	var pos, total int
 	g := graphemes.FromString(s)
-	g.AnsiEscapeSequences = options.ControlSequences
+	g.AnsiEscapeSequences = true // see truncatePosition
+
+	pos, truncated := truncatePosition(g, maxWidth, truncationTail[string]{width: tailWidth}, options)
+	if !truncated {
+		return len(s)
+	}
+	return pos
+}
+
+// truncatedString returns s cut at pos, with tail appended. When
+// ControlSequences is true, 7-bit escape sequences after pos are preserved.
+// options.ControlSequences8Bit must be false.
+func (options Options) truncatedString(s string, pos int, tail string) string {
+	if !options.ControlSequences {
+		return s[:pos] + tail
+	}
+
+	// Build result with trailing 7-bit ANSI escape sequences preserved
+	var b strings.Builder
+	b.Grow(len(s) + len(tail) + len(sgrReset)) // at most original + tail + reset
+	b.WriteString(s[:pos])
+	b.WriteString(tail)
+
+	rem := graphemes.FromString(s[pos:])
+	rem.AnsiEscapeSequences = options.ControlSequences
 
+	for rem.Next() {
+		v := rem.Value()
+		// Only preserve 7-bit escapes (ESC = 0x1B) that measure
+		// as zero-width on their own; some sequences (e.g. SOS)
+		// are only valid in their original context.
+		if len(v) > 0 && v[0] == 0x1B && options.String(v) == 0 {
+			b.WriteString(v)
+		}
+	}
+	if options.EnsureReset && sgrActive(b.String()) {
+		b.WriteString(sgrReset)
+	}
+	return b.String()
+}
+
+// truncatePosition iterates over the grapheme clusters of g and returns the
+// byte position at which to cut, such that the kept prefix plus the tail
+// fits within maxWidth. It also reports whether truncation is needed at all,
+// i.e. whether the full text is wider than maxWidth.
+//
+// The kept prefix is measured as String would measure it on its own. For
+// options whose widths depend on context, such as TabWidth, the context is
+// carried from one cluster to the next, and the tail is measured where it
+// would be placed.
+//
+// g is expected to segment escape sequences, so that the cut never lands
+// inside one. When ControlSequences is false, an escape sequence is measured
+// as the printable text it would otherwise be, but is kept or dropped whole.
+//
+// When TruncateKeepFirst is true, pos is at least the end of the first
+// cluster of nonzero width, even if that exceeds maxWidth.
+func truncatePosition[T, U ~string | ~[]byte](g *graphemes.Iterator[T], maxWidth int, tail truncationTail[U], options Options) (pos int, truncated bool) {
+	var total, first int
+	var state contextState
 	for g.Next() {
-		gw := graphemeWidth(g.Value(), options)
-		if total+gw <= maxWidthWithoutTail {
+		var gw int
+		gw, state = truncationWidth(truncationValue(g, options), state, options)
+		if total+gw+tail.widthAfter(state, options) <= maxWidth {
 			pos = g.End()
 		}
+		if first == 0 && gw > 0 {
+			first = g.End()
+		}
 		total += gw
 		if total > maxWidth {
-			if options.ControlSequences {
-				// Build result with trailing 7-bit ANSI escape sequences preserved
-				var b strings.Builder
-				b.Grow(len(s) + len(tail)) // at most original + tail
-				b.WriteString(s[:pos])
-				b.WriteString(tail)
-
-				rem := graphemes.FromString(s[pos:])
-				rem.AnsiEscapeSequences = options.ControlSequences
-
-				for rem.Next() {
-					v := rem.Value()
-					// Only preserve 7-bit escapes (ESC = 0x1B) that measure
-					// as zero-width on their own; some sequences (e.g. SOS)
-					// are only valid in their original context.
-					if len(v) > 0 && v[0] == 0x1B && options.String(v) == 0 {
-						b.WriteString(v)
-					}
-				}
-				return b.String()
+			if options.TruncateKeepFirst && pos < first {
+				pos = first
 			}
-			return s[:pos] + tail
+			return pos, true
 		}
 	}
-	// No truncation
-	return s
+	return 0, false
+}
+
+// truncationTail is the tail appended at a truncation point, and its width
+// on its own.
+type truncationTail[T ~string | ~[]byte] struct {
+	text  T
+	width int
+}
+
+// tailOf returns tail as a truncationTail for the given options.
+func tailOf[T ~string | ~[]byte](tail T, options Options) truncationTail[T] {
+	return truncationTail[T]{text: tail, width: Width(options, tail)}
+}
+
+// widthAfter returns the width of the tail following text that ended in the
+// given state. For options whose widths depend on context, such as
+// ContextualAmbiguous, the tail may be wider there than on its own, such as
+// an ambiguous "…" after a wide character.
+func (t truncationTail[T]) widthAfter(state contextState, options Options) int {
+	if len(t.text) == 0 || !options.contextual() {
+		return t.width
+	}
+	w, _ := widthFrom(t.text, state, options)
+	return w
+}
+
+// truncationWidth returns the width of the grapheme cluster v for
+// truncation, following text that ended in the given state, and the state
+// after v. When ControlSequences is false, an escape sequence is measured as
+// the printable text it would otherwise be. See truncatePosition.
+func truncationWidth[T ~string | ~[]byte](v T, state contextState, options Options) (int, contextState) {
+	switch {
+	case options.contextual():
+		return widthFrom(v, state, options)
+	case !options.ControlSequences && len(v) > 1 && v[0] == 0x1B:
+		return Width(options, v), state
+	}
+	return graphemeWidth(v, options), state
+}
+
+// truncationValue returns the grapheme cluster at g as truncation keeps or
+// drops it. When ControlSequences is false, String measures an escape
+// sequence as printable text, so its final byte may form one cluster with
+// the marks that follow, such as U+0301. In that case, the escape and the
+// marks are returned together, and g is advanced past the marks.
+func truncationValue[T ~string | ~[]byte](g *graphemes.Iterator[T], options Options) T {
+	v := g.Value()
+	if options.ControlSequences || options.DECGraphics || len(v) < 2 || v[0] != 0x1B {
+		return v
+	}
+	peek := *g
+	if peek.Next() && escapeJoins(v, peek.Value()) {
+		*g = peek
+		return T(string(v) + string(peek.Value()))
+	}
+	return v
+}
+
+// escapeJoins reports whether the final byte of the escape sequence v, as
+// printable text, forms one grapheme cluster with the start of next.
+func escapeJoins[T ~string | ~[]byte](v, next T) bool {
+	// Only non-ASCII, such as combining marks, can extend a cluster
+	if len(next) == 0 || next[0] < utf8.RuneSelf {
+		return false
+	}
+	g := graphemes.FromString(string(v[len(v)-1:]) + string(next))
+	g.Next()
+	return g.End() > 1
+}
+
+// sgrReset is the SGR sequence that resets all graphic attributes.
+const sgrReset = "\x1b[0m"
+
+// sgrActive reports whether graphic attributes remain set at the end of s,
+// i.e. whether s contains a 7-bit SGR sequence (ESC [ ... m) and the last one
+// is not a reset. A reset is an SGR whose parameters are all empty or 0.
+func sgrActive[T ~string | ~[]byte](s T) bool {
+	active := false
+	for i := 0; i+1 < len(s); i++ {
+		if s[i] != 0x1B || s[i+1] != '[' {
+			continue
+		}
+		// Parameter bytes, then the final byte
+		j := i + 2
+		for j < len(s) && s[j] >= 0x30 && s[j] <= 0x3F {
+			j++
+		}
+		if j == len(s) || s[j] != 'm' {
+			continue
+		}
+		params := s[i+2 : j]
+		active = false
+		for k := 0; k < len(params); k++ {
+			if params[k] != '0' && params[k] != ';' {
+				active = true
+				break
+			}
+		}
+		i = j
+	}
+	return active
 }
 
 // TruncateString truncates a string to the given maxWidth, and appends the
@@ -73,7 +248,237 @@ func (options Options) TruncateString(s string, maxWidth int, tail string) strin
 // It ensures the total width, including the width of the tail, is less than or
 // equal to maxWidth.
 func TruncateString(s string, maxWidth int, tail string) string {
-	return DefaultOptions.TruncateString(s, maxWidth, tail)
+	return DefaultOptionsValue().TruncateString(s, maxWidth, tail)
+}
+
+// TruncationOffset returns the byte offset at which [TruncateString] would
+// cut s, such that s[:offset] plus a tail of width tailWidth fits within
+// maxWidth. If s fits, the offset is len(s).
+func TruncationOffset(s string, maxWidth int, tailWidth int) int {
+	return DefaultOptionsValue().TruncationOffset(s, maxWidth, tailWidth)
+}
+
+// TruncateCountTail truncates a string to the given maxWidth, and appends a
+// tail reporting how many grapheme clusters were hidden, such as
+// "Hello…(+12)".
+//
+// The tail has the form "…(+N)", where N is the number of dropped grapheme
+// clusters that have nonzero width. Since the width of the tail depends on the
+// number of digits in N, the cut point is recomputed until N's digit count is
+// stable.
+//
+// It ensures the visible width, including the tail, is less than or equal to
+// maxWidth. If maxWidth is too small for the tail, the tail is a plain "…",
+// as in [Options.TruncateString], or the result is empty if even "…" does
+// not fit.
+//
+// Escape sequences are handled as in [Options.TruncateString].
+func (options Options) TruncateCountTail(s string, maxWidth int) string {
+	// We deliberately ignore ControlSequences8Bit for truncation, see TruncateString.
+	options.ControlSequences8Bit = false
+
+	const prefix, suffix = "…(+", ")"
+
+	var pos, hidden int
+	for digits := 1; ; {
+		g := graphemes.FromString(s)
+		g.AnsiEscapeSequences = true // see truncatePosition
+
+		// Measure the tail with a placeholder for each digit
+		tail := tailOf(prefix+strings.Repeat("0", digits)+suffix, options)
+
+		var truncated bool
+		pos, truncated = truncatePosition(g, maxWidth, tail, options)
+		if !truncated {
+			return s
+		}
+
+		hidden = 0
+		rem := graphemes.FromString(s[pos:])
+		rem.AnsiEscapeSequences = options.ControlSequences
+		for rem.Next() {
+			if graphemeWidth(rem.Value(), options) > 0 {
+				hidden++
+			}
+		}
+
+		// A wider tail can only hide more, so the digit count only grows,
+		// and the loop converges.
+		n := len(strconv.Itoa(hidden))
+		if n <= digits {
+			break
+		}
+		digits = n
+	}
+
+	tail := prefix + strconv.Itoa(hidden) + suffix
+	if options.String(tail) > maxWidth {
+		// A partial count, such as "…(+1", would be misleading, so fall
+		// back to a plain ellipsis
+		const ellipsis = "…"
+		if options.String(ellipsis) > maxWidth {
+			return ""
+		}
+		return options.TruncateString(s, maxWidth, ellipsis)
+	}
+	return options.truncatedString(s, pos, tail)
+}
+
+// TruncateCountTail truncates a string to the given maxWidth, and appends a
+// tail reporting how many grapheme clusters were hidden, such as
+// "Hello…(+12)". See [Options.TruncateCountTail].
+func TruncateCountTail(s string, maxWidth int) string {
+	return DefaultOptionsValue().TruncateCountTail(s, maxWidth)
+}
+
+// TruncateRightAlign truncates a string from the left to the given maxWidth,
+// keeping the rightmost content, and prepends the given head if the string is
+// truncated. See [Options.TruncateRightAlign].
+func TruncateRightAlign(s string, maxWidth int, head string) string {
+	return DefaultOptionsValue().TruncateRightAlign(s, maxWidth, head)
+}
+
+// TruncateRightAlign truncates a string from the left to the given maxWidth,
+// keeping the rightmost content, and prepends the given head if the string is
+// truncated. It is intended for right-aligned columns, such as numbers, where
+// the least significant digits matter most; pad the result with
+// [Options.PadLeft].
+//
+// It ensures the visible width, including the width of the head, is less
+// than or equal to maxWidth. If the head alone is wider than maxWidth, the
+// result is the head. Truncation never splits a grapheme cluster.
+//
+// Escape sequences are handled as in [Options.TruncateString]: when
+// [Options.ControlSequences] is true, 7-bit escape sequences before the
+// truncation point are preserved, ahead of the head, and
+// [Options.EnsureReset] is honored.
+func (options Options) TruncateRightAlign(s string, maxWidth int, head string) string {
+	// We deliberately ignore ControlSequences8Bit for truncation, see TruncateString.
+	options.ControlSequences8Bit = false
+
+	if options.contextual() {
+		return options.truncateRightAlignContext(s, maxWidth, head)
+	}
+
+	g := graphemes.FromString(s)
+	g.AnsiEscapeSequences = true // see truncatePosition
+
+	total := 0
+	for g.Next() {
+		gw, _ := truncationWidth(truncationValue(g, options), contextState{}, options)
+		total += gw
+	}
+	if total <= maxWidth {
+		return s
+	}
+
+	// Drop clusters from the left until the rest fits beside the head
+	budget := maxWidth - options.String(head)
+	pos := len(s)
+	g = graphemes.FromString(s)
+	g.AnsiEscapeSequences = true
+	for g.Next() {
+		if total <= budget {
+			pos = g.Start()
+			break
+		}
+		gw, _ := truncationWidth(truncationValue(g, options), contextState{}, options)
+		total -= gw
+	}
+	return options.rightAligned(s, pos, head)
+}
+
+// truncateRightAlignContext is TruncateRightAlign for options whose widths
+// depend on context, such as TabWidth. Dropping text on the left changes the
+// context of the rest, such as the column of a tab, so each candidate is
+// measured whole.
+func (options Options) truncateRightAlignContext(s string, maxWidth int, head string) string {
+	if options.String(s) <= maxWidth {
+		return s
+	}
+
+	g := graphemes.FromString(s)
+	g.AnsiEscapeSequences = true // see truncatePosition
+	for g.Next() {
+		start := g.Start()
+		truncationValue(g, options)
+		if r := options.rightAligned(s, start, head); options.String(r) <= maxWidth {
+			return r
+		}
+	}
+	return options.rightAligned(s, len(s), head)
+}
+
+// rightAligned returns head followed by s from pos. When ControlSequences is
+// true, 7-bit escape sequences before pos are preserved, ahead of the head,
+// and EnsureReset is honored. options.ControlSequences8Bit must be false.
+func (options Options) rightAligned(s string, pos int, head string) string {
+	var b strings.Builder
+	b.Grow(len(s) + len(head) + len(sgrReset)) // at most original + head + reset
+	if options.ControlSequences {
+		// Preserve leading 7-bit escape sequences, as in truncatedString
+		rem := graphemes.FromString(s[:pos])
+		rem.AnsiEscapeSequences = options.ControlSequences
+		for rem.Next() {
+			v := rem.Value()
+			if len(v) > 0 && v[0] == 0x1B && options.String(v) == 0 {
+				b.WriteString(v)
+			}
+		}
+	}
+	b.WriteString(head)
+	b.WriteString(s[pos:])
+	if options.ControlSequences && options.EnsureReset && sgrActive(b.String()) {
+		b.WriteString(sgrReset)
+	}
+	return b.String()
+}
+
+// TruncateRunes truncates a string to the given number of runes, rather than
+// display width, and appends the given tail if the string is truncated. It is
+// intended for limits that count runes, such as some database columns.
+//
+// It ensures the rune count, including the runes of the tail, is less than or
+// equal to maxRunes. If the tail alone has more than maxRunes runes, the
+// result is the tail.
+//
+// Truncation never splits a grapheme cluster. Since a cluster may contain
+// several runes, such as an emoji ZWJ sequence, the result may have fewer
+// than maxRunes runes. When [Options.ControlSequences] is true, an escape
+// sequence is a single cluster, and is kept or dropped whole; its runes are
+// counted. As in [Options.TruncateString], 7-bit escape sequences after the
+// truncation point are preserved, and [Options.EnsureReset] is honored, so
+// these may take the result over maxRunes.
+//
+// [Options.ControlSequences8Bit] is ignored, as in [Options.TruncateString].
+func (options Options) TruncateRunes(s string, maxRunes int, tail string) string {
+	// We deliberately ignore ControlSequences8Bit for truncation, see TruncateString.
+	options.ControlSequences8Bit = false
+
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	maxRunesWithoutTail := maxRunes - utf8.RuneCountInString(tail)
+
+	g := graphemes.FromString(s)
+	g.AnsiEscapeSequences = options.ControlSequences
+
+	var pos, total int
+	for g.Next() {
+		total += utf8.RuneCountInString(g.Value())
+		if total > maxRunesWithoutTail {
+			break
+		}
+		pos = g.End()
+	}
+	return options.truncatedString(s, pos, tail)
+}
+
+// TruncateRunes truncates a string to the given number of runes, rather than
+// display width, and appends the given tail if the string is truncated. See
+// [Options.TruncateRunes].
+func TruncateRunes(s string, maxRunes int, tail string) string {
+	return DefaultOptionsValue().TruncateRunes(s, maxRunes, tail)
 }
 
 // TruncateBytes truncates a []byte to the given maxWidth, and appends the
@@ -87,6 +492,10 @@ func TruncateString(s string, maxWidth int, tail string) string {
 // that escape sequences such as SGR resets are not lost, preventing color
 // bleed in terminal output.
 //
+// The truncation point never falls inside a 7-bit escape sequence. When
+// [Options.ControlSequences] is false, an escape sequence counts toward the
+// width as printable text, but is kept or dropped as a whole.
+//
 // [Options.ControlSequences8Bit] is ignored by truncation. 8-bit C1 byte values
 // (0x80-0x9F) overlap with UTF-8 multi-byte encoding, so manipulating them
 // during truncation can shift byte boundaries and form unintended visible
@@ -96,47 +505,76 @@ func (options Options) TruncateBytes(s []byte, maxWidth int, tail []byte) []byte
 	// We deliberately ignore ControlSequences8Bit for truncation, see above.
 	options.ControlSequences8Bit = false
 
-	maxWidthWithoutTail := maxWidth - options.Bytes(tail)
-
-	var pos, total int
 	g := graphemes.FromBytes(s)
-	g.AnsiEscapeSequences = options.ControlSequences
+	g.AnsiEscapeSequences = true // see truncatePosition
 
-	for g.Next() {
-		gw := graphemeWidth(g.Value(), options)
-		if total+gw <= maxWidthWithoutTail {
-			pos = g.End()
-		}
-		total += gw
-		if total > maxWidth {
-			if options.ControlSequences {
-				// Build result with trailing 7-bit ANSI escape sequences preserved
-				result := make([]byte, 0, len(s)+len(tail)) // at most original + tail
-				result = append(result, s[:pos]...)
-				result = append(result, tail...)
-
-				rem := graphemes.FromBytes(s[pos:])
-				rem.AnsiEscapeSequences = options.ControlSequences
-
-				for rem.Next() {
-					v := rem.Value()
-					// Only preserve 7-bit escapes (ESC = 0x1B) that measure
-					// as zero-width on their own; some sequences (e.g. SOS)
-					// are only valid in their original context.
-					if len(v) > 0 && v[0] == 0x1B && options.Bytes(v) == 0 {
-						result = append(result, v...)
-					}
-				}
-				return result
-			}
-			result := make([]byte, 0, pos+len(tail))
-			result = append(result, s[:pos]...)
-			result = append(result, tail...)
-			return result
+	pos, truncated := truncatePosition(g, maxWidth, tailOf(tail, options), options)
+	if !truncated {
+		return s
+	}
+
+	return options.truncatedBytes(s, pos, tail)
+}
+
+// truncatedBytes returns s cut at pos, with tail appended, in a new slice.
+// When ControlSequences is true, 7-bit escape sequences after pos are
+// preserved. options.ControlSequences8Bit must be false.
+func (options Options) truncatedBytes(s []byte, pos int, tail []byte) []byte {
+	n := pos + len(tail)
+	if options.ControlSequences {
+		n = len(s) + len(tail) + len(sgrReset) // at most original + tail + reset
+	}
+	return options.appendTruncated(make([]byte, 0, n), s, pos, tail)
+}
+
+// appendTruncated appends s cut at pos, with tail appended, to dst, as in
+// truncatedBytes.
+func (options Options) appendTruncated(dst []byte, s []byte, pos int, tail []byte) []byte {
+	start := len(dst)
+	dst = append(dst, s[:pos]...)
+	dst = append(dst, tail...)
+	if !options.ControlSequences {
+		return dst
+	}
+
+	// Preserve trailing 7-bit ANSI escape sequences
+	rem := graphemes.FromBytes(s[pos:])
+	rem.AnsiEscapeSequences = options.ControlSequences
+
+	for rem.Next() {
+		v := rem.Value()
+		// Only preserve 7-bit escapes (ESC = 0x1B) that measure
+		// as zero-width on their own; some sequences (e.g. SOS)
+		// are only valid in their original context.
+		if len(v) > 0 && v[0] == 0x1B && options.Bytes(v) == 0 {
+			dst = append(dst, v...)
 		}
 	}
-	// No truncation
-	return s
+	if options.EnsureReset && sgrActive(dst[start:]) {
+		dst = append(dst, sgrReset...)
+	}
+	return dst
+}
+
+// TruncateBytesInto truncates s as [Options.TruncateBytes] does, and
+// appends the result to dst, returning the extended slice. If s is not
+// truncated, s itself is appended.
+//
+// It allows callers that truncate many values, such as table renderers, to
+// reuse a single buffer, avoiding an allocation per call. The caller resets
+// dst between uses, such as with dst[:0]. s and dst must not overlap.
+func (options Options) TruncateBytesInto(dst, s []byte, maxWidth int, tail []byte) []byte {
+	// We deliberately ignore ControlSequences8Bit for truncation, see TruncateBytes.
+	options.ControlSequences8Bit = false
+
+	g := graphemes.FromBytes(s)
+	g.AnsiEscapeSequences = true // see truncatePosition
+
+	pos, truncated := truncatePosition(g, maxWidth, tailOf(tail, options), options)
+	if !truncated {
+		return append(dst, s...)
+	}
+	return options.appendTruncated(dst, s, pos, tail)
 }
 
 // TruncateBytes truncates a []byte to the given maxWidth, and appends the
@@ -145,5 +583,12 @@ func (options Options) TruncateBytes(s []byte, maxWidth int, tail []byte) []byte
 // It ensures the total width, including the width of the tail, is less than or
 // equal to maxWidth.
 func TruncateBytes(s []byte, maxWidth int, tail []byte) []byte {
-	return DefaultOptions.TruncateBytes(s, maxWidth, tail)
+	return DefaultOptionsValue().TruncateBytes(s, maxWidth, tail)
+}
+
+// TruncateBytesInto truncates s as [TruncateBytes] does, and appends the
+// result to dst, returning the extended slice. See
+// [Options.TruncateBytesInto].
+func TruncateBytesInto(dst, s []byte, maxWidth int, tail []byte) []byte {
+	return DefaultOptionsValue().TruncateBytesInto(dst, s, maxWidth, tail)
 }
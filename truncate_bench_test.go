@@ -65,3 +65,31 @@ func BenchmarkTruncateBytes(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkTruncateBytesInto(b *testing.B) {
+	cells := [][]byte{
+		[]byte(plainText),
+		[]byte(shortANSI),
+		[]byte(stackedANSI),
+		[]byte(interleavedANSI),
+	}
+
+	b.Run("TruncateBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, cell := range cells {
+				_ = csOptions.TruncateBytes(cell, 5, tail)
+			}
+		}
+	})
+
+	b.Run("TruncateBytesInto", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf []byte
+		for i := 0; i < b.N; i++ {
+			for _, cell := range cells {
+				buf = csOptions.TruncateBytesInto(buf[:0], cell, 5, tail)
+			}
+		}
+	})
+}
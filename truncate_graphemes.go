@@ -0,0 +1,85 @@
+package displaywidth
+
+import "strings"
+
+// TruncateGraphemes truncates a string, given as its grapheme clusters, to
+// the given maxWidth, and appends the given tail if the string is truncated.
+// See [Options.TruncateGraphemes].
+func TruncateGraphemes(clusters []string, maxWidth int, tail string) string {
+	return DefaultOptionsValue().TruncateGraphemes(clusters, maxWidth, tail)
+}
+
+// TruncateGraphemes truncates a string, given as its grapheme clusters, to
+// the given maxWidth, and appends the given tail if the string is truncated.
+// It is intended for hot rendering loops that have already segmented their
+// text, such as with [Options.StringGraphemes], and avoids segmenting it
+// again.
+//
+// The result is the same as [Options.TruncateString] on the joined clusters,
+// provided that the clusters are segmented as TruncateString would segment
+// them: each escape sequence is a single cluster, as when ControlSequences
+// is true. Otherwise, the cut may fall inside an escape sequence.
+func (options Options) TruncateGraphemes(clusters []string, maxWidth int, tail string) string {
+	// We deliberately ignore ControlSequences8Bit for truncation, see TruncateString.
+	options.ControlSequences8Bit = false
+
+	t := tailOf(tail, options)
+
+	// Find the number of clusters to keep, as in truncatePosition
+	// keep and first are counts of clusters; n, keepLen and firstLen are
+	// their lengths in bytes
+	var total, first, keep int
+	var n, firstLen, keepLen int
+	var state contextState
+	truncated := false
+	for i := 0; i < len(clusters); i++ {
+		v := clusters[i]
+		if !options.ControlSequences && !options.DECGraphics && len(v) > 1 && v[0] == 0x1B &&
+			i+1 < len(clusters) && escapeJoins(v, clusters[i+1]) {
+			// Keep or drop the escape and the marks after it together, see
+			// truncationValue
+			i++
+			v += clusters[i]
+		}
+		var gw int
+		gw, state = truncationWidth(v, state, options)
+		n += len(v)
+		if total+gw+t.widthAfter(state, options) <= maxWidth {
+			keep, keepLen = i+1, n
+		}
+		if first == 0 && gw > 0 {
+			first, firstLen = i+1, n
+		}
+		total += gw
+		if total > maxWidth {
+			truncated = true
+			break
+		}
+	}
+	if !truncated {
+		return strings.Join(clusters, "")
+	}
+	if options.TruncateKeepFirst && keep < first {
+		keep, keepLen = first, firstLen
+	}
+
+	var b strings.Builder
+	b.Grow(keepLen + len(tail))
+	for _, v := range clusters[:keep] {
+		b.WriteString(v)
+	}
+	b.WriteString(tail)
+
+	if options.ControlSequences {
+		// Preserve trailing 7-bit escape sequences, as in truncatedString
+		for _, v := range clusters[keep:] {
+			if len(v) > 0 && v[0] == 0x1B && options.String(v) == 0 {
+				b.WriteString(v)
+			}
+		}
+		if options.EnsureReset && sgrActive(b.String()) {
+			b.WriteString(sgrReset)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package displaywidth
+
+import (
+	"strings"
+	"testing"
+)
+
+// clustersOf segments s as TruncateString does, with escape sequences as
+// single clusters.
+func clustersOf(s string) []string {
+	var clusters []string
+	g := Options{ControlSequences: true}.StringGraphemes(s)
+	for g.Next() {
+		clusters = append(clusters, g.Value())
+	}
+	return clusters
+}
+
+func TestTruncateGraphemes(t *testing.T) {
+	inputs := []string{
+		"",
+		"hello",
+		"hello world",
+		"中文字符测试",
+		"😀👩‍💻🇺🇸 emoji",
+		"é combining é",
+		"\x1b[31mhello world\x1b[0m",
+		"\x1b[1m\x1b[31m中文\x1b[0m tail",
+		"a\tb\x07c",
+	}
+	options := []Options{
+		defaultOptions,
+		eawOptions,
+		controlSequences,
+		{TruncateKeepFirst: true},
+		{ControlSequences: true, TruncateKeepFirst: true},
+		{TabWidth: 4},
+	}
+	tails := []string{"", "...", "…", "中"}
+
+	for _, opt := range options {
+		for _, input := range inputs {
+			clusters := clustersOf(input)
+			for _, tail := range tails {
+				for maxWidth := -1; maxWidth <= 14; maxWidth++ {
+					expected := opt.TruncateString(input, maxWidth, tail)
+					if got := opt.TruncateGraphemes(clusters, maxWidth, tail); got != expected {
+						t.Errorf("TruncateGraphemes(%q, %d, %q) with options %+v = %q, want %q", input, maxWidth, tail, opt, got, expected)
+					}
+				}
+			}
+		}
+	}
+
+	if got := TruncateGraphemes([]string{"中", "文", "字"}, 4, "…"); got != "中…" {
+		t.Errorf("TruncateGraphemes() = %q, want %q", got, "中…")
+	}
+}
+
+func BenchmarkTruncateGraphemes(b *testing.B) {
+	input := strings.Repeat("hello 中文 😀 ", 8)
+	clusters := clustersOf(input)
+
+	b.Run("TruncateString", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = TruncateString(input, 40, "...")
+		}
+	})
+	b.Run("TruncateGraphemes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = TruncateGraphemes(clusters, 40, "...")
+		}
+	})
+}
@@ -0,0 +1,568 @@
+package displaywidth
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTruncateCountTail(t *testing.T) {
+	long := func(n int) string {
+		return strings.Repeat("x", n)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		maxWidth int
+		options  Options
+		expected string
+	}{
+		{"empty", "", 5, defaultOptions, ""},
+		{"fits", "hello", 5, defaultOptions, "hello"},
+		{"fits with room", "hello", 10, defaultOptions, "hello"},
+
+		// Tail "…(+N)" is 4 columns plus the digits of N
+		{"one digit", long(13), 10, defaultOptions, "xxxxx…(+8)"},
+		{"nine hidden", long(14), 10, defaultOptions, "xxxxx…(+9)"},
+		// 10 hidden would need a 2-digit tail, which hides one more
+		{"crosses 9 to 10", long(15), 10, defaultOptions, "xxxx…(+11)"},
+		{"two digits", long(20), 10, defaultOptions, "xxxx…(+16)"},
+		{"ninety-nine hidden", long(103), 10, defaultOptions, "xxxx…(+99)"},
+		// 100 hidden needs a 3-digit tail, which hides one more
+		{"crosses 99 to 100", long(104), 10, defaultOptions, "xxx…(+101)"},
+
+		// Wide characters
+		{"CJK", "中文字符测试", 8, defaultOptions, "中…(+5)"},
+		{"CJK odd budget", "中文字符测试", 9, defaultOptions, "中文…(+4)"},
+
+		// The ellipsis is East Asian Ambiguous
+		{"EAW ellipsis", long(13), 10, eawOptions, "xxxx…(+9)"},
+
+		// Zero-width clusters are not counted as hidden
+		{"combining marks not counted", "abcdefghie\u0301e\u0301", 10, defaultOptions, "abcde…(+6)"},
+
+		// Escape sequences are preserved, and not counted as hidden
+		{"ControlSequences", "\x1b[31m" + long(15) + "\x1b[0m", 10, controlSequences, "\x1b[31mxxxx…(+11)\x1b[0m"},
+
+		// Tail alone does not fit, so a plain ellipsis replaces it
+		{"tail too wide", long(20), 3, defaultOptions, "xx…"},
+		{"tail too wide for digits", long(20), 5, defaultOptions, "xxxx…"},
+		{"ellipsis only", long(20), 1, defaultOptions, "…"},
+		{"ellipsis too wide", long(20), 1, eawOptions, ""},
+		{"zero width", long(20), 0, defaultOptions, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.TruncateCountTail(tt.input, tt.maxWidth)
+			if got != tt.expected {
+				t.Errorf("TruncateCountTail(%q, %d) = %q, want %q", tt.input, tt.maxWidth, got, tt.expected)
+			}
+			if w := tt.options.String(got); w > tt.maxWidth {
+				t.Errorf("TruncateCountTail(%q, %d) has width %d, want <= %d", tt.input, tt.maxWidth, w, tt.maxWidth)
+			}
+		})
+	}
+
+	if got := TruncateCountTail(long(13), 10); got != "xxxxx…(+8)" {
+		t.Errorf("TruncateCountTail() = %q, want %q", got, "xxxxx…(+8)")
+	}
+}
+
+func TestTruncateEscapeSequenceWhole(t *testing.T) {
+	// Without ControlSequences, the escape counts as 4 printable columns
+	// ("[31m"), but truncation must never cut inside it.
+	const input = "hel\x1b[31mlo"
+
+	tests := []struct {
+		name     string
+		maxWidth int
+		tail     string
+		options  Options
+		expected string
+	}{
+		{"before escape", 3, "", defaultOptions, "hel"},
+		{"inside escape 1", 4, "", defaultOptions, "hel"},
+		{"inside escape 2", 5, "", defaultOptions, "hel"},
+		{"inside escape 3", 6, "", defaultOptions, "hel"},
+		{"after escape", 7, "", defaultOptions, "hel\x1b[31m"},
+		{"after escape plus one", 8, "", defaultOptions, "hel\x1b[31ml"},
+		{"fits", 9, "", defaultOptions, input},
+		{"tail inside escape", 5, "…", defaultOptions, "hel…"},
+		{"tail after escape", 8, "…", defaultOptions, "hel\x1b[31m…"},
+
+		// With ControlSequences, the escape is zero width and preserved
+		{"ControlSequences fits", 5, "…", controlSequences, input},
+		{"ControlSequences truncated", 3, "…", controlSequences, "he…\x1b[31m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.TruncateString(input, tt.maxWidth, tt.tail)
+			if got != tt.expected {
+				t.Errorf("TruncateString(%q, %d, %q) = %q, want %q", input, tt.maxWidth, tt.tail, got, tt.expected)
+			}
+			gotBytes := tt.options.TruncateBytes([]byte(input), tt.maxWidth, []byte(tt.tail))
+			if string(gotBytes) != tt.expected {
+				t.Errorf("TruncateBytes(%q, %d, %q) = %q, want %q", input, tt.maxWidth, tt.tail, gotBytes, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncateEscapeWithMarks(t *testing.T) {
+	// Without ControlSequences, the final byte of the escape clusters with the
+	// marks after it, as String measures them
+	const input = "ab\x1b[0m\u0301\u0301c"
+	options := Options{MaxCombiningPerCluster: 1}
+
+	tests := []struct {
+		name     string
+		maxWidth int
+		expected string
+	}{
+		{"before escape", 5, "ab"},
+		{"escape and marks", 6, "ab\x1b[0m\u0301\u0301"},
+		{"fits", 7, input},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := options.TruncateString(input, tt.maxWidth, "")
+			if got != tt.expected {
+				t.Errorf("TruncateString(%q, %d) = %q, want %q", input, tt.maxWidth, got, tt.expected)
+			}
+			if w := options.String(got); w > tt.maxWidth {
+				t.Errorf("TruncateString(%q, %d) has width %d", input, tt.maxWidth, w)
+			}
+			if got := options.TruncateGraphemes(clustersOf(input), tt.maxWidth, ""); got != tt.expected {
+				t.Errorf("TruncateGraphemes(%q, %d) = %q, want %q", input, tt.maxWidth, got, tt.expected)
+			}
+		})
+	}
+
+	// With other clusters of zero width before the escape
+	const fuzzed = "👍🏽\u00a0\r\n\u2060\x1b[0m\u0301\u0301"
+	if got := options.TruncateString(fuzzed, 6, "…"); options.String(got) > 6 {
+		t.Errorf("TruncateString(%q, 6) = %q, width %d", fuzzed, got, options.String(got))
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	family := "👨\u200D👩\u200D👧" // 5 runes, 1 cluster
+
+	tests := []struct {
+		name     string
+		input    string
+		maxRunes int
+		tail     string
+		options  Options
+		expected string
+	}{
+		{"empty", "", 5, "...", defaultOptions, ""},
+		{"fits", "hello", 5, "...", defaultOptions, "hello"},
+		{"truncated", "hello world", 8, "...", defaultOptions, "hello..."},
+		{"no tail", "hello world", 5, "", defaultOptions, "hello"},
+		{"zero", "hello", 0, "", defaultOptions, ""},
+		{"tail too long", "hello world", 2, "...", defaultOptions, "..."},
+
+		// Runes, not width: CJK is 1 rune, 2 columns
+		{"CJK", "中文字符测试", 4, "…", defaultOptions, "中文字…"},
+
+		// Clusters are never split, so the result may be under budget
+		{"combining", "e\u0301e\u0301e\u0301", 3, "", defaultOptions, "e\u0301"},
+		{"ZWJ sequence kept", "a" + family + "b", 6, "", defaultOptions, "a" + family},
+		{"ZWJ sequence dropped", "a" + family + "b", 5, "", defaultOptions, "a"},
+		{"flag", "🇺🇸🇯🇵", 3, "", defaultOptions, "🇺🇸"},
+
+		// Escape sequences count their runes; with ControlSequences they
+		// are kept whole
+		{"escape", "\x1b[31mred", 4, "", defaultOptions, "\x1b[31"},
+		{"escape ControlSequences", "\x1b[31mred", 4, "", controlSequences, "\x1b[31m"}, // dropped, then preserved
+		{"escape ControlSequences kept", "\x1b[31mred", 6, "", controlSequences, "\x1b[31mr"},
+		{"escape ControlSequences reset preserved", "\x1b[31mhello world\x1b[0m", 10, "…", controlSequences, "\x1b[31mhell…\x1b[0m"},
+		{"escape EnsureReset", "\x1b[31mhello world", 10, "…", Options{ControlSequences: true, EnsureReset: true}, "\x1b[31mhell…\x1b[0m"},
+		{"escape ControlSequences8Bit ignored", "\x9b31mhello", 5, "", Options{ControlSequences8Bit: true}, "\x9b31mh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.TruncateRunes(tt.input, tt.maxRunes, tt.tail)
+			if got != tt.expected {
+				t.Errorf("TruncateRunes(%q, %d, %q) = %q, want %q", tt.input, tt.maxRunes, tt.tail, got, tt.expected)
+			}
+		})
+	}
+
+	if got := TruncateRunes("hello world", 8, "..."); got != "hello..." {
+		t.Errorf("TruncateRunes() = %q, want %q", got, "hello...")
+	}
+}
+
+func TestTruncateKeepFirst(t *testing.T) {
+	keep := Options{TruncateKeepFirst: true}
+	keepCS := Options{TruncateKeepFirst: true, ControlSequences: true}
+
+	tests := []struct {
+		name     string
+		options  Options
+		input    string
+		maxWidth int
+		tail     string
+		expected string
+	}{
+		{"default drops all content", defaultOptions, "hello", 2, "...", "..."},
+		{"keeps first cluster", keep, "hello", 2, "...", "h..."},
+		{"keeps first cluster at zero width", keep, "hello", 0, "...", "h..."},
+		{"keeps wide first cluster", keep, "中文字", 2, "...", "中..."},
+		{"keeps whole emoji", keep, "👨\u200D👩\u200D👧 family", 3, "...", "👨\u200D👩\u200D👧..."},
+		{"no effect when content fits", keep, "hello world", 8, "...", "hello..."},
+		{"no effect when not truncated", keep, "hi", 2, "...", "hi"},
+		{"empty input", keep, "", 0, "...", ""},
+		{"skips leading escape", keepCS, "\x1b[31mhello\x1b[0m", 2, "...", "\x1b[31mh...\x1b[0m"},
+		{"skips leading zero width", keep, "\u200Bhello", 2, "...", "\u200Bh..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.TruncateString(tt.input, tt.maxWidth, tt.tail)
+			if got != tt.expected {
+				t.Errorf("TruncateString(%q, %d, %q) = %q, want %q", tt.input, tt.maxWidth, tt.tail, got, tt.expected)
+			}
+			gotBytes := tt.options.TruncateBytes([]byte(tt.input), tt.maxWidth, []byte(tt.tail))
+			if string(gotBytes) != tt.expected {
+				t.Errorf("TruncateBytes(%q, %d, %q) = %q, want %q", tt.input, tt.maxWidth, tt.tail, gotBytes, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncateRightAlign(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		input    string
+		maxWidth int
+		head     string
+		expected string
+	}{
+		{"fits", defaultOptions, "12345", 5, "…", "12345"},
+		{"long number", defaultOptions, "1234567890", 6, "…", "…67890"},
+		{"long number, ASCII head", defaultOptions, "1234567890", 6, "...", "...890"},
+		{"thousands separators", defaultOptions, "1,234,567,890", 8, "…", "…567,890"},
+		{"currency", defaultOptions, "$1,234,567.89", 8, "…", "…,567.89"},
+		{"fullwidth currency", defaultOptions, "￥１２３４５", 7, "…", "…３４５"},
+		{"wide cluster at the cut", defaultOptions, "中文字符", 6, "…", "…字符"},
+		{"wide cluster straddles the cut", defaultOptions, "中文字符", 4, "…", "…符"},
+		{"combining mark kept with base", defaultOptions, "abcé", 3, "…", "…cé"},
+		{"emoji", defaultOptions, "😀😀😀 100", 7, "…", "…😀 100"},
+		{"flag", defaultOptions, "🇺🇸🇯🇵", 3, "…", "…🇯🇵"},
+		{"empty head", defaultOptions, "1234567890", 4, "", "7890"},
+		{"head too wide", defaultOptions, "1234567890", 2, "...", "..."},
+		{"zero width", defaultOptions, "123", 0, "", ""},
+		{"escape sequences preserved", controlSequences, "\x1b[31m1234567890\x1b[0m", 4, "…", "\x1b[31m…890\x1b[0m"},
+		{"escape sequence counted as text", defaultOptions, "\x1b[31m12345", 4, "…", "…345"},
+		{"EnsureReset", Options{ControlSequences: true, EnsureReset: true}, "\x1b[31mhello world", 5, "…", "\x1b[31m…orld\x1b[0m"},
+		{"EnsureReset reset kept", Options{ControlSequences: true, EnsureReset: true}, "\x1b[31mhello world\x1b[0m", 5, "…", "\x1b[31m…orld\x1b[0m"},
+		{"EnsureReset fits", Options{ControlSequences: true, EnsureReset: true}, "\x1b[31mhello", 5, "…", "\x1b[31mhello"},
+
+		// Dropping text on the left moves the tab stops of the rest
+		{"tab", Options{TabWidth: 8}, "a\tbcdefgh", 10, "…", "…bcdefgh"},
+		{"tab after the head", Options{TabWidth: 8}, "ab\tc", 5, "…", "…c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.TruncateRightAlign(tt.input, tt.maxWidth, tt.head)
+			if got != tt.expected {
+				t.Errorf("TruncateRightAlign(%q, %d, %q) = %q, want %q", tt.input, tt.maxWidth, tt.head, got, tt.expected)
+			}
+			if w := tt.options.String(got); w > tt.maxWidth && tt.options.String(tt.head) <= tt.maxWidth {
+				t.Errorf("TruncateRightAlign(%q, %d, %q) has width %d", tt.input, tt.maxWidth, tt.head, w)
+			}
+		})
+	}
+
+	// Right-aligned column
+	for _, n := range []string{"7", "1,234", "12,345,678", "中文12"} {
+		got := PadLeft(TruncateRightAlign(n, 6, "…"), 6)
+		if w := String(got); w != 6 {
+			t.Errorf("PadLeft(TruncateRightAlign(%q)) = %q, width %d, want 6", n, got, w)
+		}
+	}
+}
+
+func TestTruncationOffset(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxWidth int
+		tail     string
+		options  Options
+		expected int
+	}{
+		{"empty", "", 5, "…", defaultOptions, 0},
+		{"fits", "hello", 5, "…", defaultOptions, 5},
+		{"truncated", "hello world", 8, "…", defaultOptions, 7},
+		{"no tail", "hello world", 8, "", defaultOptions, 8},
+		{"wide tail", "hello world", 8, "……", defaultOptions, 6},
+		{"CJK", "中文字符", 5, "…", defaultOptions, 6},
+		{"CJK even", "中文字符", 6, "…", defaultOptions, 6},
+		{"combining marks", "e\u0301e\u0301e\u0301e\u0301", 3, "…", defaultOptions, 6},
+		{"emoji ZWJ", "👨\u200d👩\u200d👧abc", 3, "…", defaultOptions, len("👨\u200d👩\u200d👧")},
+		{"escape counted", "hel\x1b[31mlo", 5, "…", defaultOptions, 3},
+		{"tab", "ab\tcd", 9, "…", Options{TabWidth: 8}, 3},
+		{"escape zero width", "\x1b[31mhello\x1b[0m", 4, "…", controlSequences, len("\x1b[31mhel")},
+		{"EAW tail", "hello world", 8, "…", eawOptions, 6},
+		{"keep first", "中文", 1, "…", Options{TruncateKeepFirst: true}, 3},
+		{"zero width", "hello", 0, "", defaultOptions, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tailWidth := tt.options.String(tt.tail)
+			got := tt.options.TruncationOffset(tt.input, tt.maxWidth, tailWidth)
+			if got != tt.expected {
+				t.Fatalf("TruncationOffset(%q, %d, %d) = %d, want %d", tt.input, tt.maxWidth, tailWidth, got, tt.expected)
+			}
+
+			// The offset matches TruncateString's cut point
+			truncated := tt.options.TruncateString(tt.input, tt.maxWidth, tt.tail)
+			if got == len(tt.input) {
+				if truncated != tt.input {
+					t.Errorf("TruncateString(%q, %d, %q) = %q, want input unchanged", tt.input, tt.maxWidth, tt.tail, truncated)
+				}
+				return
+			}
+			if want := tt.input[:got] + tt.tail; !strings.HasPrefix(truncated, want) {
+				t.Errorf("TruncateString(%q, %d, %q) = %q, want prefix %q", tt.input, tt.maxWidth, tt.tail, truncated, want)
+			}
+		})
+	}
+
+	// Every width agrees with TruncateString
+	const input = "Hello, 世界! e\u0301 🇺🇸 \x1b[1mbold\x1b[0m"
+	for _, options := range []Options{defaultOptions, eawOptions, controlSequences} {
+		for maxWidth := 0; maxWidth <= options.String(input)+1; maxWidth++ {
+			got := options.TruncationOffset(input, maxWidth, options.String("..."))
+			truncated := options.TruncateString(input, maxWidth, "...")
+			want := input
+			if got < len(input) {
+				want = input[:got] + "..."
+			}
+			if !strings.HasPrefix(truncated, want) {
+				t.Errorf("TruncateString(%q, %d) = %q, want prefix %q", input, maxWidth, truncated, want)
+			}
+		}
+	}
+
+	if got := TruncationOffset("hello world", 8, 1); got != 7 {
+		t.Errorf("TruncationOffset() = %d, want 7", got)
+	}
+}
+
+func TestEnsureReset(t *testing.T) {
+	reset := Options{ControlSequences: true, EnsureReset: true}
+
+	tests := []struct {
+		name     string
+		input    string
+		maxWidth int
+		options  Options
+		expected string
+	}{
+		{"no reset in input", "\x1b[31mhello world", 8, reset, "\x1b[31mhello w…\x1b[0m"},
+		{"no reset without option", "\x1b[31mhello world", 8, controlSequences, "\x1b[31mhello w…"},
+		{"reset preserved", "\x1b[31mhello world\x1b[0m", 8, reset, "\x1b[31mhello w…\x1b[0m"},
+		{"short reset preserved", "\x1b[31mhello world\x1b[m", 8, reset, "\x1b[31mhello w…\x1b[m"},
+		{"color after reset", "\x1b[31mhi\x1b[0m \x1b[32mthere world", 8, reset, "\x1b[31mhi\x1b[0m \x1b[32mther…\x1b[0m"},
+		{"reset then color dropped", "\x1b[31mhello\x1b[0m world\x1b[1m!", 8, reset, "\x1b[31mhello\x1b[0m w…\x1b[1m\x1b[0m"},
+		{"reset before cut", "\x1b[31mhi\x1b[0m hello world", 8, reset, "\x1b[31mhi\x1b[0m hell…"},
+		{"non-zero parameters", "\x1b[0;31mhello world", 8, reset, "\x1b[0;31mhello w…\x1b[0m"},
+		{"truecolor", "\x1b[38;2;255;0;0mhello world", 8, reset, "\x1b[38;2;255;0;0mhello w…\x1b[0m"},
+		{"no colors", "hello world", 8, reset, "hello w…"},
+		{"cursor sequence only", "\x1b[2Khello world", 8, reset, "\x1b[2Khello w…"},
+		{"fits", "\x1b[31mhello", 8, reset, "\x1b[31mhello"},
+
+		// Without ControlSequences, the reset would count toward the width
+		{"requires ControlSequences", "\x1b[31mhello world", 12, Options{EnsureReset: true}, "\x1b[31mhello w…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.TruncateString(tt.input, tt.maxWidth, "…")
+			if got != tt.expected {
+				t.Errorf("TruncateString(%q, %d) = %q, want %q", tt.input, tt.maxWidth, got, tt.expected)
+			}
+			gotBytes := tt.options.TruncateBytes([]byte(tt.input), tt.maxWidth, []byte("…"))
+			if string(gotBytes) != tt.expected {
+				t.Errorf("TruncateBytes(%q, %d) = %q, want %q", tt.input, tt.maxWidth, gotBytes, tt.expected)
+			}
+			var clusters []string
+			g := tt.options.StringGraphemes(tt.input)
+			for g.Next() {
+				clusters = append(clusters, g.Value())
+			}
+			if got := tt.options.TruncateGraphemes(clusters, tt.maxWidth, "…"); got != tt.expected {
+				t.Errorf("TruncateGraphemes(%q, %d) = %q, want %q", tt.input, tt.maxWidth, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncateBytesInto(t *testing.T) {
+	inputs := []string{
+		"",
+		"hello",
+		"hello world",
+		"中文字符测试",
+		"e\u0301e\u0301e\u0301e\u0301e\u0301e\u0301",
+		"\x1b[31mhello world\x1b[0m",
+		"\x1b[1m\x1b[31mhello\x1b[0m world\x1b[32m!\x1b[0m",
+		"hel\x1b[31mlo",
+		"\x1b[31mhello world",
+	}
+	options := []Options{
+		defaultOptions,
+		controlSequences,
+		eawOptions,
+		{ControlSequences: true, EnsureReset: true},
+		{TruncateKeepFirst: true},
+	}
+
+	// One buffer is reused across all calls
+	buf := []byte("stale")
+	for _, o := range options {
+		for _, input := range inputs {
+			for maxWidth := 0; maxWidth <= 12; maxWidth++ {
+				want := o.TruncateBytes([]byte(input), maxWidth, []byte("…"))
+				buf = o.TruncateBytesInto(buf[:0], []byte(input), maxWidth, []byte("…"))
+				if !bytes.Equal(buf, want) {
+					t.Errorf("TruncateBytesInto(%q, %d) = %q, want %q", input, maxWidth, buf, want)
+				}
+			}
+		}
+	}
+
+	// The result is appended to dst
+	got := TruncateBytesInto([]byte("> "), []byte("hello world"), 8, []byte("..."))
+	if want := "> hello..."; string(got) != want {
+		t.Errorf("TruncateBytesInto() = %q, want %q", got, want)
+	}
+
+	// A reset is only added for colors in the appended portion
+	reset := Options{ControlSequences: true, EnsureReset: true}
+	got = reset.TruncateBytesInto([]byte("\x1b[31m"), []byte("hello world"), 8, []byte("…"))
+	if want := "\x1b[31mhello w…"; string(got) != want {
+		t.Errorf("TruncateBytesInto() = %q, want %q", got, want)
+	}
+}
+
+// TestTruncateContext tests truncation with options whose widths depend on
+// context, such as TabWidth, for which the kept text must be measured as
+// String measures it, rather than cluster by cluster.
+func TestTruncateContext(t *testing.T) {
+	tab4 := Options{TabWidth: 4}
+	tab8 := Options{TabWidth: 8}
+	contextual := Options{ContextualAmbiguous: true}
+	cjk := Options{AmbiguousInCJKContext: true}
+
+	tests := []struct {
+		name     string
+		options  Options
+		input    string
+		maxWidth int
+		tail     string
+		expected string
+	}{
+		{"tab fits", tab8, "a\tb", 9, "", "a\tb"},
+		{"tab", tab8, "a\tb", 5, "", "a"},
+		{"tab at the cut", tab8, "ab\tc", 8, "…", "ab…"},
+		{"tab kept", tab4, "a\tbc", 5, "…", "a\t…"},
+		{"tabs", tab4, "a\tb\tc", 8, "…", "a\tb…"},
+		{"tab after CJK", tab4, "中\tab", 5, "…", "中\t…"},
+		{"tab after escape text", tab4, "\x1b[1m\tab", 5, "", "\x1b[1m\ta"},
+		{"tab with escapes", Options{TabWidth: 4, ControlSequences: true}, "\x1b[31ma\tbc\x1b[0m", 5, "…", "\x1b[31ma\t…\x1b[0m"},
+
+		// With ContextualAmbiguous, ambiguous characters after wide ones are
+		// wide, including an ambiguous tail
+		{"ambiguous after wide", contextual, "××漢××", 6, "", "××漢×"},
+		{"ambiguous after wide cut", contextual, "××漢××", 5, "", "××漢"},
+		{"ambiguous fits", contextual, "××漢××", 8, "", "××漢××"},
+		{"ambiguous tail after wide", contextual, "漢字×××", 5, "…", "漢…"},
+		{"ambiguous tail after narrow", contextual, "漢字a××", 6, "…", "漢字a…"},
+
+		// With AmbiguousInCJKContext, an ambiguous character takes its width
+		// from both neighbors, so the kept one may narrow at the cut
+		{"CJK context after", cjk, "漢×××××", 3, "", "漢"},
+		{"CJK context before", cjk, "×漢", 3, "", "×"},
+		{"CJK context fits", cjk, "×漢", 4, "", "×漢"},
+		{"CJK context ambiguous tail", cjk, "a×漢b", 4, "…", "a×…"},
+		{"CJK context CJK tail", cjk, "×abc", 3, "字", "字"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.TruncateString(tt.input, tt.maxWidth, tt.tail)
+			if got != tt.expected {
+				t.Errorf("TruncateString(%q, %d, %q) = %q, want %q", tt.input, tt.maxWidth, tt.tail, got, tt.expected)
+			}
+			if w := tt.options.String(got); w > tt.maxWidth {
+				t.Errorf("TruncateString(%q, %d, %q) has width %d", tt.input, tt.maxWidth, tt.tail, w)
+			}
+			if got := tt.options.TruncateBytes([]byte(tt.input), tt.maxWidth, []byte(tt.tail)); string(got) != tt.expected {
+				t.Errorf("TruncateBytes(%q, %d, %q) = %q, want %q", tt.input, tt.maxWidth, tt.tail, got, tt.expected)
+			}
+
+			// TruncationOffset takes the width of the tail on its own, which
+			// holds unless the tail is wider where it is placed
+			tailWidth := tt.options.String(tt.tail)
+			offset := tt.options.TruncationOffset(tt.input, tt.maxWidth, tailWidth)
+			kept := tt.input[:offset]
+			if offset < len(tt.input) && tt.options.String(kept+tt.tail) == tt.options.String(kept)+tailWidth &&
+				!strings.HasPrefix(tt.expected, kept+tt.tail) {
+				t.Errorf("TruncationOffset(%q, %d) = %d, want the cut of %q", tt.input, tt.maxWidth, offset, tt.expected)
+			}
+		})
+	}
+
+	inputs := []string{
+		"a\tb\tc", "ab\tcd\tef gh\tij", "中\t文\ta b", "\x1b[31ma\tb\x1b[0m\tc d",
+		"××漢××", "漢字××× ×漢×", "漢 ×× 漢 ××", "a×漢\t×b",
+	}
+	for _, options := range []Options{tab4, tab8, {TabWidth: 4, ControlSequences: true}, contextual, {ContextualAmbiguous: true, TabWidth: 4}, cjk, {AmbiguousInCJKContext: true, TabWidth: 4}} {
+		testTruncationFits(t, options, inputs)
+	}
+}
+
+// testTruncationFits checks that each truncation function keeps each input
+// within every maxWidth that the tail fits in, with the given options.
+func testTruncationFits(t *testing.T, options Options, inputs []string) {
+	t.Helper()
+	const tail = "…"
+
+	for _, input := range inputs {
+		for maxWidth := options.String(tail); maxWidth < options.String(input); maxWidth++ {
+			results := map[string]string{
+				"TruncateString":     options.TruncateString(input, maxWidth, tail),
+				"TruncateBytes":      string(options.TruncateBytes([]byte(input), maxWidth, []byte(tail))),
+				"TruncateWords":      options.TruncateWords(input, maxWidth, tail),
+				"TruncateLines":      options.TruncateLines(input, maxWidth, tail),
+				"TruncateRightAlign": options.TruncateRightAlign(input, maxWidth, tail),
+				"TruncateCountTail":  options.TruncateCountTail(input, maxWidth),
+				"TruncateGraphemes":  options.TruncateGraphemes(clustersOf(input), maxWidth, tail),
+				"JoinTruncate":       options.JoinTruncate(strings.Split(input, " "), " ", maxWidth, tail),
+			}
+			for name, got := range results {
+				if w := options.String(got); w > maxWidth {
+					t.Errorf("%s(%q, %d) with %+v = %q, width %d", name, input, maxWidth, options, got, w)
+				}
+			}
+
+			if offset := options.TruncationOffset(input, maxWidth, 1); options.String(input[:offset])+1 > maxWidth {
+				t.Errorf("TruncationOffset(%q, %d, 1) with %+v = %d, too wide", input, maxWidth, options, offset)
+			}
+		}
+	}
+}
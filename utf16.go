@@ -0,0 +1,40 @@
+package displaywidth
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// StringUTF16 calculates the display width of UTF-16 text, such as from
+// Windows APIs or JavaScript. See [Options.StringUTF16].
+func StringUTF16(u []uint16) int {
+	return DefaultOptionsValue().StringUTF16(u)
+}
+
+// StringUTF16 calculates the display width of UTF-16 text, for the given
+// options. Surrogate pairs are combined into runes, and the runes into
+// grapheme clusters, so for valid UTF-16 the width equals [Options.String] of
+// the decoded text.
+//
+// An unpaired surrogate is width 1, as is invalid UTF-8 in [Options.String].
+func (options Options) StringUTF16(u []uint16) int {
+	// Grapheme segmentation operates on UTF-8, so transcode. An unpaired
+	// surrogate becomes a single invalid byte, which is width 1.
+	buf := make([]byte, 0, len(u)*3)
+	for i := 0; i < len(u); i++ {
+		r := rune(u[i])
+		if utf16.IsSurrogate(r) {
+			if i+1 < len(u) {
+				if pair := utf16.DecodeRune(r, rune(u[i+1])); pair != utf8.RuneError {
+					buf = utf8.AppendRune(buf, pair)
+					i++
+					continue
+				}
+			}
+			buf = append(buf, 0xFF)
+			continue
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return options.Bytes(buf)
+}
@@ -0,0 +1,61 @@
+package displaywidth
+
+import (
+	"testing"
+	"unicode/utf16"
+)
+
+func TestStringUTF16(t *testing.T) {
+	inputs := []string{
+		"",
+		"hello world",
+		"中文字符",
+		"é",
+		"😀",           // surrogate pair
+		"👩‍💻",         // ZWJ sequence of surrogate pairs
+		"👍\U0001F3FD", // modifier
+		"🇺🇸🇯🇵",        // flags
+		"\U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", // tag sequence
+		"1️⃣",
+		"\U00020000\U0002A6D6", // CJK Extension B
+		"★→",
+		"\x1b[31mred\x1b[0m",
+		"a\tb\r\nc",
+	}
+	options := []Options{defaultOptions, eawOptions, controlSequences, {TabWidth: 4}}
+
+	for _, opt := range options {
+		for _, input := range inputs {
+			u := utf16.Encode([]rune(input))
+			if got, want := opt.StringUTF16(u), opt.String(input); got != want {
+				t.Errorf("StringUTF16(%q) with options %+v = %d, want %d", input, opt, got, want)
+			}
+		}
+	}
+
+	// Unpaired surrogates are width 1
+	unpaired := []struct {
+		name     string
+		input    []uint16
+		expected int
+	}{
+		{"lone high", []uint16{0xD83D}, 1},
+		{"lone low", []uint16{0xDE00}, 1},
+		{"high at end", []uint16{'a', 0xD83D}, 2},
+		{"high then ASCII", []uint16{0xD83D, 'a'}, 2},
+		{"low then high", []uint16{0xDE00, 0xD83D}, 2},
+		{"lone high then pair", []uint16{0xD83D, 0xD83D, 0xDE00}, 1 + 2},
+		{"lone high EAW", []uint16{0xD83D}, 1},
+	}
+
+	for _, tt := range unpaired {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StringUTF16(tt.input); got != tt.expected {
+				t.Errorf("StringUTF16(%#x) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := eawOptions.StringUTF16(tt.input); got != tt.expected {
+				t.Errorf("StringUTF16(%#x) with EastAsianWidth = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package displaywidth
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/clipperhouse/uax29/v2/graphemes"
@@ -12,14 +15,33 @@ import (
 // by iterating over grapheme clusters in the string
 // and summing their widths.
 func String(s string) int {
-	return DefaultOptions.String(s)
+	return DefaultOptionsValue().String(s)
 }
 
 // String calculates the display width of a string, for the given options, by
 // iterating over grapheme clusters in the string and summing their widths.
 func (options Options) String(s string) int {
-	width := 0
-	pos := 0
+	// Printable ASCII is one column per byte, whatever the options, so a
+	// string of only printable ASCII needs no further work
+	ascii := printableASCIILength(s)
+	if ascii == len(s) {
+		return ascii
+	}
+
+	s = untilNUL(s, options)
+
+	if options.contextual() {
+		g := graphemes.FromString(s)
+		g.AnsiEscapeSequences = options.ControlSequences || options.DECGraphics
+		g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+		width, _ := contextualWidth(g, 0, options)
+		return width
+	}
+
+	// Continue after the printable ASCII prefix, which StopAtNUL never cuts
+	width := ascii
+	pos := ascii
+	tables := !options.clusterOptions()
 
 	for pos < len(s) {
 		// Try ASCII optimization
@@ -39,7 +61,11 @@ func (options Options) String(s string) int {
 
 		for g.Next() {
 			v := g.Value()
-			width += graphemeWidth(v, options)
+			if tables {
+				width += tableWidth(v, options.EastAsianWidth, options.ControlSequences8Bit)
+			} else {
+				width += graphemeWidth(v, options)
+			}
 			pos += len(v)
 
 			// Quick check: if remaining might have printable ASCII, break to outer loop
@@ -63,14 +89,33 @@ func (options Options) String(s string) int {
 // by iterating over grapheme clusters in the byte slice
 // and summing their widths.
 func Bytes(s []byte) int {
-	return DefaultOptions.Bytes(s)
+	return DefaultOptionsValue().Bytes(s)
 }
 
 // Bytes calculates the display width of a []byte, for the given options, by
 // iterating over grapheme clusters in the slice and summing their widths.
 func (options Options) Bytes(s []byte) int {
-	width := 0
-	pos := 0
+	// Printable ASCII is one column per byte, whatever the options, so a
+	// string of only printable ASCII needs no further work
+	ascii := printableASCIILength(s)
+	if ascii == len(s) {
+		return ascii
+	}
+
+	s = untilNUL(s, options)
+
+	if options.contextual() {
+		g := graphemes.FromBytes(s)
+		g.AnsiEscapeSequences = options.ControlSequences || options.DECGraphics
+		g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+		width, _ := contextualWidth(g, 0, options)
+		return width
+	}
+
+	// Continue after the printable ASCII prefix, which StopAtNUL never cuts
+	width := ascii
+	pos := ascii
+	tables := !options.clusterOptions()
 
 	for pos < len(s) {
 		// Try ASCII optimization
@@ -90,7 +135,11 @@ func (options Options) Bytes(s []byte) int {
 
 		for g.Next() {
 			v := g.Value()
-			width += graphemeWidth(v, options)
+			if tables {
+				width += tableWidth(v, options.EastAsianWidth, options.ControlSequences8Bit)
+			} else {
+				width += graphemeWidth(v, options)
+			}
 			pos += len(v)
 
 			// Quick check: if remaining might have printable ASCII, break to outer loop
@@ -110,6 +159,263 @@ func (options Options) Bytes(s []byte) int {
 	return width
 }
 
+// contextual reports whether the options require tracking context across
+// grapheme clusters, such as the current column.
+func (options Options) contextual() bool {
+	return options.TabWidth > 0 || options.DECGraphics || options.ContextualAmbiguous || options.AmbiguousInCJKContext
+}
+
+// contextualWidth sums the widths of the grapheme clusters of g, tracking the
+// column for options whose widths depend on context, such as TabWidth. col is
+// the starting column. It returns the total width and the ending column.
+//
+// When DECGraphics is true, g must segment 7-bit escape sequences.
+func contextualWidth[T ~string | ~[]byte](g *graphemes.Iterator[T], col int, options Options) (width, endCol int) {
+	width, state := contextualWidthFrom(g, contextState{col: col}, options)
+	return width, state.col
+}
+
+// contextState is the context that contextualWidth carries from one grapheme
+// cluster to the next.
+type contextState struct {
+	// col is the current column
+	col int
+	// wide indicates that the previous visible cluster was wide, for
+	// ContextualAmbiguous
+	wide bool
+	// cjk indicates that the previous visible cluster was East Asian Wide,
+	// and pending that it was ambiguous and awaits its next neighbor, for
+	// AmbiguousInCJKContext
+	cjk, pending bool
+}
+
+// contextualWidthFrom is contextualWidth, starting from the given state, such
+// as that of the preceding text. It returns the total width and the state
+// after g.
+func contextualWidthFrom[T ~string | ~[]byte](g *graphemes.Iterator[T], state contextState, options Options) (width int, end contextState) {
+	col, wide := state.col, state.wide
+	cjk, pending := state.cjk, state.pending
+
+	for g.Next() {
+		v := g.Value()
+
+		var w int
+		eastAsian := false
+		isCJK, ambiguous := false, false
+		switch {
+		case options.CaretNotation && isCaretControl(v[0]) && (len(v) == 1 || isNewline(v)):
+			// Tabs and newlines in caret notation are ordinary characters
+			w = graphemeWidth(v, options)
+		case len(v) == 1 && v[0] == '\t' && options.TabWidth > 0:
+			w = options.TabWidth - col%options.TabWidth
+		case isNewline(v) || (len(v) == 1 && v[0] == '\r'), options.VerticalControls && len(v) == 1 && (v[0] == '\v' || v[0] == '\f'):
+			col = 0
+			wide = false
+			cjk, pending = false, false
+			continue
+		case options.DECGraphics && len(v) > 1 && v[0] == 0x1B:
+			switch {
+			case isSCS(v):
+				w = 0
+			case options.ControlSequences:
+				w = graphemeWidth(v, options)
+			default:
+				// Any other escape is measured as the printable text it
+				// would be without ControlSequences
+				text := options
+				text.DECGraphics = false
+				text.TabWidth = 0
+				w = Width(text, v)
+			}
+		default:
+			w = graphemeWidth(v, options)
+			switch {
+			case options.AmbiguousInCJKContext && isAmbiguousCluster(v, w, options):
+				ambiguous = true
+				w = 1
+				if cjk {
+					w = 2
+				}
+			case options.AmbiguousInCJKContext:
+				isCJK = isEastAsianWideCluster(v)
+			case options.ContextualAmbiguous && wide && w == 1 && isAmbiguousCluster(v, w, options):
+				w = 2
+			}
+			eastAsian = w == 2
+		}
+
+		if w > 0 {
+			wide = eastAsian
+			if pending && isCJK {
+				// The previous ambiguous cluster is followed by CJK
+				width++
+				col++
+			}
+			cjk, pending = isCJK, ambiguous && !cjk
+		}
+		width += w
+		col += w
+	}
+	return width, contextState{col: col, wide: wide, cjk: cjk, pending: pending}
+}
+
+// widthFrom returns the width of s following text that ended in the given
+// state, such as the text before a truncation point, and the state after s.
+// The state is tracked only for options that depend on context, such as
+// TabWidth or ContextualAmbiguous.
+func widthFrom[T ~string | ~[]byte](s T, state contextState, options Options) (width int, end contextState) {
+	if !options.contextual() {
+		return Width(options, s), contextState{}
+	}
+
+	escapes := options.ControlSequences || options.DECGraphics
+	if b, ok := any(s).([]byte); ok {
+		g := graphemes.FromBytes(b)
+		g.AnsiEscapeSequences = escapes
+		g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+		return contextualWidthFrom(g, state, options)
+	}
+	g := graphemes.FromString(string(s))
+	g.AnsiEscapeSequences = escapes
+	g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+	return contextualWidthFrom(g, state, options)
+}
+
+// Width calculates the display width of a string or []byte, for the given
+// options, by iterating over grapheme clusters and summing their widths.
+//
+// It is a generic alternative to [Options.String] and [Options.Bytes], for
+// callers whose own code is generic over string and []byte. Go methods cannot
+// have type parameters, so the options are passed as the first argument; use
+// [DefaultOptions] for the default behavior.
+//
+// Named types whose underlying type is []byte are converted to a string,
+// which allocates.
+func Width[T ~string | ~[]byte](options Options, s T) int {
+	if b, ok := any(s).([]byte); ok {
+		return options.Bytes(b)
+	}
+	return options.String(string(s))
+}
+
+// StringerWidth calculates the display width of v.String(). See
+// [Options.StringerWidth].
+func StringerWidth(v fmt.Stringer) int {
+	return DefaultOptionsValue().StringerWidth(v)
+}
+
+// StringerWidth calculates the display width of v.String(), for the given
+// options, for values such as those passed to logging code. The string is
+// produced by v, which may allocate it.
+func (options Options) StringerWidth(v fmt.Stringer) int {
+	return options.String(v.String())
+}
+
+// UpperWidth calculates the display width of s after converting it to upper
+// case. See [Options.UpperWidth].
+func UpperWidth(s string) int {
+	return DefaultOptionsValue().UpperWidth(s)
+}
+
+// UpperWidth calculates the display width of strings.ToUpper(s), for the
+// given options, such as to preview upper-cased output. It allocates the
+// upper-cased string.
+//
+// The width can differ from that of s where the cases differ in East Asian
+// Width: with EastAsianWidth, é is ambiguous and width 2, but É is width 1.
+// strings.ToUpper maps each rune to a single rune, so ß and ligatures such
+// as ﬁ are unchanged, rather than expanded to "SS" and "FI" as in full case
+// mapping.
+func (options Options) UpperWidth(s string) int {
+	return options.String(strings.ToUpper(s))
+}
+
+// EndColumn returns the column after rendering s starting at column
+// startCol. See [Options.EndColumn].
+func EndColumn(startCol int, s string) int {
+	return DefaultOptionsValue().EndColumn(startCol, s)
+}
+
+// EndColumn returns the column after rendering s starting at column
+// startCol, with the given options, for appending text to an existing line.
+// Columns are zero-based.
+//
+// Unlike the width of s, the column depends on startCol when TabWidth is
+// positive, since a tab advances to the next tab stop after startCol. A
+// newline or carriage return resets the column to 0, so the result is the
+// column on the last line of s.
+func (options Options) EndColumn(startCol int, s string) int {
+	s = untilNUL(s, options)
+
+	g := graphemes.FromString(s)
+	g.AnsiEscapeSequences = options.ControlSequences || options.DECGraphics
+	g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+
+	_, col := contextualWidth(g, startCol, options)
+	return col
+}
+
+// WidthAndEmoji calculates the display width of a string, and reports
+// whether it contains an emoji, in a single pass. See [Options.WidthAndEmoji].
+func WidthAndEmoji(s string) (width int, hasEmoji bool) {
+	return DefaultOptionsValue().WidthAndEmoji(s)
+}
+
+// WidthAndEmoji calculates the display width of a string, for the given
+// options, and reports whether it contains an emoji, in a single pass. The
+// width equals [Options.String].
+//
+// An emoji is a grapheme cluster that [Options.ExplainString], for the zero
+// Options, classifies as [ReasonEmoji], [ReasonEmojiVS16] or
+// [ReasonRegionalIndicator]: an Extended_Pictographic character with emoji
+// presentation, including ZWJ and modifier sequences; a character promoted
+// to emoji presentation by VS16, including keycaps; or a flag or lone
+// regional indicator. Options that change the width of emoji, such as
+// EmojiSet or HonorVS15, do not change the classification.
+func (options Options) WidthAndEmoji(s string) (width int, hasEmoji bool) {
+	s = untilNUL(s, options)
+
+	// When width depends on context, it is measured separately below
+	measure := !options.contextual()
+
+	for pos := 0; pos < len(s); {
+		// ASCII is never emoji
+		if asciiLen := printableASCIILength(s[pos:]); asciiLen > 0 {
+			width += asciiLen
+			pos += asciiLen
+			continue
+		}
+
+		g := graphemes.FromString(s[pos:])
+		g.AnsiEscapeSequences = options.ControlSequences
+		g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+
+		start := pos
+		for g.Next() {
+			v := g.Value()
+			if measure {
+				width += graphemeWidth(v, options)
+			}
+			if !hasEmoji && len(v) > 1 {
+				hasEmoji = isEmojiCluster(v)
+			}
+			pos += len(v)
+
+			if pos < len(s) && s[pos] >= 0x20 && s[pos] <= 0x7E {
+				break
+			}
+		}
+		if pos == start {
+			pos++
+		}
+	}
+
+	if !measure {
+		width = options.String(s)
+	}
+	return width, hasEmoji
+}
+
 // Rune calculates the display width of a rune. You
 // should almost certainly use [String] or [Bytes] for
 // most purposes.
@@ -118,7 +424,7 @@ func (options Options) Bytes(s []byte) int {
 // cluster, not a rune. Iterating over runes to measure
 // width is incorrect in many cases.
 func Rune(r rune) int {
-	return DefaultOptions.Rune(r)
+	return DefaultOptionsValue().Rune(r)
 }
 
 // Rune calculates the display width of a rune, for the given options.
@@ -129,6 +435,9 @@ func Rune(r rune) int {
 // Iterating over runes to measure width is incorrect in many cases.
 func (options Options) Rune(r rune) int {
 	if r < utf8.RuneSelf {
+		if options.CaretNotation && isCaretControl(byte(r)) {
+			return 2
+		}
 		return asciiWidth(byte(r))
 	}
 
@@ -144,9 +453,95 @@ func (options Options) Rune(r rune) int {
 	return graphemeWidth(buf[:n], options)
 }
 
+// ClusterWidth calculates the display width of rs, treated as a single
+// grapheme cluster. See [Options.ClusterWidth].
+func ClusterWidth(rs []rune) int {
+	return DefaultOptionsValue().ClusterWidth(rs)
+}
+
+// ClusterWidth calculates the display width of rs, for the given options,
+// treating the runes as a single grapheme cluster, such as a composed
+// character held by an input method. Unlike measuring each rune with
+// [Options.Rune], it applies the logic of [Options.String] to the cluster as
+// a whole, so a VS16 sequence, a flag or an emoji modifier sequence is width
+// 2, and a cluster of only zero-width runes is width 0.
+//
+// When rs is a single grapheme cluster, the result equals
+// String(string(rs)); otherwise, the width is that of a cluster led by
+// rs[0]. Invalid runes, such as surrogates, are measured as U+FFFD, as in
+// string(rs). Options that depend on context, such as TabWidth, are ignored.
+func (options Options) ClusterWidth(rs []rune) int {
+	var buf [32]byte
+	b := buf[:0]
+	for _, r := range rs {
+		b = utf8.AppendRune(b, r)
+	}
+	return graphemeWidth(b, options)
+}
+
+// ErrInvalidRune is returned by [Options.RuneWidthStrict] for a rune that is
+// not a valid Unicode scalar value.
+var ErrInvalidRune = errors.New("displaywidth: invalid rune")
+
+// RuneWidthStrict calculates the display width of a rune, and returns an
+// error for an invalid rune. See [Options.RuneWidthStrict].
+func RuneWidthStrict(r rune) (int, error) {
+	return DefaultOptionsValue().RuneWidthStrict(r)
+}
+
+// RuneWidthStrict calculates the display width of a rune, for the given
+// options, as [Options.Rune]. Unlike Rune, which returns 0 for both, it
+// distinguishes a rune of zero width from an invalid one: surrogates
+// (U+D800-U+DFFF), negative runes and runes above [unicode.MaxRune] return
+// an error wrapping [ErrInvalidRune].
+func (options Options) RuneWidthStrict(r rune) (int, error) {
+	if !utf8.ValidRune(r) {
+		return 0, fmt.Errorf("%w: %U", ErrInvalidRune, r)
+	}
+	return options.Rune(r), nil
+}
+
+// ByteWidth returns the display width of a single byte, for callers that
+// build their own fast paths over known-ASCII data. It does not depend on
+// options.
+//
+// Control characters (0x00-0x1F) and DEL (0x7F) are width 0, and printable
+// ASCII (0x20-0x7E) is width 1. The result is only meaningful for bytes
+// below 0x80, which are single-byte UTF-8; other bytes are part of a
+// multi-byte rune, or invalid, and are width 1, as a lone invalid byte is in
+// [String].
+func ByteWidth(b byte) int {
+	return asciiWidth(b)
+}
+
 // graphemeWidth returns the display width of a grapheme cluster.
 // The passed string must be a single grapheme cluster.
 func graphemeWidth[T ~string | ~[]byte](s T, options Options) int {
+	if options.clusterOptions() {
+		width, _ := graphemeWidthReason(s, options)
+		return width
+	}
+	return tableWidth(s, options.EastAsianWidth, options.ControlSequences8Bit)
+}
+
+// clusterOptions reports whether any option other than EastAsianWidth,
+// ControlSequences and ControlSequences8Bit changes the width of a grapheme
+// cluster on its own. Without them, [tableWidth] measures the cluster.
+func (options Options) clusterOptions() bool {
+	return options.MaxClusterBytes > 0 || options.MaxCombiningPerCluster > 0 ||
+		options.VisibleJoiners || options.WideEnclosingMarks || options.EmojiFallback ||
+		options.OSCFilter != nil || options.CaretNotation || options.SplitFlags ||
+		options.CombiningOverride != nil || options.UnknownWidth > 0 ||
+		options.PrivateUseWidth > 0 || options.HalfwidthAsWide || options.EmojiSet != nil ||
+		options.HonorVS15 || options.EmojiWidth > 0 || options.NeutralAsWide ||
+		options.NarrowAmbiguous != nil
+}
+
+// tableWidth returns the display width of a grapheme cluster according to
+// the Unicode tables, for options that set only EastAsianWidth and the
+// control sequence options. It is the common case of graphemeWidthReason,
+// without the checks for other options, and must agree with it.
+func tableWidth[T ~string | ~[]byte](s T, eastAsianWidth, controlSequences8Bit bool) int {
 	if len(s) == 0 {
 		return 0
 	}
@@ -154,7 +549,7 @@ func graphemeWidth[T ~string | ~[]byte](s T, options Options) int {
 	// C1 controls (0x80-0x9F) are zero-width when 8-bit control sequences
 	// are enabled. This must be checked before the single-byte optimization
 	// below, which would otherwise return width 1 for these bytes.
-	if options.ControlSequences8Bit && s[0] >= 0x80 && s[0] <= 0x9F {
+	if controlSequences8Bit && s[0] >= 0x80 && s[0] <= 0x9F {
 		return 0
 	}
 
@@ -171,7 +566,14 @@ func graphemeWidth[T ~string | ~[]byte](s T, options Options) int {
 	p, sz := lookup(s)
 	prop := property(p)
 
+	// A cluster with emoji modifiers that do not follow an emoji is as wide
+	// as an emoji, since the rest of it is narrower; see
+	// graphemeWidthReason. The check is needed only where the cluster would
+	// otherwise be narrower.
 	if prop.is(_Zero_Width) {
+		if len(s) >= 5 && hasLoneModifier(s) {
+			return 2
+		}
 		return 0
 	}
 
@@ -179,20 +581,237 @@ func graphemeWidth[T ~string | ~[]byte](s T, options Options) int {
 		return 2
 	}
 
-	if options.EastAsianWidth && prop.is(_East_Asian_Ambiguous) {
+	if eastAsianWidth && prop.is(_East_Asian_Ambiguous) {
 		return 2
 	}
 
-	if prop.is(_VS16_Eligible) && sz > 0 && len(s) >= sz+3 && isVS16(s[sz:sz+3]) {
+	if prop.is(_VS16_Eligible) && sz > 0 && isVS16(s, sz) {
 		return 2
 	}
 	if hasEligibleVS16Pair(s, sz+1) {
 		return 2
 	}
 
+	if sz > 0 && isEmojiModifier(s, sz) && unicode.Is(emojiModifierBase, decodeRune(s)) {
+		return 2
+	}
+
+	if len(s) >= 5 && hasLoneModifier(s) {
+		return 2
+	}
+
 	return 1
 }
 
+// graphemeWidthReason returns the display width of a grapheme cluster, and
+// the reason for it, which [Options.ExplainString] reports. The passed string
+// must be a single grapheme cluster.
+func graphemeWidthReason[T ~string | ~[]byte](s T, options Options) (int, Reason) {
+	if len(s) == 0 {
+		return 0, ReasonZeroWidth
+	}
+
+	if options.MaxClusterBytes > 0 && len(s) > options.MaxClusterBytes {
+		return clusterPiecesWidth(s, options)
+	}
+
+	if options.MaxCombiningPerCluster > 0 && len(s) > 2 {
+		if extra := combiningOverflow(s, options.MaxCombiningPerCluster); extra > 0 {
+			options.MaxCombiningPerCluster = 0
+			return graphemeWidth(s, options) + extra, ReasonOption
+		}
+	}
+
+	if options.VisibleJoiners && len(s) > 2 {
+		if w, ok := visibleJoinersWidth(s, options); ok {
+			return w, ReasonOption
+		}
+	}
+
+	if options.WideEnclosingMarks && len(s) > 2 && hasEnclosingMark(s) {
+		options.WideEnclosingMarks = false
+		if w, reason := graphemeWidthReason(s, options); w == 0 {
+			return 0, reason
+		} else {
+			return w + 1, ReasonOption
+		}
+	}
+
+	if options.EmojiFallback && len(s) > 4 {
+		if w, ok := emojiFallbackWidth(s, options); ok {
+			return w, ReasonOption
+		}
+	}
+
+	if options.OSCFilter != nil && len(s) > 1 {
+		if payload, ok := oscPayload(s); ok && !options.OSCFilter.ZeroWidth([]byte(payload)) {
+			text := options
+			text.ControlSequences = false
+			text.ControlSequences8Bit = false
+			return Width(text, s), ReasonEscapeSequence
+		}
+	}
+
+	// C1 controls (0x80-0x9F) are zero-width when 8-bit control sequences
+	// are enabled. This must be checked before the single-byte optimization
+	// below, which would otherwise return width 1 for these bytes.
+	if options.ControlSequences8Bit && s[0] >= 0x80 && s[0] <= 0x9F {
+		if len(s) > 1 {
+			return 0, ReasonEscapeSequence
+		}
+		return 0, ReasonControl
+	}
+
+	// Controls in caret notation are 2 wide, including each of CRLF
+	if options.CaretNotation && isCaretControl(s[0]) && (len(s) == 1 || isNewline(s)) {
+		return 2 * len(s), ReasonControl
+	}
+
+	// Optimization: single-byte graphemes need no property lookup
+	if len(s) == 1 {
+		switch {
+		case s[0] >= utf8.RuneSelf:
+			return 1, ReasonInvalidUTF8
+		case asciiWidth(s[0]) == 0:
+			return 0, ReasonControl
+		}
+		return 1, ReasonNarrow
+	}
+
+	// Multi-byte grapheme clusters led by a C0 control (0x00-0x1F)
+	if s[0] <= 0x1F {
+		if s[0] == 0x1B && options.ControlSequences {
+			return 0, ReasonEscapeSequence
+		}
+		return 0, ReasonControl
+	}
+
+	// Emoji modifiers that do not follow an emoji are shown as an emoji,
+	// although the segmenter attaches them to the cluster. The cluster
+	// occupies one cell group, so it is as wide as an emoji, or as the rest
+	// of the cluster if that is wider, and never wider than 2 by default.
+	if len(s) >= 5 && hasLoneModifier(s) {
+		if rest, lone := loneModifiers(s); lone > 0 {
+			if w, reason := graphemeWidthReason(rest, options); w > options.emojiWidth() {
+				return w, reason
+			}
+			return options.emojiWidth(), ReasonEmoji
+		}
+	}
+
+	if options.SplitFlags && isRegionalIndicator(s, 0) && isRegionalIndicator(s, 4) {
+		return 2 * options.emojiWidth(), ReasonOption
+	}
+
+	p, sz := lookup(s)
+	prop := property(p)
+
+	if options.CombiningOverride != nil && unicode.Is(options.CombiningOverride, decodeRune(s)) {
+		return 0, ReasonZeroWidth
+	}
+
+	if options.UnknownWidth > 0 && prop == 0 && isUnassignedPlane(s) {
+		return options.UnknownWidth, ReasonOption
+	}
+
+	if options.PrivateUseWidth > 0 && isPrivateUse(decodeRune(s)) {
+		return options.PrivateUseWidth, ReasonOption
+	}
+
+	if prop.is(_Zero_Width) {
+		if unicode.IsControl(decodeRune(s)) {
+			return 0, ReasonControl
+		}
+		return 0, ReasonZeroWidth
+	}
+
+	if options.HalfwidthAsWide && isHalfwidth(decodeRune(s)) {
+		return 2, ReasonOption
+	}
+
+	// Emoji outside of EmojiSet are narrow
+	narrowEmoji := options.EmojiSet != nil && !inEmojiSet(s, options.EmojiSet)
+
+	if prop.is(_Wide) {
+		if narrowEmoji {
+			return 1, ReasonNarrow
+		}
+		if options.HonorVS15 && sz > 0 && isVS15(s, sz) && unicode.Is(extendedPictographic, decodeRune(s)) {
+			return 1, ReasonNarrow
+		}
+		switch {
+		case isRegionalIndicator(s, 0):
+			return options.emojiWidth(), ReasonRegionalIndicator
+		case unicode.Is(extendedPictographic, decodeRune(s)):
+			return options.emojiWidth(), ReasonEmoji
+		}
+		return 2, ReasonEastAsianWide
+	}
+
+	if options.EastAsianWidth && prop.is(_East_Asian_Ambiguous) && !isNarrowAmbiguous(s, options.NarrowAmbiguous) {
+		return 2, ReasonEastAsianAmbiguous
+	}
+
+	if narrowEmoji {
+		return 1, ReasonNarrow
+	}
+
+	if prop.is(_VS16_Eligible) && sz > 0 && isVS16(s, sz) {
+		return options.emojiWidth(), ReasonEmojiVS16
+	}
+	if hasEligibleVS16Pair(s, sz+1) {
+		return options.emojiWidth(), ReasonEmojiVS16
+	}
+
+	// An emoji modifier sequence has emoji presentation, even if its base
+	// defaults to text presentation
+	if sz > 0 && isEmojiModifier(s, sz) && unicode.Is(emojiModifierBase, decodeRune(s)) {
+		return options.emojiWidth(), ReasonEmoji
+	}
+
+	if options.NeutralAsWide && !prop.is(_East_Asian_Ambiguous) && isNeutral(s) {
+		return 2, ReasonOption
+	}
+
+	switch {
+	case prop.is(_East_Asian_Ambiguous):
+		return 1, ReasonEastAsianAmbiguous
+	case sz == 1 && s[0] >= utf8.RuneSelf:
+		return 1, ReasonInvalidUTF8
+	}
+	return 1, ReasonNarrow
+}
+
+// isEastAsianWideCluster reports whether the grapheme cluster v is East
+// Asian Wide or Fullwidth, such as a CJK character, rather than an emoji or
+// a flag. See [Options.AmbiguousInCJKContext].
+func isEastAsianWideCluster[T ~string | ~[]byte](v T) bool {
+	if len(v) < 3 {
+		// Wide code points begin at U+1100
+		return false
+	}
+	p, _ := lookup(v)
+	if !property(p).is(_Wide) {
+		return false
+	}
+	return !isRegionalIndicator(v, 0) && !unicode.Is(extendedPictographic, decodeRune(v))
+}
+
+// isNarrowAmbiguous reports whether the leading rune of the grapheme cluster
+// is in set, which may be nil. See [Options.NarrowAmbiguous].
+func isNarrowAmbiguous[T ~string | ~[]byte](s T, set *unicode.RangeTable) bool {
+	return set != nil && unicode.Is(set, decodeRune(s))
+}
+
+// emojiWidth returns the width of an emoji grapheme cluster. See
+// [Options.EmojiWidth].
+func (options Options) emojiWidth() int {
+	if options.EmojiWidth > 0 {
+		return options.EmojiWidth
+	}
+	return 2
+}
+
 func asciiWidth(b byte) int {
 	if b <= 0x1F || b == 0x7F {
 		return 0
@@ -200,10 +819,97 @@ func asciiWidth(b byte) int {
 	return 1
 }
 
+// clusterPiecesWidth returns the width of a grapheme cluster longer than
+// MaxClusterBytes, and the reason for it, measuring only its leading piece,
+// which is cut at a rune boundary. See [Options.MaxClusterBytes].
+func clusterPiecesWidth[T ~string | ~[]byte](s T, options Options) (int, Reason) {
+	limit := options.MaxClusterBytes
+	options.MaxClusterBytes = 0
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		cut = limit
+	}
+
+	pieces := (len(s) + limit - 1) / limit
+	w, reason := graphemeWidthReason(s[:cut], options)
+	if w == 0 {
+		return 0, reason
+	}
+	return pieces * w, ReasonOption
+}
+
+// combiningOverflow returns the width to add to a grapheme cluster for
+// combining marks beyond max, 1 for each further group of up to max marks.
+// See [Options.MaxCombiningPerCluster].
+func combiningOverflow[T ~string | ~[]byte](s T, max int) int {
+	marks := 0
+	for i, r := range string(s) {
+		// The base is not a mark, even if it is a lone combining mark
+		if i == 0 {
+			continue
+		}
+		if isVariationSelector(r) {
+			continue
+		}
+		if unicode.In(r, unicode.Mn, unicode.Me) {
+			marks++
+		}
+	}
+	if marks <= max {
+		return 0
+	}
+	return (marks - 1) / max
+}
+
+// hasEnclosingMark reports whether the grapheme cluster contains an
+// enclosing mark (Me) after its base, and is not an emoji presentation
+// sequence. See [Options.WideEnclosingMarks].
+func hasEnclosingMark[T ~string | ~[]byte](s T) bool {
+	found := false
+	for i, r := range string(s) {
+		if i == 0 {
+			continue
+		}
+		if r == 0xFE0F {
+			return false
+		}
+		if unicode.Is(unicode.Me, r) {
+			found = true
+		}
+	}
+	return found
+}
+
+// isVariationSelector reports whether r is a variation selector, VS1-VS256.
+func isVariationSelector(r rune) bool {
+	return (r >= 0xFE00 && r <= 0xFE0F) || (r >= 0xE0100 && r <= 0xE01EF)
+}
+
+// isCaretControl reports whether b is a C0 control or DEL, which are
+// displayed as two characters in caret notation.
+func isCaretControl(b byte) bool {
+	return b <= 0x1F || b == 0x7F
+}
+
 // printableASCIILength returns the length of consecutive printable ASCII bytes
 // starting at the beginning of s.
 func printableASCIILength[T ~string | ~[]byte](s T) int {
-	i := 0
+	// Skip 8 bytes at a time, then find the end byte by byte
+	var i int
+	switch v := any(s).(type) {
+	case string:
+		i = printableASCII8Length(v)
+	case []byte:
+		i = printableASCII8Length(v)
+	default:
+		// Named types (underlying type string or []byte)
+		i = printableASCII8Length(s)
+	}
+
 	for ; i < len(s); i++ {
 		b := s[i]
 		// Printable ASCII is 0x20-0x7E (space through tilde)
@@ -222,10 +928,311 @@ func printableASCIILength[T ~string | ~[]byte](s T) int {
 	return i
 }
 
-// isVS16 checks if the slice matches VS16 (U+FE0F) UTF-8 encoding
-// (EF B8 8F). It assumes len(s) >= 3.
-func isVS16[T ~string | ~[]byte](s T) bool {
-	return s[0] == 0xEF && s[1] == 0xB8 && s[2] == 0x8F
+const (
+	lsb = 0x0101010101010101 // the low bit of each byte
+	msb = 0x8080808080808080 // the high bit of each byte
+)
+
+// printableASCII8Length returns the length of the leading 8-byte chunks of s
+// that are entirely printable ASCII. Callers specialize T to string or
+// []byte, so that the compiler combines each chunk into a single load.
+func printableASCII8Length[T ~string | ~[]byte](s T) int {
+	i := 0
+	for ; i+8 <= len(s); i += 8 {
+		c := s[i : i+8]
+		x := uint64(c[0]) | uint64(c[1])<<8 | uint64(c[2])<<16 | uint64(c[3])<<24 |
+			uint64(c[4])<<32 | uint64(c[5])<<40 | uint64(c[6])<<48 | uint64(c[7])<<56
+		if !isPrintableASCII8(x) {
+			break
+		}
+	}
+	return i
+}
+
+// isPrintableASCII8 reports whether all 8 bytes packed in x are printable
+// ASCII (0x20-0x7E), without branching on each byte.
+func isPrintableASCII8(x uint64) bool {
+	// Non-ASCII bytes have the high bit set. The tests below assume that
+	// no byte does.
+	nonASCII := x & msb
+	// Bytes less than 0x20 borrow into their high bit when 0x20 is
+	// subtracted.
+	control := (x - 0x20*lsb) & ^x & msb
+	// DEL bytes become zero when XORed with 0x7F, and zero bytes borrow
+	// into their high bit when 1 is subtracted.
+	y := x ^ (0x7F * lsb)
+	del := (y - lsb) & ^y & msb
+	return nonASCII|control|del == 0
+}
+
+// isVS16 checks if s matches VS16 (U+FE0F) UTF-8 encoding (EF B8 8F) at
+// byte offset i. It compares against s directly, rather than a subslice, to
+// avoid slicing in the hot path.
+func isVS16[T ~string | ~[]byte](s T, i int) bool {
+	return i >= 0 && i+2 < len(s) && s[i] == 0xEF && s[i+1] == 0xB8 && s[i+2] == 0x8F
+}
+
+// isVS15 checks if s matches VS15 (U+FE0E) UTF-8 encoding (EF B8 8E) at
+// byte offset i.
+func isVS15[T ~string | ~[]byte](s T, i int) bool {
+	return i >= 0 && i+2 < len(s) && s[i] == 0xEF && s[i+1] == 0xB8 && s[i+2] == 0x8E
+}
+
+// isEmojiModifier checks if s has an emoji modifier, U+1F3FB to U+1F3FF
+// (F0 9F 8F BB to F0 9F 8F BF), at byte offset i.
+func isEmojiModifier[T ~string | ~[]byte](s T, i int) bool {
+	return i >= 0 && i+3 < len(s) && s[i] == 0xF0 && s[i+1] == 0x9F && s[i+2] == 0x8F && s[i+3] >= 0xBB && s[i+3] <= 0xBF
+}
+
+// hasLoneModifier reports whether the grapheme cluster contains an emoji
+// modifier that does not follow an emoji. It is a quick check on the bytes,
+// before loneModifiers decodes the cluster.
+func hasLoneModifier[T ~string | ~[]byte](s T) bool {
+	for i := 1; i+3 < len(s); i++ {
+		if s[i] != 0xF0 || !isEmojiModifier(s, i) {
+			continue
+		}
+		if !followsEmoji(s, i) {
+			return true
+		}
+		i += 3
+	}
+	return false
+}
+
+// loneModifiers returns the number of emoji modifiers in the grapheme
+// cluster that do not follow an emoji, such as a skin tone after a letter or
+// another modifier, and the cluster without them. An emoji is an
+// Extended_Pictographic or Emoji_Modifier_Base code point. A modifier at
+// the start of the cluster is its base, and is not counted.
+func loneModifiers[T ~string | ~[]byte](s T) (rest string, lone int) {
+	var b []byte
+	start := 0
+	for i, r := range string(s) {
+		if i > 0 && r >= 0x1F3FB && r <= 0x1F3FF && !followsEmoji(s, i) {
+			b = append(b, s[start:i]...)
+			start = i + utf8.RuneLen(r)
+			lone++
+		}
+	}
+	if lone == 0 {
+		return "", 0
+	}
+	b = append(b, s[start:]...)
+	return string(b), lone
+}
+
+// followsEmoji reports whether the rune of s that ends at byte offset i is
+// an emoji that an emoji modifier may follow: an Extended_Pictographic or
+// Emoji_Modifier_Base code point.
+func followsEmoji[T ~string | ~[]byte](s T, i int) bool {
+	// Most emoji are U+1F000 to U+1FFFF, F0 9F xx xx, which emojiSMP covers
+	if i >= 4 && s[i-4] == 0xF0 && s[i-3] == 0x9F && s[i-2]&0xC0 == 0x80 && s[i-1]&0xC0 == 0x80 {
+		n := int(s[i-2]&0x3F)<<6 | int(s[i-1]&0x3F)
+		return emojiSMP[n/64]&(1<<(n%64)) != 0
+	}
+	prev := decodeLastRune(s[:i])
+	return unicode.Is(extendedPictographic, prev) || unicode.Is(emojiModifierBase, prev)
+}
+
+// isRegionalIndicator checks if s has a regional indicator (U+1F1E6 to
+// U+1F1FF, F0 9F 87 A6 to F0 9F 87 BF) at byte offset i.
+func isRegionalIndicator[T ~string | ~[]byte](s T, i int) bool {
+	return i >= 0 && i+3 < len(s) && s[i] == 0xF0 && s[i+1] == 0x9F && s[i+2] == 0x87 && s[i+3] >= 0xA6 && s[i+3] <= 0xBF
+}
+
+// isSCS checks if the slice is a 7-bit SCS (select character set) escape
+// sequence, such as ESC ( 0 or ESC ) B: ESC, an intermediate byte designating
+// one of the G0-G3 character sets, any further intermediate bytes, and a
+// final byte.
+func isSCS[T ~string | ~[]byte](s T) bool {
+	if len(s) < 3 || s[0] != 0x1B {
+		return false
+	}
+	switch s[1] {
+	case '(', ')', '*', '+', '-', '.', '/':
+	default:
+		return false
+	}
+	i := 2
+	for i < len(s)-1 && s[i] >= 0x20 && s[i] <= 0x2F {
+		i++
+	}
+	return i == len(s)-1 && s[i] >= 0x30 && s[i] <= 0x7E
+}
+
+// oscPayload returns the payload of an OSC (operating system command)
+// sequence, without its 7-bit (ESC ]) or 8-bit (0x9D) introducer and its
+// terminator, and reports whether s is an OSC sequence.
+func oscPayload[T ~string | ~[]byte](s T) (T, bool) {
+	switch {
+	case len(s) >= 2 && s[0] == 0x1B && s[1] == ']':
+		s = s[2:]
+	case len(s) >= 1 && s[0] == 0x9D:
+		s = s[1:]
+	default:
+		return s, false
+	}
+
+	switch n := len(s); {
+	case n >= 1 && (s[n-1] == 0x07 || s[n-1] == 0x9C):
+		s = s[:n-1]
+	case n >= 2 && s[n-2] == 0x1B && s[n-1] == '\\':
+		s = s[:n-2]
+	}
+	return s, true
+}
+
+// isPrivateUse reports whether r is a private use code point, in the BMP
+// private use area or the supplementary private use planes 15 and 16.
+func isPrivateUse(r rune) bool {
+	return (r >= 0xE000 && r <= 0xF8FF) || (r >= 0xF0000 && r <= 0xFFFFD) || (r >= 0x100000 && r <= 0x10FFFD)
+}
+
+// zwj is the UTF-8 encoding of ZERO WIDTH JOINER (U+200D)
+const zwj = "\u200D"
+
+// emojiFallbackWidth returns the sum of the widths of the components of an
+// emoji ZWJ sequence, and whether s is one. See [Options.EmojiFallback].
+func emojiFallbackWidth[T ~string | ~[]byte](s T, options Options) (int, bool) {
+	if !unicode.Is(extendedPictographic, decodeRune(s)) {
+		return 0, false
+	}
+
+	options.EmojiFallback = false
+	width, start := 0, 0
+	for i := 0; i+len(zwj) <= len(s); i++ {
+		if string(s[i:i+len(zwj)]) != zwj {
+			continue
+		}
+		width += graphemeWidth(s[start:i], options)
+		start = i + len(zwj)
+		i = start - 1
+	}
+	if start == 0 {
+		return 0, false
+	}
+	return width + graphemeWidth(s[start:], options), true
+}
+
+// visibleJoinersWidth returns the width of the parts of s between ZWJ
+// (U+200D) and ZWNJ (U+200C), plus 1 for each joiner, and whether s contains
+// a joiner. See [Options.VisibleJoiners].
+func visibleJoinersWidth[T ~string | ~[]byte](s T, options Options) (int, bool) {
+	options.VisibleJoiners = false
+	width, start := 0, 0
+	for i := 0; i+2 < len(s); i++ {
+		// U+200C and U+200D are E2 80 8C and E2 80 8D
+		if s[i] != 0xE2 || s[i+1] != 0x80 || (s[i+2] != 0x8C && s[i+2] != 0x8D) {
+			continue
+		}
+		width += graphemeWidth(s[start:i], options) + 1
+		start = i + 3
+		i = start - 1
+	}
+	if start == 0 {
+		return 0, false
+	}
+	return width + graphemeWidth(s[start:], options), true
+}
+
+// isHalfwidth reports whether r is East Asian Halfwidth (H), per
+// EastAsianWidth.txt: halfwidth katakana, Hangul and symbols, and the won
+// sign. See [Options.HalfwidthAsWide].
+func isHalfwidth(r rune) bool {
+	switch {
+	case r == 0x20A9:
+		return true
+	case r < 0xFF61 || r > 0xFFEE:
+		return false
+	}
+	return r <= 0xFFBE ||
+		(r >= 0xFFC2 && r <= 0xFFC7) ||
+		(r >= 0xFFCA && r <= 0xFFCF) ||
+		(r >= 0xFFD2 && r <= 0xFFD7) ||
+		(r >= 0xFFDA && r <= 0xFFDC) ||
+		(r >= 0xFFE8 && r <= 0xFFEE)
+}
+
+// isNeutral reports whether the leading rune of a grapheme cluster, which
+// is neither wide, ambiguous nor zero width, is East Asian Neutral (N), i.e.
+// valid non-ASCII, and neither East Asian Narrow (Na) nor Halfwidth (H). See
+// [Options.NeutralAsWide].
+func isNeutral[T ~string | ~[]byte](s T) bool {
+	if len(s) < 2 || s[0] < 0xC2 {
+		// ASCII and invalid UTF-8
+		return false
+	}
+	r := decodeRune(s)
+	switch {
+	case r == utf8.RuneError:
+		return false
+	case r == 0xA2, r == 0xA3, r == 0xA5, r == 0xA6, r == 0xAC, r == 0xAF:
+		// Narrow: cent, pound, yen, broken bar, not and macron signs
+		return false
+	case r >= 0x27E6 && r <= 0x27ED, r == 0x2985, r == 0x2986:
+		// Narrow: mathematical brackets
+		return false
+	}
+	return !isHalfwidth(r)
+}
+
+// inEmojiSet reports whether the grapheme cluster is allowed to be a wide
+// emoji by set. Clusters whose base code point is not an emoji are always
+// allowed. See [Options.EmojiSet].
+func inEmojiSet[T ~string | ~[]byte](s T, set *unicode.RangeTable) bool {
+	r := decodeRune(s)
+	if !isEmojiBase(r) {
+		return true
+	}
+	return unicode.Is(set, r)
+}
+
+// isEmojiBase reports whether r can be the base code point of an emoji
+// grapheme cluster: an Extended_Pictographic character or a regional
+// indicator.
+func isEmojiBase(r rune) bool {
+	return unicode.Is(extendedPictographic, r) || (r >= 0x1F1E6 && r <= 0x1F1FF)
+}
+
+// decodeLastRune returns the last rune of s.
+func decodeLastRune[T ~string | ~[]byte](s T) rune {
+	switch v := any(s).(type) {
+	case string:
+		r, _ := utf8.DecodeLastRuneInString(v)
+		return r
+	case []byte:
+		r, _ := utf8.DecodeLastRune(v)
+		return r
+	}
+	// Handles named types
+	r, _ := utf8.DecodeLastRuneInString(string(s))
+	return r
+}
+
+// decodeRune returns the first rune of s.
+func decodeRune[T ~string | ~[]byte](s T) rune {
+	switch v := any(s).(type) {
+	case string:
+		r, _ := utf8.DecodeRuneInString(v)
+		return r
+	case []byte:
+		r, _ := utf8.DecodeRune(v)
+		return r
+	}
+	// Handles named types
+	r, _ := utf8.DecodeRuneInString(string(s))
+	return r
+}
+
+// isUnassignedPlane checks if the slice begins with the UTF-8 encoding of a
+// code point in planes 4 through 13 (U+40000-U+DFFFF, F1 80 80 80 through
+// F3 9F BF BF), which have no assigned characters.
+func isUnassignedPlane[T ~string | ~[]byte](s T) bool {
+	if len(s) < 4 {
+		return false
+	}
+	return s[0] == 0xF1 || s[0] == 0xF2 || (s[0] == 0xF3 && s[1] <= 0x9F)
 }
 
 // hasEligibleVS16Pair returns true if the byte range starting at start
@@ -250,7 +1257,7 @@ func hasEligibleVS16Pair[T ~string | ~[]byte](s T, start int) bool {
 		if i+2 >= len(s) {
 			return false
 		}
-		if !isVS16(s[i:]) || i == 0 {
+		if !isVS16(s, i) || i == 0 {
 			start = i + 1
 			continue
 		}
@@ -2,13 +2,59 @@ package displaywidth
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/clipperhouse/displaywidth/testdata"
 )
 
 var defaultOptions = Options{}
 
 var eawOptions = Options{EastAsianWidth: true}
 
+// widthTest is a test case for the display width of input, with the given
+// options.
+type widthTest struct {
+	name     string
+	input    string
+	options  Options
+	expected int
+}
+
+// testWidths checks String and Bytes against each test case. Unless the
+// options depend on context, it also checks that the widths of the grapheme
+// clusters sum to the expected width.
+func testWidths(t *testing.T, tests []widthTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.String(tt.input); got != tt.expected {
+				t.Errorf("String(%+q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := tt.options.Bytes([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Bytes(%+q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if tt.options.contextual() || tt.options.StopAtNUL {
+				return
+			}
+
+			sum := 0
+			g := tt.options.StringGraphemes(tt.input)
+			for g.Next() {
+				sum += g.Width()
+			}
+			if sum != tt.expected {
+				t.Errorf("sum of grapheme widths for %+q = %d, want %d", tt.input, sum, tt.expected)
+			}
+		})
+	}
+}
+
 func TestStringWidth(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -105,6 +151,227 @@ func TestStringWidth(t *testing.T) {
 	}
 }
 
+// TestLoneRegionalIndicators pins the width of regional indicators that are
+// not part of a pair. The segmenter pairs them greedily from the left, so an
+// odd run ends with a lone indicator, which is an emoji of width 2.
+func TestLoneRegionalIndicators(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		clusters []string
+		expected int
+	}{
+		{"lone", "\U0001F1FA", []string{"\U0001F1FA"}, 2},
+		{"pair", "\U0001F1FA\U0001F1F8", []string{"\U0001F1FA\U0001F1F8"}, 2},
+		{"three", "\U0001F1FA\U0001F1F8\U0001F1EC", []string{"\U0001F1FA\U0001F1F8", "\U0001F1EC"}, 2 + 2},
+		{"four", "\U0001F1FA\U0001F1F8\U0001F1EC\U0001F1E7", []string{"\U0001F1FA\U0001F1F8", "\U0001F1EC\U0001F1E7"}, 2 + 2},
+		{"five", "\U0001F1FA\U0001F1F8\U0001F1EC\U0001F1E7\U0001F1EF", []string{"\U0001F1FA\U0001F1F8", "\U0001F1EC\U0001F1E7", "\U0001F1EF"}, 2 + 2 + 2},
+		{"lone between text", "a\U0001F1FAb", []string{"a", "\U0001F1FA", "b"}, 1 + 2 + 1},
+		{"lone with combining mark", "\U0001F1FA\u0301", []string{"\U0001F1FA\u0301"}, 2},
+		{"lone then pair after text", "\U0001F1FAx\U0001F1FA\U0001F1F8", []string{"\U0001F1FA", "x", "\U0001F1FA\U0001F1F8"}, 2 + 1 + 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clusters []string
+			g := StringGraphemes(tt.input)
+			for g.Next() {
+				clusters = append(clusters, g.Value())
+			}
+			if strings.Join(clusters, "|") != strings.Join(tt.clusters, "|") {
+				t.Errorf("clusters of %+q = %+q, want %+q", tt.input, clusters, tt.clusters)
+			}
+
+			if got := String(tt.input); got != tt.expected {
+				t.Errorf("String(%+q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := Bytes([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Bytes(%+q) = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	if got := Rune(0x1F1FA); got != 2 {
+		t.Errorf("Rune(U+1F1FA) = %d, want 2", got)
+	}
+	if got := eawOptions.String("\U0001F1FA"); got != 2 {
+		t.Errorf("String(U+1F1FA) with EastAsianWidth = %d, want 2", got)
+	}
+}
+
+// TestEmojiModifiers pins the width of emoji modifiers (skin tones) in valid
+// and malformed sequences. The segmenter attaches a modifier to the
+// preceding cluster whatever it is; a modifier that does not follow an emoji
+// is measured as a standalone emoji.
+func TestEmojiModifiers(t *testing.T) {
+	tests := []widthTest{
+		// Valid modifier sequences
+		{"modifier sequence", "\U0001F44B\U0001F3FD", defaultOptions, 2},
+		{"text-default base", "\u261D\U0001F3FD", defaultOptions, 2},
+		{"text-default base victory", "\u270C\U0001F3FD", defaultOptions, 2},
+		{"text-default base alone", "\u261D", defaultOptions, 1},
+		{"in ZWJ sequence", "\U0001F469\U0001F3FD\u200D\U0001F4BB", defaultOptions, 2},
+
+		// Emoji that are not modifier bases keep the modifier attached
+		{"emoji not a base", "\U0001F600\U0001F3FD", defaultOptions, 2},
+		{"text-default emoji not a base", "\u2764\U0001F3FD", defaultOptions, 1},
+
+		// Malformed: modifiers that do not follow an emoji. The cluster is
+		// as wide as an emoji, or as the rest of it if that is wider.
+		{"lone modifier", "\U0001F3FD", defaultOptions, 2},
+		{"two modifiers", "\U0001F3FD\U0001F3FD", defaultOptions, 2},
+		{"letter and modifier", "a\U0001F3FD", defaultOptions, 2},
+		{"CJK and modifier", "\u4E2D\U0001F3FD", defaultOptions, 2},
+		{"Mahjong tile and modifier", "\U0001F000\U0001F3FD", defaultOptions, 2},
+		{"combining mark and modifier", "e\u0301\U0001F3FD", defaultOptions, 2},
+		{"modifier after modifier sequence", "\U0001F44B\U0001F3FD\U0001F3FD", defaultOptions, 2},
+		{"modifier after ZWJ", "\U0001F44B\u200D\U0001F3FD", defaultOptions, 2},
+		{"ZWJ and modifier", "\u200D\U0001F3FD", defaultOptions, 2},
+		{"modifier then emoji", "\U0001F3FD\U0001F44B", defaultOptions, 2 + 2},
+		{"in text", "hi\U0001F3FD!", defaultOptions, 1 + 2 + 1},
+		{"EmojiWidth", "a\U0001F3FD", Options{EmojiWidth: 1}, 1},
+		{"EmojiWidth CJK", "\u4E2D\U0001F3FD", Options{EmojiWidth: 1}, 2},
+	}
+
+	testWidths(t, tests)
+
+	for _, tt := range tests {
+		sum := 0
+		for _, e := range tt.options.ExplainString(tt.input) {
+			sum += e.Width
+		}
+		if sum != tt.expected {
+			t.Errorf("sum of ExplainString widths for %+q = %d, want %d", tt.input, sum, tt.expected)
+		}
+	}
+
+	for _, s := range []string{"a\U0001F3FD", "\u261D\U0001F3FD"} {
+		if e := ExplainString(s); len(e) != 1 || e[0].Reason != ReasonEmoji {
+			t.Errorf("ExplainString(%+q) = %+v, want a single ReasonEmoji", s, e)
+		}
+		if _, hasEmoji := WidthAndEmoji(s); !hasEmoji {
+			t.Errorf("WidthAndEmoji(%+q) has no emoji", s)
+		}
+	}
+}
+
+// TestTableWidth verifies that tableWidth, which graphemeWidth uses when no
+// per-cluster option is set, agrees with graphemeWidthReason.
+func TestTableWidth(t *testing.T) {
+	options := []Options{defaultOptions, eawOptions, controlSequences, controlSequences8Bit, {EastAsianWidth: true, ControlSequences: true, ControlSequences8Bit: true}}
+
+	check := func(o Options, s string) {
+		t.Helper()
+		got := tableWidth(s, o.EastAsianWidth, o.ControlSequences8Bit)
+		if expected, _ := graphemeWidthReason(s, o); got != expected {
+			t.Errorf("tableWidth(%+q) with %+v = %d, graphemeWidthReason = %d", s, o, got, expected)
+		}
+	}
+
+	for _, load := range []func() ([]byte, error){testdata.Sample, testdata.InvalidUTF8, testdata.TestCases} {
+		corpus, err := load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range options {
+			g := o.StringGraphemes(string(corpus))
+			for g.Next() {
+				check(o, g.Value())
+			}
+		}
+	}
+
+	if testing.Short() {
+		return
+	}
+	// Each rune of planes 0 to 3 and 14, which have all assigned code points
+	// other than private use, alone and followed by a variation selector, an
+	// emoji modifier, a combining mark or a ZWJ and a modifier
+	suffixes := map[Options][]string{
+		defaultOptions: {"", "\uFE0E", "\uFE0F", "\U0001F3FD", "\u0301\U0001F3FD", "\u200D\U0001F3FD"},
+		eawOptions:     {"", "\uFE0F"},
+	}
+	for o, suffixes := range suffixes {
+		for r := rune(0); r <= 0xE0FFF; r++ {
+			if r == 0x40000 {
+				r = 0xE0000
+			}
+			if !utf8.ValidRune(r) {
+				continue
+			}
+			for _, suffix := range suffixes {
+				check(o, string(r)+suffix)
+			}
+		}
+	}
+}
+
+// TestClusterOptions verifies that clusterOptions reports every option that
+// graphemeWidthReason checks, so that graphemeWidth does not skip it.
+func TestClusterOptions(t *testing.T) {
+	// Options that tableWidth handles, or that do not change the width of a
+	// cluster on its own
+	other := map[string]bool{
+		"EastAsianWidth": true, "ControlSequences": true, "ControlSequences8Bit": true,
+		"AmbiguousScaledWidth": true, "WideScaledWidth": true, "EmojiScaledWidth": true, "TabWidth": true, "VerticalControls": true,
+		"DECGraphics": true, "ContextualAmbiguous": true, "AmbiguousInCJKContext": true,
+		"TruncateKeepFirst": true, "EnsureReset": true, "StopAtNUL": true,
+	}
+
+	if defaultOptions.clusterOptions() {
+		t.Errorf("clusterOptions() = true for the zero Options")
+	}
+
+	typ := reflect.TypeOf(Options{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		var o Options
+		v := reflect.ValueOf(&o).Elem().Field(i)
+		switch v.Kind() {
+		case reflect.Bool:
+			v.SetBool(true)
+		case reflect.Int:
+			v.SetInt(1)
+		case reflect.Ptr:
+			v.Set(reflect.New(field.Type.Elem()))
+		default:
+			t.Fatalf("unexpected kind %v of Options.%s", v.Kind(), field.Name)
+		}
+		if got := o.clusterOptions(); got == other[field.Name] {
+			t.Errorf("clusterOptions() = %v with Options.%s set", got, field.Name)
+		}
+	}
+}
+
+// TestStringAllocs verifies that measuring does not allocate: the grapheme
+// iterator does not escape, and stays on the stack.
+func TestStringAllocs(t *testing.T) {
+	inputs := []string{
+		mediumCJK,
+		"Hello, 世界! 👋🏽 café 🇺🇸 ★",
+		"\x1b[31mred\x1b[0m and plain",
+	}
+	options := []Options{
+		defaultOptions,
+		eawOptions,
+		controlSequencesBoth,
+		{TabWidth: 8},
+		{ContextualAmbiguous: true},
+	}
+
+	for _, o := range options {
+		for _, input := range inputs {
+			bs := []byte(input)
+			if n := testing.AllocsPerRun(100, func() { _ = o.String(input) }); n != 0 {
+				t.Errorf("%+v String(%q) allocates %v times, want 0", o, input, n)
+			}
+			if n := testing.AllocsPerRun(100, func() { _ = o.Bytes(bs) }); n != 0 {
+				t.Errorf("%+v Bytes(%q) allocates %v times, want 0", o, input, n)
+			}
+		}
+	}
+}
+
 var controlSequences = Options{ControlSequences: true}
 var controlSequences8Bit = Options{ControlSequences8Bit: true}
 var controlSequencesBoth = Options{ControlSequences: true, ControlSequences8Bit: true}
@@ -231,6 +498,133 @@ func TestAnsiEscapeSequences8Bit(t *testing.T) {
 	}
 }
 
+// TestCSIParameterBytes verifies that the parameter bytes (0x30–0x3F) and
+// intermediate bytes (0x20–0x2F) of a CSI, which look printable, are zero
+// width along with the rest of the sequence, and that a malformed CSI is
+// measured predictably as text.
+func TestCSIParameterBytes(t *testing.T) {
+	tests := []widthTest{
+		{"truecolor SGR", "\x1b[38;2;255;0;0m", controlSequences, 0},
+		{"truecolor SGR with text", "\x1b[38;2;255;0;0mred\x1b[0m", controlSequences, 3},
+		{"256-color background", "\x1b[48;5;208mX", controlSequences, 1},
+		{"colon subparameters", "\x1b[38:2::255:0:0mX", controlSequences, 1},
+		{"private marker", "\x1b[?25h", controlSequences, 0},
+		{"SGR mouse", "\x1b[<0;12;34M", controlSequences, 0},
+		{"intermediate byte", "\x1b[2 qX", controlSequences, 1},
+		{"intermediate only", "\x1b[!p", controlSequences, 0},
+		{"8-bit truecolor", "\x9b38;2;255;0;0mX", controlSequences8Bit, 1},
+		{"8-bit intermediate", "\x9b2 qX", controlSequences8Bit, 1},
+		{"tab after SGR", "\x1b[38;2;1;2;3m\tX", Options{ControlSequences: true, TabWidth: 8}, 9},
+
+		// Without ControlSequences, the parameters are printable text
+		{"default options", "\x1b[38;2;255;0;0m", defaultOptions, 14},
+
+		// A malformed CSI is not a sequence: ESC is a zero-width control,
+		// and the bytes that follow are measured as text
+		{"DEL final byte", "\x1b[1;2\x7fX", controlSequences, 5},
+		{"C0 in parameters", "\x1b[12\x01X", controlSequences, 4},
+		{"non-ASCII final", "\x1b[1;2éX", controlSequences, 6},
+		{"ESC in parameters", "\x1b[1\x1b[0mX", controlSequences, 3},
+		{"unterminated", "\x1b[38;2;255", controlSequences, 9},
+		{"8-bit DEL final byte", "\x9b1;2\x7fX", controlSequences8Bit, 4},
+	}
+
+	testWidths(t, tests)
+
+	// Every parameter, intermediate, and final byte
+	for p := byte(0x30); p <= 0x3F; p++ {
+		for i := byte(0x20); i <= 0x2F; i++ {
+			for f := byte(0x40); f <= 0x7E; f++ {
+				input := string([]byte{0x1B, '[', p, p, i, f})
+				if got := controlSequences.String(input); got != 0 {
+					t.Fatalf("String(%q) = %d, want 0", input, got)
+				}
+			}
+		}
+	}
+}
+
+// TestCursorAndModeSequences verifies that the escape sequences that
+// commonly wrap cursor-hiding output are zero width: the two-byte ESC Fp and
+// Fs forms, such as DECSC (ESC 7), DECRC (ESC 8), DECKPAM (ESC =) and
+// DECKPNM (ESC >), and private mode sets, such as ESC [ ? 25 l.
+func TestCursorAndModeSequences(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"DECSC", "\x1b7", 0},
+		{"DECRC", "\x1b8", 0},
+		{"DECKPAM", "\x1b=", 0},
+		{"DECKPNM", "\x1b>", 0},
+		{"RIS", "\x1bc", 0},
+		{"reverse index", "\x1bM", 0},
+		{"SCOSC", "\x1b[s", 0},
+		{"SCORC", "\x1b[u", 0},
+		{"hide cursor", "\x1b[?25l", 0},
+		{"show cursor", "\x1b[?25h", 0},
+		{"alternate screen", "\x1b[?1049h", 0},
+		{"bracketed paste", "\x1b[?2004h", 0},
+		{"multiple private modes", "\x1b[?1000;1006h", 0},
+		{"save and restore around text", "\x1b7hello\x1b8", 5},
+		{"hidden cursor around text", "\x1b7\x1b[?25l中文\x1b[?25h\x1b8", 4},
+		{"keypad mode around text", "\x1b=ok\x1b>", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := controlSequences.String(tt.input); got != tt.expected {
+				t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := controlSequences.Bytes([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Bytes(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			// Without ControlSequences, the bytes after ESC are printable
+			if got := defaultOptions.String(tt.input); got <= tt.expected {
+				t.Errorf("String(%q) without ControlSequences = %d, want > %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	// Each sequence is a single cluster, which truncation preserves
+	const input = "\x1b7\x1b[?25lhello world\x1b[?25h\x1b8"
+	if got, want := controlSequences.TruncateString(input, 6, "…"), "\x1b7\x1b[?25lhello…\x1b[?25h\x1b8"; got != want {
+		t.Errorf("TruncateString(%q) = %q, want %q", input, got, want)
+	}
+}
+
+// TestControlStrings8Bit verifies that control strings opened by an 8-bit C1
+// introducer (DCS, SOS, OSC, PM, APC) are consumed through the 8-bit ST
+// (0x9C), so that their printable payload is not counted.
+func TestControlStrings8Bit(t *testing.T) {
+	tests := []widthTest{
+		{"OSC title", "\x9D0;a long window title\x9C", controlSequences8Bit, 0},
+		{"OSC title with CJK payload", "\x9D0;中文タイトル\x9C", controlSequences8Bit, 0},
+		{"OSC title with emoji payload", "\x9D0;😀 build\x9C", controlSequences8Bit, 0},
+		{"OSC 8 hyperlink", "\x9D8;;https://example.com\x9Clink\x9D8;;\x9C", controlSequences8Bit, 4},
+		{"OSC 8 hyperlink, CJK text", "\x9D8;;https://example.com\x9C中文\x9D8;;\x9C", controlSequences8Bit, 4},
+		{"OSC between text", "ab\x9D0;title\x9Ccd", controlSequences8Bit, 4},
+		{"empty OSC", "\x9D\x9C", controlSequences8Bit, 0},
+		{"DCS payload", "\x90$qm payload\x9Cx", controlSequences8Bit, 1},
+		{"PM payload", "\x9Eprivacy message\x9C", controlSequences8Bit, 0},
+		{"APC payload", "\x9FG a=T;AAAA\x9C", controlSequences8Bit, 0},
+		{"SOS payload", "\x98start of string\x9C", controlSequences8Bit, 0},
+		{"consecutive strings", "\x9D0;a\x9C\x9D2;b\x9C\x90c\x9C", controlSequences8Bit, 0},
+		{"both options", "\x1b[1m\x9D0;title\x9Chi\x1b[0m", controlSequencesBoth, 2},
+
+		// 0x9C is also a UTF-8 continuation byte, so a payload character
+		// whose encoding contains it, such as U+011C (C4 9C), ends the
+		// string early.
+		{"payload containing 0x9C byte", "\x9D0;\u011Cx\x9C", controlSequences8Bit, 1},
+
+		// Without ControlSequences8Bit, the payload is counted
+		{"OSC default options", "\x9D0;title\x9C", defaultOptions, 9},
+	}
+
+	testWidths(t, tests)
+}
+
 // TestAnsiEscapeSequencesIndependence verifies that the 7-bit and 8-bit options
 // are strictly independent: enabling one must NOT cause the other's sequences
 // to be treated as escape sequences.
@@ -680,6 +1074,43 @@ func TestEmojiPresentationRune(t *testing.T) {
 	}
 }
 
+// TestVS16NonEmojiBase verifies that VS16 (U+FE0F) only promotes bases that
+// have a valid emoji presentation sequence per emoji-variation-sequences.txt.
+// A VS16 after any other base does not create a width-2 emoji.
+func TestVS16NonEmojiBase(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		// Not eligible: VS16 is ignored
+		{"Latin a + VS16", "a\uFE0F", 1},
+		{"Latin A + VS16", "A\uFE0F", 1},
+		{"precomposed é + VS16", "\u00E9\uFE0F", 1},
+		{"decomposed é + VS16", "e\u0301\uFE0F", 1},
+		{"rightwards arrow + VS16", "\u2192\uFE0F", 1}, // → has no emoji variation sequence
+		{"CJK + VS16", "中\uFE0F", 2},                   // already wide, unchanged
+		{"VS16 alone", "\uFE0F", 0},
+
+		// Eligible: VS16 requests emoji presentation
+		{"scissors + VS16", "\u2702\uFE0F", 2},
+		{"left right arrow + VS16", "\u2194\uFE0F", 2},
+		{"digit + VS16", "1\uFE0F", 2},
+		{"number sign + VS16", "#\uFE0F", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := String(tt.input); got != tt.expected {
+				t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := Bytes([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Bytes(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
 // TestComplexEmojiSequences tests width of complex emoji sequences
 func TestComplexEmojiSequences(t *testing.T) {
 	tests := []struct {
@@ -700,6 +1131,39 @@ func TestComplexEmojiSequences(t *testing.T) {
 			want:  2,
 			desc:  "0 + VS16 + combining enclosing keycap",
 		},
+		// Keycaps without VS16 are not emoji keycap sequences (TR51 requires
+		// VS16). They are a digit with an enclosing mark, in text presentation,
+		// and keep the width of the digit.
+		{
+			name:  "Keycap without VS16 1⃣",
+			input: "1\u20E3",
+			want:  1,
+			desc:  "1 + combining enclosing keycap, no VS16",
+		},
+		{
+			name:  "Keycap without VS16 #⃣",
+			input: "#\u20E3",
+			want:  1,
+			desc:  "# + combining enclosing keycap, no VS16",
+		},
+		{
+			name:  "Keycap without VS16 *⃣",
+			input: "*\u20E3",
+			want:  1,
+			desc:  "* + combining enclosing keycap, no VS16",
+		},
+		{
+			name:  "Keycap with VS15 1︎⃣",
+			input: "1\uFE0E\u20E3",
+			want:  1,
+			desc:  "1 + VS15 + combining enclosing keycap, text presentation",
+		},
+		{
+			name:  "Keycaps without VS16 in text",
+			input: "1\u20E32\u20E3",
+			want:  2,
+			desc:  "two keycaps without VS16, one cell each",
+		},
 		{
 			name:  "Flag sequence 🇺🇸 (Regional Indicator pair)",
 			input: "\U0001F1FA\U0001F1F8",
@@ -737,6 +1201,128 @@ func TestComplexEmojiSequences(t *testing.T) {
 	}
 }
 
+// TestEmojiTagSequences tests subdivision flags, which are emoji tag
+// sequences: U+1F3F4 WAVING BLACK FLAG, followed by tag characters
+// (U+E0020..U+E007E) spelling the subdivision, terminated by U+E007F CANCEL
+// TAG. The tags are zero width, and the whole sequence is a single grapheme
+// cluster of width 2.
+func TestEmojiTagSequences(t *testing.T) {
+	const (
+		scotland = "\U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F" // gbsct
+		wales    = "\U0001F3F4\U000E0067\U000E0062\U000E0077\U000E006C\U000E0073\U000E007F" // gbwls
+		england  = "\U0001F3F4\U000E0067\U000E0062\U000E0065\U000E006E\U000E0067\U000E007F" // gbeng
+	)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"Scotland", scotland, 2},
+		{"Wales", wales, 2},
+		{"England", england, 2},
+		{"black flag alone", "\U0001F3F4", 2},
+		{"all three", scotland + wales + england, 6},
+		{"in text", "go " + scotland + "!", 3 + 2 + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, opt := range []Options{defaultOptions, eawOptions} {
+				if got := opt.String(tt.input); got != tt.expected {
+					t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.expected)
+				}
+				if got := opt.Bytes([]byte(tt.input)); got != tt.expected {
+					t.Errorf("Bytes(%q) = %d, want %d", tt.input, got, tt.expected)
+				}
+			}
+		})
+	}
+
+	for _, flag := range []string{scotland, wales, england} {
+		n := 0
+		g := StringGraphemes(flag)
+		for g.Next() {
+			n++
+			if g.Width() != 2 {
+				t.Errorf("StringGraphemes(%q) width = %d, want 2", g.Value(), g.Width())
+			}
+		}
+		if n != 1 {
+			t.Errorf("StringGraphemes(%q) yielded %d clusters, want 1", flag, n)
+		}
+	}
+
+	// The tag characters themselves are zero width
+	for r := rune(0xE0020); r <= 0xE007F; r++ {
+		if got := Rune(r); got != 0 {
+			t.Errorf("Rune(%U) = %d, want 0", r, got)
+		}
+	}
+}
+
+// TestIncompleteTagSequences pins the width of malformed emoji tag
+// sequences. The segmenter attaches tag characters to the preceding
+// cluster as extenders, whether or not the sequence is terminated by U+E007F
+// CANCEL TAG, so a partial subdivision flag is a single cluster measured by
+// its base: width 2 for the black flag, like a complete flag. Orphaned tags,
+// with no base, are zero width.
+func TestIncompleteTagSequences(t *testing.T) {
+	const (
+		flag   = "\U0001F3F4"
+		tags   = "\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074" // gbsct
+		cancel = "\U000E007F"
+	)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+		clusters int
+	}{
+		{"complete", flag + tags + cancel, 2, 1},
+		{"missing cancel tag", flag + tags, 2, 1},
+		{"single tag", flag + "\U000E0067", 2, 1},
+		{"cancel tag only", flag + cancel, 2, 1},
+		{"missing cancel tag then text", flag + tags + "x", 2 + 1, 2},
+		{"two partial flags", flag + tags + flag + tags, 2 + 2, 2},
+		{"with combining mark", flag + tags + "\u0301" + cancel, 2, 1},
+		{"other emoji base", "😀" + tags + cancel, 2, 1},
+
+		// Tags after a non-emoji base do not change its width
+		{"after ASCII", "a" + tags + cancel, 1, 1},
+		{"after CJK", "中" + tags, 2, 1},
+
+		// Orphaned tags are zero width
+		{"orphaned tags", tags, 0, 1},
+		{"orphaned tags with cancel", tags + cancel, 0, 1},
+		{"orphaned cancel tag", cancel, 0, 1},
+		{"orphaned tags then text", tags + "x", 1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, opt := range []Options{defaultOptions, eawOptions} {
+				if got := opt.String(tt.input); got != tt.expected {
+					t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.expected)
+				}
+				if got := opt.Bytes([]byte(tt.input)); got != tt.expected {
+					t.Errorf("Bytes(%q) = %d, want %d", tt.input, got, tt.expected)
+				}
+			}
+
+			n := 0
+			g := StringGraphemes(tt.input)
+			for g.Next() {
+				n++
+			}
+			if n != tt.clusters {
+				t.Errorf("StringGraphemes(%q) yielded %d clusters, want %d", tt.input, n, tt.clusters)
+			}
+		})
+	}
+}
+
 // TestMixedContent tests width of strings with mixed emoji and text
 func TestMixedContent(t *testing.T) {
 	tests := []struct {
@@ -1221,10 +1807,146 @@ func TestAsciiWidth(t *testing.T) {
 				t.Errorf("asciiWidth(0x%02X '%s') = %d, want %d (%s)",
 					tt.b, string(tt.b), got, tt.expected, tt.desc)
 			}
+			got = ByteWidth(tt.b)
+			if got != tt.expected {
+				t.Errorf("ByteWidth(0x%02X '%s') = %d, want %d (%s)",
+					tt.b, string(tt.b), got, tt.expected, tt.desc)
+			}
 		})
 	}
 }
 
+func TestClusterWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []rune
+		options  Options
+		expected int
+	}{
+		{"empty", nil, defaultOptions, 0},
+		{"ASCII", []rune{'a'}, defaultOptions, 1},
+		{"CJK", []rune{'中'}, defaultOptions, 2},
+		{"combining", []rune{'e', 0x0301}, defaultOptions, 1},
+		{"zero width only", []rune{0x0301, 0x0302}, defaultOptions, 0},
+		{"control", []rune{0x07}, defaultOptions, 0},
+		{"CRLF", []rune{'\r', '\n'}, defaultOptions, 0},
+
+		// VS16 sequences
+		{"VS16", []rune{0x2764, 0xFE0F}, defaultOptions, 2},
+		{"without VS16", []rune{0x2764}, defaultOptions, 1},
+		{"keycap", []rune{'1', 0xFE0F, 0x20E3}, defaultOptions, 2},
+		{"VS15", []rune{0x231A, 0xFE0E}, Options{HonorVS15: true}, 1},
+
+		// Emoji modifier sequences
+		{"skin tone", []rune{0x1F44B, 0x1F3FD}, defaultOptions, 2},
+		{"text-default base with skin tone", []rune{0x261D, 0x1F3FD}, defaultOptions, 2},
+		{"ZWJ with skin tones", []rune{0x1F469, 0x1F3FD, 0x200D, 0x1F4BB}, defaultOptions, 2},
+
+		// Regional indicators
+		{"flag", []rune{0x1F1FA, 0x1F1F8}, defaultOptions, 2},
+		{"lone regional indicator", []rune{0x1F1FA}, defaultOptions, 2},
+
+		// Options
+		{"ambiguous", []rune{'★'}, defaultOptions, 1},
+		{"ambiguous EAW", []rune{'★'}, eawOptions, 2},
+		{"EmojiWidth", []rune{0x1F600}, Options{EmojiWidth: 1}, 1},
+		{"escape sequence", []rune("\x1b[31m"), controlSequences, 0},
+
+		// Invalid runes are measured as U+FFFD
+		{"surrogate", []rune{0xD800}, defaultOptions, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.ClusterWidth(tt.input); got != tt.expected {
+				t.Errorf("ClusterWidth(%U) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got, want := tt.options.ClusterWidth(tt.input), tt.options.String(string(tt.input)); got != want {
+				t.Errorf("ClusterWidth(%U) = %d, String() = %d", tt.input, got, want)
+			}
+		})
+	}
+
+	// Equal to String for every grapheme cluster of the sample
+	for _, options := range []Options{defaultOptions, eawOptions} {
+		g := options.StringGraphemes("Hi 中文 👋🏽 🇯🇵 ❤\uFE0F 1\uFE0F\u20E3 e\u0301 👨\u200d👩\u200d👧")
+		for g.Next() {
+			if got, want := options.ClusterWidth([]rune(g.Value())), g.Width(); got != want {
+				t.Errorf("ClusterWidth(%q) = %d, want %d", g.Value(), got, want)
+			}
+		}
+	}
+
+	if got := ClusterWidth([]rune{0x1F44B, 0x1F3FD}); got != 2 {
+		t.Errorf("ClusterWidth() = %d, want 2", got)
+	}
+}
+
+func TestRuneWidthStrict(t *testing.T) {
+	invalid := []rune{0xD800, 0xDBFF, 0xDC00, 0xDFFF, unicode.MaxRune + 1, -1}
+	for _, r := range invalid {
+		w, err := RuneWidthStrict(r)
+		if !errors.Is(err, ErrInvalidRune) {
+			t.Errorf("RuneWidthStrict(%U) error = %v, want ErrInvalidRune", r, err)
+		}
+		if w != 0 {
+			t.Errorf("RuneWidthStrict(%U) = %d, want 0", r, w)
+		}
+	}
+
+	// The whole surrogate range is invalid
+	for r := rune(0xD800); r <= 0xDFFF; r++ {
+		if _, err := RuneWidthStrict(r); err == nil {
+			t.Fatalf("RuneWidthStrict(%U) error = nil, want ErrInvalidRune", r)
+		}
+	}
+
+	valid := []struct {
+		r        rune
+		expected int
+	}{
+		{'a', 1},
+		{0, 0},      // control, legitimately zero width
+		{0x0301, 0}, // combining mark
+		{0x200B, 0}, // zero width space
+		{'中', 2},
+		{'😀', 2},
+		{0xD7FF, 1},
+		{0xE000, 1},
+		{unicode.MaxRune, 1},
+	}
+	for _, tt := range valid {
+		w, err := RuneWidthStrict(tt.r)
+		if err != nil {
+			t.Errorf("RuneWidthStrict(%U) error = %v, want nil", tt.r, err)
+		}
+		if w != tt.expected || w != Rune(tt.r) {
+			t.Errorf("RuneWidthStrict(%U) = %d, want %d", tt.r, w, tt.expected)
+		}
+	}
+}
+
+func TestByteWidth(t *testing.T) {
+	// ByteWidth agrees with String and Rune for single-byte UTF-8
+	for b := 0; b < 0x80; b++ {
+		s := string([]byte{byte(b)})
+		if got, want := ByteWidth(byte(b)), String(s); got != want {
+			t.Errorf("ByteWidth(0x%02X) = %d, String() = %d", b, got, want)
+		}
+		if got, want := ByteWidth(byte(b)), Rune(rune(b)); got != want {
+			t.Errorf("ByteWidth(0x%02X) = %d, Rune() = %d", b, got, want)
+		}
+	}
+
+	// Other bytes are width 1, as a lone invalid byte
+	for b := 0x80; b <= 0xFF; b++ {
+		s := string([]byte{byte(b)})
+		if got, want := ByteWidth(byte(b)), String(s); got != want || got != 1 {
+			t.Errorf("ByteWidth(0x%02X) = %d, String() = %d, want 1", b, got, want)
+		}
+	}
+}
+
 func TestTruncateString(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1593,6 +2315,257 @@ func TestNamedStringAndByteTypes(t *testing.T) {
 	}
 }
 
+func TestPrintableASCIILengthChunks(t *testing.T) {
+	// Place every byte value at every position of the first and second
+	// 8-byte chunk, and compare with a byte-by-byte scan.
+	printable := func(b byte) bool {
+		return b >= 0x20 && b <= 0x7E
+	}
+
+	for v := 0; v <= 0xFF; v++ {
+		for pos := 0; pos < 20; pos++ {
+			buf := []byte(strings.Repeat("x", 20))
+			buf[pos] = byte(v)
+
+			expected := 0
+			for expected < len(buf) && printable(buf[expected]) {
+				expected++
+			}
+			if expected > 0 && expected < len(buf) && buf[expected] >= 0x80 {
+				expected--
+			}
+
+			if got := printableASCIILength(string(buf)); got != expected {
+				t.Fatalf("printableASCIILength(%q) = %d, want %d", buf, got, expected)
+			}
+			if got := printableASCIILength(buf); got != expected {
+				t.Fatalf("printableASCIILength([]byte %q) = %d, want %d", buf, got, expected)
+			}
+		}
+	}
+}
+
+type cityName struct{ city, country string }
+
+func (c cityName) String() string {
+	return c.city + "、" + c.country
+}
+
+func TestStringerWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    fmt.Stringer
+		options  Options
+		expected int
+	}{
+		{"CJK", cityName{"東京", "日本"}, defaultOptions, 10},
+		{"mixed", cityName{"Tokyo", "日本"}, defaultOptions, 11},
+		{"empty", cityName{}, defaultOptions, 2},
+		{"ambiguous EAW", cityName{"★", "★"}, eawOptions, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.StringerWidth(tt.input); got != tt.expected {
+				t.Errorf("StringerWidth(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	if got := StringerWidth(cityName{"東京", "日本"}); got != 10 {
+		t.Errorf("StringerWidth() = %d, want 10", got)
+	}
+}
+
+func TestUpperWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  Options
+		expected int
+	}{
+		{"empty", "", defaultOptions, 0},
+		{"ASCII", "hello", defaultOptions, 5},
+		{"fullwidth", "ａｂｃ", defaultOptions, 6},
+		{"halfwidth katakana", "ｱｲｳ", defaultOptions, 3},
+		{"CJK", "中文", defaultOptions, 4},
+
+		// ß has no single-rune upper case, so it is unchanged
+		{"sharp s", "straße", defaultOptions, 6},
+		{"ligature", "\uFB01", defaultOptions, 1},
+
+		// The cases differ in East Asian Width
+		{"ambiguous lower", "é", eawOptions, 1},
+		{"ambiguous word", "café", eawOptions, 4},
+		{"dotless i", "\u0131", eawOptions, 1},
+		{"micro sign", "\u00B5", eawOptions, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.UpperWidth(tt.input); got != tt.expected {
+				t.Errorf("UpperWidth(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got, want := tt.options.UpperWidth(tt.input), tt.options.String(strings.ToUpper(tt.input)); got != want {
+				t.Errorf("UpperWidth(%q) = %d, String(ToUpper) = %d", tt.input, got, want)
+			}
+		})
+	}
+
+	// Lower case that is ambiguous becomes neutral
+	if got, orig := eawOptions.UpperWidth("é"), eawOptions.String("é"); got != 1 || orig != 2 {
+		t.Errorf("UpperWidth(%q) = %d and String = %d, want 1 and 2", "é", got, orig)
+	}
+	if got := UpperWidth("ｘ"); got != 2 {
+		t.Errorf("UpperWidth() = %d, want 2", got)
+	}
+}
+
+func TestEndColumn(t *testing.T) {
+	tab4 := Options{TabWidth: 4}
+	tab8 := Options{TabWidth: 8}
+
+	tests := []struct {
+		name     string
+		options  Options
+		startCol int
+		input    string
+		expected int
+	}{
+		{"empty", defaultOptions, 3, "", 3},
+		{"ASCII", defaultOptions, 0, "hello", 5},
+		{"ASCII from column", defaultOptions, 10, "hello", 15},
+		{"CJK from column", defaultOptions, 3, "中文", 7},
+
+		// A tab snaps to the next stop after startCol
+		{"tab from 0", tab4, 0, "\tx", 5},
+		{"tab from 1", tab4, 1, "\tx", 5},
+		{"tab from 3", tab4, 3, "\tx", 5},
+		{"tab from stop", tab4, 4, "\tx", 9},
+		{"tab from 5", tab4, 5, "\tx", 9},
+		{"text then tab from 2", tab4, 2, "ab\t", 8},
+		{"text then tab from 1", tab4, 1, "ab\t", 4},
+		{"tab 8 from 6", tab8, 6, "\t", 8},
+		{"CJK then tab", tab8, 1, "中文\t|", 9},
+		{"tab width 0", defaultOptions, 3, "\tx", 4},
+
+		// Vertical tabs and form feeds reset the column with VerticalControls
+		{"form feed", defaultOptions, 3, "ab\fc", 6},
+		{"form feed, VerticalControls", Options{VerticalControls: true}, 3, "ab\fc", 1},
+		{"vertical tab, VerticalControls", Options{VerticalControls: true}, 3, "ab\vcd", 2},
+
+		// Newlines reset the column
+		{"newline", tab4, 10, "abc\nde", 2},
+		{"newline then tab", tab4, 10, "abc\n\tx", 5},
+		{"CRLF", defaultOptions, 10, "abc\r\nd", 1},
+		{"CR", defaultOptions, 10, "abc\rd", 1},
+		{"trailing newline", defaultOptions, 10, "abc\n", 0},
+
+		{"escape sequence", controlSequences, 2, "\x1b[31mab\x1b[0m", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.EndColumn(tt.startCol, tt.input); got != tt.expected {
+				t.Errorf("EndColumn(%d, %q) = %d, want %d", tt.startCol, tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	// Concatenating segments gives the same column as the whole
+	segments := []string{"ab\t", "中\t", "x\ty", "\t\tz"}
+	col, whole := 0, ""
+	for _, seg := range segments {
+		col = tab4.EndColumn(col, seg)
+		whole += seg
+	}
+	if want := tab4.EndColumn(0, whole); col != want {
+		t.Errorf("EndColumn() over segments = %d, want %d", col, want)
+	}
+	if want := tab4.String(whole); col != want {
+		t.Errorf("EndColumn() over segments = %d, String() = %d", col, want)
+	}
+
+	if got := EndColumn(2, "中"); got != 4 {
+		t.Errorf("EndColumn() = %d, want 4", got)
+	}
+}
+
+func TestWidthAndEmoji(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  Options
+		hasEmoji bool
+	}{
+		{"empty", "", defaultOptions, false},
+		{"ASCII", "hello world", defaultOptions, false},
+		{"CJK", "中文", defaultOptions, false},
+		{"ambiguous", "★→", eawOptions, false},
+		{"text presentation", "\u2764", defaultOptions, false},
+		{"digit", "1", defaultOptions, false},
+		{"escape sequences", "\x1b[31mred\x1b[0m", controlSequences, false},
+
+		{"emoji", "hi 😀", defaultOptions, true},
+		{"emoji presentation", "\u231B", defaultOptions, true},
+		{"VS16", "\u2764\uFE0F", defaultOptions, true},
+		{"keycap", "1\uFE0F\u20E3", defaultOptions, true},
+		{"flag", "🇺🇸", defaultOptions, true},
+		{"lone regional indicator", "\U0001F1FA", defaultOptions, true},
+		{"ZWJ sequence", "👩\u200D💻", defaultOptions, true},
+		{"modifier", "👍\U0001F3FD", defaultOptions, true},
+		{"mixed", "中文 and 😀 in a long line of text", defaultOptions, true},
+
+		// Classification does not depend on width options
+		{"EmojiSet", "😀", Options{EmojiSet: &unicode.RangeTable{}}, true},
+		{"TabWidth", "a\t😀", Options{TabWidth: 4}, true},
+		{"TabWidth no emoji", "a\tb", Options{TabWidth: 4}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, hasEmoji := tt.options.WidthAndEmoji(tt.input)
+			if want := tt.options.String(tt.input); width != want {
+				t.Errorf("WidthAndEmoji(%q) width = %d, want %d", tt.input, width, want)
+			}
+			if hasEmoji != tt.hasEmoji {
+				t.Errorf("WidthAndEmoji(%q) hasEmoji = %v, want %v", tt.input, hasEmoji, tt.hasEmoji)
+			}
+		})
+	}
+
+	if width, hasEmoji := WidthAndEmoji("a😀"); width != 3 || !hasEmoji {
+		t.Errorf("WidthAndEmoji() = (%d, %v), want (3, true)", width, hasEmoji)
+	}
+}
+
+func TestWidth(t *testing.T) {
+	type namedString string
+	type namedBytes []byte
+
+	inputs := []string{"", "hello", "中文", "😀👍🏽", "🇺🇸", "e\u0301", "\x1b[31mred\x1b[0m", "★"}
+	options := []Options{defaultOptions, eawOptions, controlSequences}
+
+	for _, opt := range options {
+		for _, input := range inputs {
+			want := opt.String(input)
+
+			if got := Width(opt, input); got != want {
+				t.Errorf("Width(%+v, string %q) = %d, want %d", opt, input, got, want)
+			}
+			if got := Width(opt, []byte(input)); got != want {
+				t.Errorf("Width(%+v, []byte %q) = %d, want %d", opt, input, got, want)
+			}
+			if got := Width(opt, namedString(input)); got != want {
+				t.Errorf("Width(%+v, namedString %q) = %d, want %d", opt, input, got, want)
+			}
+			if got := Width(opt, namedBytes(input)); got != want {
+				t.Errorf("Width(%+v, namedBytes %q) = %d, want %d", opt, input, got, want)
+			}
+		}
+	}
+}
+
 // TestPrintableASCIIOptimization verifies that the partial ASCII optimization
 // in String() and Bytes() works correctly for printable ASCII content.
 func TestPrintableASCIIOptimization(t *testing.T) {
@@ -1634,6 +2607,342 @@ func TestPrintableASCIIOptimization(t *testing.T) {
 	}
 }
 
+// TestCleanASCII verifies the early return for strings of only printable
+// ASCII, and the fallback when a control byte or non-ASCII byte appears.
+func TestCleanASCII(t *testing.T) {
+	clean := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 4)
+	options := []Options{
+		defaultOptions,
+		eawOptions,
+		controlSequencesBoth,
+		{TabWidth: 8},
+		{CaretNotation: true},
+		{StopAtNUL: true},
+		{DECGraphics: true},
+		{EmojiSet: &unicode.RangeTable{}, NeutralAsWide: true},
+	}
+
+	for _, o := range options {
+		if got := o.String(clean); got != len(clean) {
+			t.Errorf("%+v String(%q) = %d, want %d", o, clean, got, len(clean))
+		}
+		if got := o.Bytes([]byte(clean)); got != len(clean) {
+			t.Errorf("%+v Bytes(%q) = %d, want %d", o, clean, got, len(clean))
+		}
+	}
+
+	tests := []widthTest{
+		{"control at start", "\x01" + clean, defaultOptions, len(clean)},
+		{"control in middle", clean + "\x01" + clean, defaultOptions, 2 * len(clean)},
+		{"control at end", clean + "\x01", defaultOptions, len(clean)},
+		{"DEL", clean + "\x7f", defaultOptions, len(clean)},
+		{"newline", clean + "\n" + clean, defaultOptions, 2 * len(clean)},
+		{"tab", "ab\tcd", Options{TabWidth: 8}, 8 + 2},
+		{"caret notation", clean + "\x01", Options{CaretNotation: true}, len(clean) + 2},
+		{"NUL", clean + "\x00" + clean, Options{StopAtNUL: true}, len(clean)},
+		{"escape sequence", clean + "\x1b[31m" + clean, controlSequences, 2 * len(clean)},
+		{"combining after ASCII", clean + "\u0301", defaultOptions, len(clean)},
+		{"non-ASCII at end", clean + "中", defaultOptions, len(clean) + 2},
+		{"neutral after ASCII", clean + "ñ", Options{NeutralAsWide: true}, len(clean) + 2},
+	}
+
+	testWidths(t, tests)
+}
+
+// TestSoftHyphen pins U+00AD SOFT HYPHEN, a format character (Cf), as zero
+// width. It is only displayed, as a hyphen, where a line is broken, which is
+// up to the renderer; see [Options.WrapOffsets].
+func TestSoftHyphen(t *testing.T) {
+	tests := []widthTest{
+		{"alone", "\u00AD", defaultOptions, 0},
+		{"in a word", "hy\u00ADphen", defaultOptions, 6},
+		{"several", "a\u00ADb\u00ADc", defaultOptions, 3},
+		{"EAW", "hy\u00ADphen", eawOptions, 6},
+		{"after CJK", "中\u00AD文", defaultOptions, 4},
+	}
+
+	testWidths(t, tests)
+
+	if got := Rune(0x00AD); got != 0 {
+		t.Errorf("Rune(U+00AD) = %d, want 0", got)
+	}
+	if !LookupProperty(0x00AD).IsZeroWidth() {
+		t.Errorf("LookupProperty(U+00AD) is not zero width")
+	}
+	if e := ExplainString("\u00AD"); len(e) != 1 || e[0].Reason != ReasonZeroWidth {
+		t.Errorf("ExplainString(U+00AD) = %+v, want ReasonZeroWidth", e)
+	}
+
+	// A soft hyphen is not a break opportunity of its own; the wrapped
+	// lines are as without it, and it stays zero width at the end of a line
+	if got := WrapOffsets("ab\u00ADcd", 2); !reflect.DeepEqual(got, []int{4}) {
+		t.Errorf("WrapOffsets() = %v, want [4]", got)
+	}
+}
+
+// TestEmDashes pins the width of the multi-em dashes. Although they are
+// drawn two and three ems long, U+2E3A TWO-EM DASH and U+2E3B THREE-EM DASH
+// are East Asian Neutral, not Wide, in EastAsianWidth.txt, so they are width
+// 1, and terminals draw them in one cell.
+func TestEmDashes(t *testing.T) {
+	tests := []widthTest{
+		{"em dash", "\u2014", defaultOptions, 1},
+		{"two-em dash", "\u2E3A", defaultOptions, 1},
+		{"three-em dash", "\u2E3B", defaultOptions, 1},
+		{"two-em dash EAW", "\u2E3A", eawOptions, 1},
+		{"three-em dash EAW", "\u2E3B", eawOptions, 1},
+		{"em dash EAW", "\u2014", eawOptions, 2}, // ambiguous
+		{"in text", "a\u2E3Ab\u2E3Bc", defaultOptions, 5},
+	}
+
+	testWidths(t, tests)
+
+	for _, r := range []rune{0x2E3A, 0x2E3B} {
+		if p := LookupProperty(r); p.IsWide() || p.IsAmbiguous() {
+			t.Errorf("LookupProperty(%U) = %v, want neither wide nor ambiguous", r, p)
+		}
+	}
+}
+
+func TestBidiDirectionIndependent(t *testing.T) {
+	// Width is independent of direction: mirrored characters, such as
+	// brackets, keep their width in right-to-left context, and bidi
+	// formatting characters are zero width.
+	for _, r := range []rune{
+		0x200E, 0x200F, 0x061C, // LRM, RLM, ALM
+		0x202A, 0x202B, 0x202C, 0x202D, 0x202E, // LRE, RLE, PDF, LRO, RLO
+		0x2066, 0x2067, 0x2068, 0x2069, // LRI, RLI, FSI, PDI
+	} {
+		if got := Rune(r); got != 0 {
+			t.Errorf("Rune(%U) = %d, want 0", r, got)
+		}
+	}
+
+	mirrored := []string{
+		"(", ")", "[", "]", "{", "}", "<", ">", "«", "»",
+		"\u2039", "\u203A", // single angle quotation marks
+		"\u2208", "\u220B", // element of, contains as member
+		"\u2264", "\u2265", // less-than or equal, greater-than or equal
+		"\u3008", "\u3009", // CJK angle brackets
+		"\u300C", "\u300D", // CJK corner brackets
+		"\uFF08", "\uFF09", // fullwidth parentheses
+		"\uFE59", "\uFE5A", // small parentheses
+	}
+
+	wrappers := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{"RLM", "\u200F", "\u200F"},
+		{"LRM", "\u200E", "\u200E"},
+		{"RLE", "\u202B", "\u202C"},
+		{"RLO", "\u202E", "\u202C"},
+		{"RLI", "\u2067", "\u2069"},
+		{"Hebrew", "\u05E9\u05DC\u05D5\u05DD ", " \u05E2\u05D5\u05DC\u05DD"},
+		{"Arabic", "\u0645\u0631\u062D\u0628\u0627 ", " \u0639\u0627\u0644\u0645"},
+	}
+
+	for _, options := range []Options{defaultOptions, eawOptions} {
+		// Each mirrored pair has equal width
+		for i := 0; i+1 < len(mirrored); i += 2 {
+			if a, b := options.String(mirrored[i]), options.String(mirrored[i+1]); a != b {
+				t.Errorf("String(%q) = %d, but String(%q) = %d", mirrored[i], a, mirrored[i+1], b)
+			}
+		}
+
+		for _, m := range mirrored {
+			ltr := options.String(m)
+			for _, w := range wrappers {
+				s := w.before + m + w.after
+				want := options.String(w.before) + ltr + options.String(w.after)
+				if got := options.String(s); got != want {
+					t.Errorf("String(%q) with EastAsianWidth=%v = %d, want %d", s, options.EastAsianWidth, got, want)
+				}
+				if got := options.Bytes([]byte(s)); got != want {
+					t.Errorf("Bytes(%q) with EastAsianWidth=%v = %d, want %d", s, options.EastAsianWidth, got, want)
+				}
+			}
+		}
+	}
+
+	// A bracketed RTL phrase has the width of its LTR rendering
+	if got := String("\u200F(\u05E9\u05DC\u05D5\u05DD)\u200F"); got != 6 {
+		t.Errorf("String() = %d, want 6", got)
+	}
+}
+
+func TestFreeVariationSelectors(t *testing.T) {
+	// Mongolian free variation selectors and the variation selectors
+	// supplement (ideographic variation sequences) are zero width, and do
+	// not add columns to the preceding character.
+	for _, r := range []rune{0x180B, 0x180C, 0x180D, 0x180F, 0xE0100, 0xE0101, 0xE01EF} {
+		if got := Rune(r); got != 0 {
+			t.Errorf("Rune(%U) = %d, want 0", r, got)
+		}
+		if !LookupProperty(r).IsZeroWidth() {
+			t.Errorf("LookupProperty(%U).IsZeroWidth() = false, want true", r)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"Mongolian a + FVS1", "\u1820\u180B", 1},
+		{"Mongolian u + FVS2, i", "\u1824\u180C\u1822", 2},
+		{"Mongolian a + FVS4", "\u1820\u180F", 1},
+		{"Mongolian word with FVS", "\u182E\u1823\u1829\u182D\u1823\u182F\u180B", 6},
+		{"Mongolian word with FVS mid-word", "\u182E\u1823\u180C\u1829\u182D\u1823\u182F", 6},
+		{"ideographic variation sequence", "\u8FBA\U000E0100", 2},
+		{"ideographic variation sequences in text", "\u845B\U000E0101\u98FE\U000E0100\u308A", 6},
+		{"variation selector supplement on Latin", "a\U000E01EF", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, options := range []Options{defaultOptions, eawOptions} {
+				if got := options.String(tt.input); got != tt.expected {
+					t.Errorf("String(%q) with EastAsianWidth=%v = %d, want %d", tt.input, options.EastAsianWidth, got, tt.expected)
+				}
+				if got := options.Bytes([]byte(tt.input)); got != tt.expected {
+					t.Errorf("Bytes(%q) with EastAsianWidth=%v = %d, want %d", tt.input, options.EastAsianWidth, got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestCombiningMarksOnWideBase(t *testing.T) {
+	// A cluster takes the width of its base; combining marks add nothing,
+	// even on a wide base.
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"CJK + acute", "中\u0301", 2},
+		{"CJK + two marks", "中\u0301\u0308", 2},
+		{"CJK + enclosing circle", "中\u20DD", 2},
+		{"Hiragana + dakuten", "か\u3099", 2},
+		{"Hangul syllable + mark", "한\u0301", 2},
+		{"fullwidth + acute", "Ａ\u0301", 2},
+		{"emoji + acute", "😀\u0301", 2},
+		{"CJK Extension B + acute", "\U00020000\u0301", 2},
+		{"CJK + mark, then ASCII", "中\u0301a", 3},
+		{"narrow + acute", "e\u0301", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, options := range []Options{defaultOptions, eawOptions} {
+				if got := options.String(tt.input); got != tt.expected {
+					t.Errorf("String(%q) with EastAsianWidth=%v = %d, want %d", tt.input, options.EastAsianWidth, got, tt.expected)
+				}
+				if got := options.Bytes([]byte(tt.input)); got != tt.expected {
+					t.Errorf("Bytes(%q) with EastAsianWidth=%v = %d, want %d", tt.input, options.EastAsianWidth, got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+// TestSpacingMarks documents the width of spacing combining marks (general
+// category Mc). The generator excludes Mc from the zero-width combining marks,
+// so a lone spacing mark is width 1, like its fallback rendering on a dotted
+// circle. Within a grapheme cluster, the cluster takes the width of its base,
+// so a spacing mark does not add a column, even where a font would draw it
+// with its own advance.
+func TestSpacingMarks(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		// Devanagari
+		{"Devanagari sign visarga alone", "\u0903", 1},
+		{"Devanagari vowel sign i alone", "\u093F", 1},
+		{"Devanagari ka + visarga", "\u0915\u0903", 1},
+		{"Devanagari ki", "\u0915\u093F", 1},
+		{"Devanagari kii", "\u0915\u0940", 1},
+		{"Devanagari namaste", "\u0928\u092E\u0938\u094D\u0924\u0947", 3},
+
+		// Tamil
+		{"Tamil vowel sign aa alone", "\u0BBE", 1},
+		{"Tamil kaa", "\u0B95\u0BBE", 1},
+		{"Tamil ko (two-part vowel sign)", "\u0B95\u0BCA", 1},
+		{"Tamil tamil", "\u0BA4\u0BAE\u0BBF\u0BB4\u0BCD", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := String(tt.input); got != tt.expected {
+				t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := Bytes([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Bytes(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	// Lone spacing marks are width 1 as runes, and are not combining marks
+	for _, r := range []rune{0x0903, 0x093F, 0x0940, 0x0BBE, 0x0BCA} {
+		if got := Rune(r); got != 1 {
+			t.Errorf("Rune(%U) = %d, want 1", r, got)
+		}
+		if LookupProperty(r).IsCombining() {
+			t.Errorf("LookupProperty(%U).IsCombining() = true, want false", r)
+		}
+	}
+}
+
+// TestHangulJamo verifies that conjoining Hangul jamo (Leading, Vowel and
+// Trailing) form a single grapheme cluster, per UAX #29, and that the cluster
+// takes the width of its leading jamo, 2, as a single syllable block.
+func TestHangulJamo(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+		clusters int
+	}{
+		{"L+V+T 한", "\u1112\u1161\u11AB", 2, 1},
+		{"L+V 하", "\u1112\u1161", 2, 1},
+		{"L+L+V", "\u1100\u1100\u1161", 2, 1},
+		{"precomposed 한", "\uD55C", 2, 1},
+		{"LV syllable + T", "\uD558\u11AB", 2, 1},
+		{"L + LV syllable", "\u1100\uAC00", 2, 1},
+		{"two syllables 한글", "\u1112\u1161\u11AB\u1100\u1173\u11AF", 4, 2},
+		{"extended jamo", "\uA960\u1161\uD7CB", 2, 1},
+
+		// Lone jamo
+		{"lone L", "\u1112", 2, 1},
+		{"lone V", "\u1161", 1, 1},
+		{"lone T", "\u11AB", 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := String(tt.input); got != tt.expected {
+				t.Errorf("String(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+			if got := Bytes([]byte(tt.input)); got != tt.expected {
+				t.Errorf("Bytes(%q) = %d, want %d", tt.input, got, tt.expected)
+			}
+
+			clusters := 0
+			g := StringGraphemes(tt.input)
+			for g.Next() {
+				clusters++
+			}
+			if clusters != tt.clusters {
+				t.Errorf("StringGraphemes(%q) has %d clusters, want %d", tt.input, clusters, tt.clusters)
+			}
+		})
+	}
+}
+
 // TestUnicode16IndicConjunctBreak tests Unicode 16.0 Indic_Conjunct_Break property.
 // This property affects grapheme cluster breaking in Indic scripts, ensuring that
 // conjuncts (consonant clusters) are properly grouped into single grapheme clusters.
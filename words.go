@@ -0,0 +1,86 @@
+package displaywidth
+
+import (
+	"github.com/clipperhouse/uax29/v2/graphemes"
+)
+
+// TruncateWords truncates a string to the given maxWidth at a word boundary,
+// and appends the given tail if the string is truncated. It ensures the
+// visible width, including the width of the tail, is less than or equal to
+// maxWidth.
+//
+// The string is cut before the last ASCII space that keeps the width within
+// maxWidth, so that no word is cut; trailing spaces before the cut are
+// dropped. If the first word alone is too wide, it falls back to truncating
+// that word at a grapheme cluster boundary, as [Options.TruncateString].
+//
+// Escape sequences are handled as in [Options.TruncateString].
+func (options Options) TruncateWords(s string, maxWidth int, tail string) string {
+	// We deliberately ignore ControlSequences8Bit for truncation, see TruncateString.
+	options.ControlSequences8Bit = false
+
+	g := graphemes.FromString(s)
+	g.AnsiEscapeSequences = true // see truncatePosition
+
+	pos, truncated := truncatePosition(g, maxWidth, tailOf(tail, options), options)
+	if !truncated {
+		return s
+	}
+
+	g = graphemes.FromString(s)
+	g.AnsiEscapeSequences = true
+	return options.truncatedString(s, wordPosition(g, pos), tail)
+}
+
+// TruncateWords truncates a string to the given maxWidth at a word boundary,
+// and appends the given tail if the string is truncated. See
+// [Options.TruncateWords].
+func TruncateWords(s string, maxWidth int, tail string) string {
+	return DefaultOptionsValue().TruncateWords(s, maxWidth, tail)
+}
+
+// TruncateWordsBytes truncates a []byte to the given maxWidth at a word
+// boundary, and appends the given tail if the []byte is truncated. See
+// [Options.TruncateWords].
+func (options Options) TruncateWordsBytes(s []byte, maxWidth int, tail []byte) []byte {
+	// We deliberately ignore ControlSequences8Bit for truncation, see TruncateString.
+	options.ControlSequences8Bit = false
+
+	g := graphemes.FromBytes(s)
+	g.AnsiEscapeSequences = true // see truncatePosition
+
+	pos, truncated := truncatePosition(g, maxWidth, tailOf(tail, options), options)
+	if !truncated {
+		return s
+	}
+
+	g = graphemes.FromBytes(s)
+	g.AnsiEscapeSequences = true
+	return options.truncatedBytes(s, wordPosition(g, pos), tail)
+}
+
+// TruncateWordsBytes truncates a []byte to the given maxWidth at a word
+// boundary, and appends the given tail if the []byte is truncated. See
+// [Options.TruncateWords].
+func TruncateWordsBytes(s []byte, maxWidth int, tail []byte) []byte {
+	return DefaultOptionsValue().TruncateWordsBytes(s, maxWidth, tail)
+}
+
+// wordPosition moves the truncation position pos back to the end of the
+// last word before an ASCII space at or before pos. Spaces are found as
+// grapheme clusters of g, so a space within an escape sequence is not a
+// boundary. If there is no such word, pos is returned unchanged.
+func wordPosition[T ~string | ~[]byte](g *graphemes.Iterator[T], pos int) int {
+	cut, wordEnd := 0, 0
+	for g.Next() && g.Start() <= pos {
+		if v := g.Value(); len(v) == 1 && v[0] == ' ' {
+			cut = wordEnd
+		} else {
+			wordEnd = g.End()
+		}
+	}
+	if cut == 0 {
+		return pos
+	}
+	return cut
+}
@@ -0,0 +1,69 @@
+package displaywidth
+
+import "testing"
+
+func TestTruncateWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxWidth int
+		tail     string
+		options  Options
+		expected string
+	}{
+		{"empty", "", 5, "...", defaultOptions, ""},
+		{"fits", "hello world", 11, "...", defaultOptions, "hello world"},
+
+		{"sentence", "the quick brown fox", 15, "...", defaultOptions, "the quick..."},
+		{"cut at space", "the quick brown fox", 12, "...", defaultOptions, "the quick..."},
+		{"cut at word end", "the quick brown fox", 9, "", defaultOptions, "the quick"},
+		{"mid word", "the quick brown fox", 13, "", defaultOptions, "the quick"},
+		{"multiple spaces", "the   quick brown", 10, "…", defaultOptions, "the…"},
+		{"no tail", "hello world", 8, "", defaultOptions, "hello"},
+
+		// A single word too wide falls back to grapheme truncation
+		{"long word", "supercalifragilistic", 8, "...", defaultOptions, "super..."},
+		{"long first word", "supercalifragilistic word", 8, "...", defaultOptions, "super..."},
+		{"leading space", " supercalifragilistic", 8, "...", defaultOptions, " supe..."},
+		{"long CJK word", "中文字符测试", 7, "...", defaultOptions, "中文..."},
+		{"long word with ZWJ", "👩\u200D💻👩\u200D💻👩\u200D💻", 5, "…", defaultOptions, "👩\u200D💻👩\u200D💻…"},
+
+		// Wide characters
+		{"CJK words", "中文 字符 测试", 11, "…", defaultOptions, "中文 字符…"},
+		{"CJK straddling", "中文 字符测试", 8, "…", defaultOptions, "中文…"},
+
+		// Only ASCII spaces are word boundaries
+		{"ideographic space", "中文\u3000字符测试", 10, "…", defaultOptions, "中文\u3000字…"},
+
+		// Escape sequences
+		{"ControlSequences", "\x1b[31mthe quick brown\x1b[0m", 12, "...", controlSequences, "\x1b[31mthe quick...\x1b[0m"},
+		{"space in escape", "\x1b]0;a b\x07longword", 5, "", controlSequences, "\x1b]0;a b\x07longw"},
+
+		// Tabs are measured at their column
+		{"tab", "ab cd\tef", 9, "…", Options{TabWidth: 8}, "ab…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.TruncateWords(tt.input, tt.maxWidth, tt.tail)
+			if got != tt.expected {
+				t.Errorf("TruncateWords(%q, %d, %q) = %q, want %q", tt.input, tt.maxWidth, tt.tail, got, tt.expected)
+			}
+			if w := tt.options.String(got); w > tt.maxWidth {
+				t.Errorf("TruncateWords(%q, %d, %q) has width %d, want <= %d", tt.input, tt.maxWidth, tt.tail, w, tt.maxWidth)
+			}
+
+			gotBytes := tt.options.TruncateWordsBytes([]byte(tt.input), tt.maxWidth, []byte(tt.tail))
+			if string(gotBytes) != tt.expected {
+				t.Errorf("TruncateWordsBytes(%q, %d, %q) = %q, want %q", tt.input, tt.maxWidth, tt.tail, gotBytes, tt.expected)
+			}
+		})
+	}
+
+	if got := TruncateWords("hello world", 8, ""); got != "hello" {
+		t.Errorf("TruncateWords() = %q, want %q", got, "hello")
+	}
+	if got := TruncateWordsBytes([]byte("hello world"), 8, nil); string(got) != "hello" {
+		t.Errorf("TruncateWordsBytes() = %q, want %q", got, "hello")
+	}
+}
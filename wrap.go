@@ -0,0 +1,200 @@
+package displaywidth
+
+import (
+	"github.com/clipperhouse/uax29/v2/graphemes"
+)
+
+// FitsInBox reports whether s, hard-wrapped to cols columns, fits within
+// rows lines.
+//
+// Lines are broken at grapheme cluster boundaries, before any cluster that
+// would exceed cols, and not around no-break characters, as in
+// [Options.WrapOffsets]. Existing newlines ("\n" or "\r\n") are forced breaks. A
+// trailing newline does not begin a new line, and an empty string occupies
+// zero lines.
+//
+// It returns false if any single grapheme cluster is wider than cols, such as
+// a wide character when cols is 1, since it could never fit.
+func (options Options) FitsInBox(s string, cols, rows int) bool {
+	if len(s) == 0 {
+		return rows >= 0
+	}
+	if cols <= 0 || rows <= 0 {
+		return false
+	}
+
+	lines := 1
+	fits := options.hardWrap(s, cols, func(offset int, forced bool) bool {
+		lines++
+		return lines <= rows
+	})
+	return fits && lines <= rows
+}
+
+// FitsInBox reports whether s, hard-wrapped to cols columns, fits within
+// rows lines. See [Options.FitsInBox].
+func FitsInBox(s string, cols, rows int) bool {
+	return DefaultOptionsValue().FitsInBox(s, cols, rows)
+}
+
+// WrapOffsets returns the byte offsets at which line breaks should be
+// inserted to hard-wrap s to the given width. It does not allocate copies of
+// s; callers can slice s at the offsets, or render it with breaks inserted.
+//
+// Lines are broken at grapheme cluster boundaries, before any cluster that
+// would exceed width, accounting for wide characters. Existing newlines
+// ("\n" or "\r\n") reset the line, and are not included in the offsets. A
+// grapheme cluster wider than width is placed on a line of its own.
+//
+// Lines are not broken before or after a no-break character, such as U+00A0
+// NO-BREAK SPACE or U+2060 WORD JOINER; the line is instead broken at the
+// last earlier boundary, so that the text around it stays together. If there
+// is no such boundary on the line, it is broken before the cluster that
+// would exceed width, as usual.
+//
+// A soft hyphen (U+00AD) is zero width, and is not treated as a break
+// opportunity of its own; callers that show a hyphen at a break after one
+// must insert it, and allow a column for it.
+//
+// For options whose widths depend on context, such as [Options.TabWidth],
+// each line is measured as [Options.String] would measure it on its own, so a
+// tab is expanded from the start of its line.
+//
+// If width is not positive, or s fits within width, the result is nil.
+func (options Options) WrapOffsets(s string, width int) []int {
+	if width <= 0 {
+		return nil
+	}
+
+	var offsets []int
+	options.hardWrap(s, width, func(offset int, forced bool) bool {
+		if !forced {
+			offsets = append(offsets, offset)
+		}
+		return true
+	})
+	return offsets
+}
+
+// WrapOffsets returns the byte offsets at which line breaks should be
+// inserted to hard-wrap s to the given width. See [Options.WrapOffsets].
+func WrapOffsets(s string, width int) []int {
+	return DefaultOptionsValue().WrapOffsets(s, width)
+}
+
+// hardWrap iterates over the grapheme clusters of s, and calls fn with the
+// byte offset at which each line after the first begins. forced is true when
+// the line begins after a newline in s, and false when the line was broken
+// because the next grapheme cluster would exceed width. A trailing newline
+// does not begin a line.
+//
+// A grapheme cluster wider than width is placed on a line of its own. Breaks
+// before or after a no-break character are moved to the last earlier
+// boundary on the line, if the rest of the line then fits.
+//
+// For options whose widths depend on context, such as TabWidth, each line is
+// measured as String would measure it on its own.
+//
+// Iteration stops early if fn returns false. hardWrap returns false if it was
+// stopped early or if any grapheme cluster is wider than width.
+func (options Options) hardWrap(s string, width int, fn func(offset int, forced bool) bool) bool {
+	fits := true
+	col := 0
+
+	// state is the context of the current line, for options such as TabWidth
+	contextual := options.contextual()
+	var state contextState
+	measure := func(v string, state contextState) (int, contextState) {
+		if !contextual {
+			return graphemeWidth(v, options), state
+		}
+		return widthFrom(v, state, options)
+	}
+
+	// brk is the last boundary on the current line at which a break is
+	// allowed, and brkCol is the column at that boundary, or -1 if none.
+	brk, brkCol := -1, 0
+	prevGlue := false
+
+	g := graphemes.FromString(s)
+	g.AnsiEscapeSequences = options.ControlSequences || options.DECGraphics
+	g.AnsiEscapeSequences8Bit = options.ControlSequences8Bit
+
+	for g.Next() {
+		v := g.Value()
+		if isNewline(v) {
+			col, state = 0, contextState{}
+			brk, prevGlue = -1, false
+			if g.End() < len(s) && !fn(g.End(), true) {
+				return false
+			}
+			continue
+		}
+
+		w, next := measure(v, state)
+		glue := isNoBreak(v)
+		allowed := col > 0 && !prevGlue && !glue
+		if col > 0 && col+w > width {
+			offset := g.Start()
+			moved := false
+			if !allowed && brk >= 0 {
+				// The text after brk moves to the new line, where its
+				// context, such as the column of a tab, starts over
+				restCol, restState := col-brkCol, state
+				if contextual {
+					restCol, restState = widthFrom(s[brk:offset], contextState{}, options)
+				}
+				if rw, rnext := measure(v, restState); restCol+rw <= width {
+					offset, moved = brk, true
+					col, state = restCol, restState
+					w, next = rw, rnext
+				}
+			}
+			if !moved {
+				col, state = 0, contextState{}
+				w, next = measure(v, state)
+			}
+			brk = -1
+			if !fn(offset, false) {
+				return false
+			}
+		} else if allowed {
+			brk, brkCol = g.Start(), col
+		}
+		if w > width {
+			fits = false
+		}
+		col += w
+		state = next
+		prevGlue = glue
+	}
+	return fits
+}
+
+// isNewline reports whether the grapheme cluster is a line feed, or a
+// carriage return followed by a line feed.
+func isNewline[T ~string | ~[]byte](v T) bool {
+	switch len(v) {
+	case 1:
+		return v[0] == '\n'
+	case 2:
+		return v[0] == '\r' && v[1] == '\n'
+	}
+	return false
+}
+
+// isNoBreak reports whether the grapheme cluster begins with a character
+// that prohibits line breaks before and after it: one of the Glue (GL) or
+// Word Joiner (WJ) characters of UAX #14 that are commonly used in text,
+// U+00A0 NO-BREAK SPACE, U+2007 FIGURE SPACE, U+202F NARROW NO-BREAK SPACE,
+// U+2060 WORD JOINER and U+FEFF ZERO WIDTH NO-BREAK SPACE.
+func isNoBreak[T ~string | ~[]byte](v T) bool {
+	if len(v) < 2 || v[0] < 0xC2 {
+		return false
+	}
+	switch decodeRune(v) {
+	case 0x00A0, 0x2007, 0x202F, 0x2060, 0xFEFF:
+		return true
+	}
+	return false
+}
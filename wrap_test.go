@@ -0,0 +1,154 @@
+package displaywidth
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFitsInBox(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		cols     int
+		rows     int
+		options  Options
+		expected bool
+	}{
+		{"empty", "", 5, 0, defaultOptions, true},
+		{"empty one row", "", 5, 1, defaultOptions, true},
+		{"zero rows", "a", 5, 0, defaultOptions, false},
+		{"zero cols", "a", 0, 5, defaultOptions, false},
+
+		{"fits one line", "hello", 5, 1, defaultOptions, true},
+		{"wraps to two lines", "hello world", 5, 2, defaultOptions, false}, // "hello", " worl", "d"
+		{"wraps to three lines", "hello world", 5, 3, defaultOptions, true},
+
+		// Newlines are forced breaks
+		{"newline", "ab\ncd", 5, 1, defaultOptions, false},
+		{"newline fits", "ab\ncd", 5, 2, defaultOptions, true},
+		{"CRLF fits", "ab\r\ncd", 5, 2, defaultOptions, true},
+		{"trailing newline", "ab\n", 5, 1, defaultOptions, true},
+		{"blank lines", "a\n\nb", 5, 2, defaultOptions, false},
+		{"blank lines fit", "a\n\nb", 5, 3, defaultOptions, true},
+
+		// CJK wraps to more lines than its rune count suggests
+		{"CJK 4 runes, 3 cols", "中文字符", 3, 2, defaultOptions, false}, // one character per line
+		{"CJK 4 runes, 3 cols fits", "中文字符", 3, 4, defaultOptions, true},
+		{"CJK 4 runes, 4 cols", "中文字符", 4, 2, defaultOptions, true},
+		{"CJK odd cols", "中文字符测试", 5, 3, defaultOptions, true},         // two characters per line
+		{"CJK with ASCII", "a中文b", 3, 2, defaultOptions, true},         // "a中", "文b"
+		{"CJK with ASCII narrow", "a中文b", 2, 3, defaultOptions, false}, // "a", "中", "文", "b"
+		{"CJK too wide for cols", "中", 1, 5, defaultOptions, false},
+
+		// Ambiguous characters under EastAsianWidth
+		{"ambiguous narrow", "★★★★", 2, 2, defaultOptions, true},
+		{"ambiguous EAW", "★★★★", 2, 2, eawOptions, false},
+
+		// Zero-width content does not take space
+		{"combining marks", "éé", 2, 1, defaultOptions, true},
+		{"escape sequences", "\x1b[31mab\x1b[0m", 2, 1, controlSequences, true},
+
+		// No-break characters keep text together, which may take more lines
+		{"space", "ab cd", 3, 2, defaultOptions, true},                    // "ab ", "cd"
+		{"NBSP earlier break", "ab\u00A0cd", 3, 2, defaultOptions, false}, // "a", "b\u00A0c", "d"
+		{"NBSP three lines", "ab\u00A0cd", 3, 3, defaultOptions, true},
+
+		// Tabs are expanded from the start of their line
+		{"tab", "ab\tcd", 4, 1, Options{TabWidth: 4}, false}, // "ab\t", "cd"
+		{"tab two lines", "ab\tcd", 4, 2, Options{TabWidth: 4}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.FitsInBox(tt.input, tt.cols, tt.rows)
+			if got != tt.expected {
+				t.Errorf("FitsInBox(%q, %d, %d) = %v, want %v", tt.input, tt.cols, tt.rows, got, tt.expected)
+			}
+		})
+	}
+
+	if !FitsInBox("hello", 5, 1) {
+		t.Errorf("FitsInBox(%q, 5, 1) = false, want true", "hello")
+	}
+}
+
+func TestWrapOffsets(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		width    int
+		options  Options
+		expected []int
+	}{
+		{"empty", "", 5, defaultOptions, nil},
+		{"zero width", "hello", 0, defaultOptions, nil},
+		{"fits", "hello", 5, defaultOptions, nil},
+		{"wraps", "hello world", 5, defaultOptions, []int{5, 10}},
+		{"width 1", "abc", 1, defaultOptions, []int{1, 2}},
+
+		// Wide characters
+		{"CJK", "中文字符", 4, defaultOptions, []int{6}},
+		{"CJK odd width", "中文字符", 3, defaultOptions, []int{3, 6, 9}},
+		{"CJK with ASCII", "a中文b", 3, defaultOptions, []int{4}},
+		{"CJK too wide", "a中", 1, defaultOptions, []int{1}},
+		{"emoji", "😀😀😀", 4, defaultOptions, []int{8}},
+
+		// Grapheme clusters are not split
+		{"combining", "e\u0301e\u0301e\u0301", 2, defaultOptions, []int{6}},
+		{"ZWJ sequence", "ab👩\u200D💻", 3, defaultOptions, []int{2}},
+
+		// Existing newlines reset the line, and are not offsets
+		{"newline", "abc\ndefgh", 3, defaultOptions, []int{7}},
+		{"CRLF", "ab\r\ncdef", 3, defaultOptions, []int{7}},
+
+		// Escape sequences are zero width with ControlSequences
+		{"ControlSequences", "\x1b[31mabcdef\x1b[0m", 3, controlSequences, []int{8}},
+
+		// No-break characters move the break to an earlier boundary
+		{"NBSP fits", "abc 1\u00A0kg", 8, defaultOptions, nil},
+		{"NBSP", "abc 1\u00A0kg", 6, defaultOptions, []int{4}},
+		{"NBSP before break", "abc 1\u00A0kg", 5, defaultOptions, []int{4}},
+		{"narrow NBSP", "abc 1\u202Fkg", 6, defaultOptions, []int{4}},
+		{"word joiner", "abc 1\u2060kg", 5, defaultOptions, []int{4}},
+		{"word joiner fits", "ab\u2060cd", 4, defaultOptions, nil},
+		{"NBSP too long for line", "\u00A0\u00A0\u00A0", 2, defaultOptions, []int{4}},
+		{"NBSP rest too wide", "a\u00A0bcd", 3, defaultOptions, []int{4}},
+
+		// Tabs are expanded from the start of their line
+		{"tab", "ab\tcd\tef", 6, Options{TabWidth: 4}, []int{5}},
+		{"tab too wide", "ab\tc\td", 4, Options{TabWidth: 8}, []int{2, 3, 4, 5}},
+		{"tab moved with NBSP", "ab c\u00A0\tde", 5, Options{TabWidth: 4}, []int{3, 8}},
+		{"ContextualAmbiguous", "漢××", 4, Options{ContextualAmbiguous: true}, []int{5}},
+		{"AmbiguousInCJKContext", "漢××", 4, Options{AmbiguousInCJKContext: true}, []int{5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.options.WrapOffsets(tt.input, tt.width)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("WrapOffsets(%q, %d) = %v, want %v", tt.input, tt.width, got, tt.expected)
+			}
+
+			if tt.width <= 0 {
+				return
+			}
+
+			// Slicing at the offsets yields lines that fit, except for a
+			// single grapheme cluster that is too wide
+			prev := 0
+			for _, offset := range append(got, len(tt.input)) {
+				for _, line := range strings.Split(tt.input[prev:offset], "\n") {
+					if w := tt.options.String(line); w > tt.width && tt.options.GraphemeOffset(line, 1) < len(line) {
+						t.Errorf("line %q has width %d, want <= %d", line, w, tt.width)
+					}
+				}
+				prev = offset
+			}
+		})
+	}
+
+	if got := WrapOffsets("hello world", 5); !reflect.DeepEqual(got, []int{5, 10}) {
+		t.Errorf("WrapOffsets() = %v, want %v", got, []int{5, 10})
+	}
+}